@@ -0,0 +1,155 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	reqContext "context"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/admission"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+)
+
+// limitKey identifies the requests a limit applies to. The limit of the client has an empty key.
+type limitKey struct {
+	chaincodeID string
+	fcn         string
+}
+
+// WithClientLimit limits the rate and the number of in-flight requests of the client, across all chaincodes.
+// Requests which exceed the limit are queued, or rejected with status code AdmissionRejected.
+//  Parameters:
+//  opts holds the admission control parameters
+//
+//  Returns:
+//  a client option
+func WithClientLimit(opts admission.Opts) ClientOption {
+	return withLimit(limitKey{}, opts)
+}
+
+// WithChaincodeLimit limits the rate and the number of in-flight requests of the client to the given chaincode.
+// Requests which exceed the limit are queued, or rejected with status code AdmissionRejected.
+//  Parameters:
+//  chaincodeID is the ID of the chaincode
+//  opts holds the admission control parameters
+//
+//  Returns:
+//  a client option
+func WithChaincodeLimit(chaincodeID string, opts admission.Opts) ClientOption {
+	if chaincodeID == "" {
+		return func(*Client) error {
+			return errors.New("chaincode ID is required")
+		}
+	}
+	return withLimit(limitKey{chaincodeID: chaincodeID}, opts)
+}
+
+// WithFunctionLimit limits the rate and the number of in-flight requests of the client to the given function of
+// a chaincode. Requests which exceed the limit are queued, or rejected with status code AdmissionRejected.
+//  Parameters:
+//  chaincodeID is the ID of the chaincode
+//  fcn is the chaincode function
+//  opts holds the admission control parameters
+//
+//  Returns:
+//  a client option
+func WithFunctionLimit(chaincodeID, fcn string, opts admission.Opts) ClientOption {
+	if chaincodeID == "" || fcn == "" {
+		return func(*Client) error {
+			return errors.New("chaincode ID and function are required")
+		}
+	}
+	return withLimit(limitKey{chaincodeID: chaincodeID, fcn: fcn}, opts)
+}
+
+func withLimit(key limitKey, opts admission.Opts) ClientOption {
+	return func(cc *Client) error {
+		limiter, err := admission.New(opts)
+		if err != nil {
+			return errors.WithMessage(err, "invalid admission limit")
+		}
+		if cc.limiters == nil {
+			cc.limiters = make(map[limitKey]*admission.Limiter)
+		}
+		cc.limiters[key] = limiter
+		return nil
+	}
+}
+
+// admit waits for the request to be admitted by the limits of the function, of the chaincode and of the client,
+// in that order, so that requests waiting on a narrow limit don't hold the capacity of the wider ones.
+// The wait is bounded by the timeout of the request. If a limit rejects the request, the admissions of the
+// previous limits are cancelled, which returns their tokens.
+// The returned function releases the capacity held by the request and must be called when the request completes.
+func (cc *Client) admit(parent reqContext.Context, request Request, timeout time.Duration) (func(), error) {
+	// requests without a chaincode ID or function are rejected by the handler contexts
+	if len(cc.limiters) == 0 || request.ChaincodeID == "" || request.Fcn == "" {
+		return func() {}, nil
+	}
+	if parent == nil {
+		parent = reqContext.Background()
+	}
+	ctx, cancel := reqContext.WithTimeout(parent, timeout)
+	defer cancel()
+
+	var admissions []*admission.Admission
+	release := func() {
+		for i := len(admissions) - 1; i >= 0; i-- {
+			admissions[i].Release()
+		}
+	}
+
+	start := time.Now()
+	keys := []limitKey{{chaincodeID: request.ChaincodeID, fcn: request.Fcn}, {chaincodeID: request.ChaincodeID}, {}}
+	for _, key := range keys {
+		limiter, ok := cc.limiters[key]
+		if !ok {
+			continue
+		}
+
+		a, err := limiter.Acquire(ctx)
+		if err != nil {
+			for i := len(admissions) - 1; i >= 0; i-- {
+				admissions[i].Cancel()
+			}
+			if err == reqContext.DeadlineExceeded {
+				err = admission.ErrQueueTimeout
+			}
+			recordAdmissionRejection(cc, request, rejectionReason(err))
+			return nil, status.New(status.ClientStatus, status.AdmissionRejected.ToInt32(),
+				"request rejected by admission control: "+err.Error(), nil)
+		}
+		admissions = append(admissions, a)
+	}
+	recordAdmissionWait(cc, request, time.Since(start))
+
+	return release, nil
+}
+
+// admissionTimeout returns the timeout of the request, which bounds its wait for admission
+func (cc *Client) admissionTimeout(txnOpts *requestOptions) time.Duration {
+	if timeout := txnOpts.Timeouts[fab.Execute]; timeout > 0 {
+		return timeout
+	}
+	if timeout := txnOpts.Timeouts[fab.Query]; timeout > 0 {
+		return timeout
+	}
+	return cc.context.EndpointConfig().Timeout(fab.Execute)
+}
+
+func rejectionReason(err error) string {
+	switch err {
+	case admission.ErrQueueFull:
+		return "queue_full"
+	case admission.ErrQueueTimeout:
+		return "queue_timeout"
+	default:
+		return "cancelled"
+	}
+}
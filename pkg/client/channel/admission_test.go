@@ -0,0 +1,120 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/admission"
+	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+func TestLimitOptions(t *testing.T) {
+	fabCtx := setupCustomTestContext(t, txnmocks.NewMockSelectionService(nil), txnmocks.NewMockDiscoveryService(nil), nil)
+	ctx := createChannelContext(fabCtx, channelID)
+
+	_, err := New(ctx, WithClientLimit(admission.Opts{}))
+	assert.Error(t, err, "expected error for limit without rate or maximum in-flight requests")
+
+	_, err = New(ctx, WithChaincodeLimit("", admission.Opts{Rate: 1}))
+	assert.Error(t, err, "expected error for limit without chaincode ID")
+
+	_, err = New(ctx, WithFunctionLimit("testCC", "", admission.Opts{Rate: 1}))
+	assert.Error(t, err, "expected error for limit without function")
+
+	chClient, err := New(ctx, WithClientLimit(admission.Opts{Rate: 100}), WithChaincodeLimit("testCC", admission.Opts{MaxInFlight: 2}),
+		WithFunctionLimit("testCC", "move", admission.Opts{MaxInFlight: 1}))
+	require.NoError(t, err)
+	assert.Len(t, chClient.limiters, 3)
+}
+
+func TestAdmission(t *testing.T) {
+	fabCtx := setupCustomTestContext(t, txnmocks.NewMockSelectionService(nil), txnmocks.NewMockDiscoveryService(nil), nil)
+	chClient, err := New(createChannelContext(fabCtx, channelID),
+		WithChaincodeLimit("testCC", admission.Opts{MaxInFlight: 2}),
+		WithFunctionLimit("testCC", "move", admission.Opts{MaxInFlight: 1, QueueSize: 1, QueueTimeout: 10 * time.Millisecond}))
+	require.NoError(t, err)
+
+	handler := &blockingHandler{started: make(chan struct{}), unblock: make(chan struct{})}
+	done := make(chan error)
+	go func() {
+		_, err := chClient.InvokeHandler(handler, Request{ChaincodeID: "testCC", Fcn: "move"})
+		done <- err
+	}()
+	<-handler.started
+
+	_, err = chClient.InvokeHandler(&blockingHandler{}, Request{ChaincodeID: "testCC", Fcn: "move"})
+	s, ok := status.FromError(err)
+	require.True(t, ok, "expected status error")
+	assert.Equal(t, status.ClientStatus, s.Group)
+	assert.EqualValues(t, status.AdmissionRejected, s.Code, "expected request to be rejected once the queue timed out")
+
+	// the request doesn't exceed the limit of the chaincode
+	_, err = chClient.InvokeHandler(&blockingHandler{}, Request{ChaincodeID: "testCC", Fcn: "query"})
+	assert.NoError(t, err)
+
+	// requests to other chaincodes are not limited
+	_, err = chClient.InvokeHandler(&blockingHandler{}, Request{ChaincodeID: "otherCC", Fcn: "move"})
+	assert.NoError(t, err)
+
+	close(handler.unblock)
+	require.NoError(t, <-done)
+
+	_, err = chClient.InvokeHandler(&blockingHandler{}, Request{ChaincodeID: "testCC", Fcn: "move"})
+	assert.NoError(t, err, "expected request to be admitted once the in-flight request completed")
+}
+
+func TestAdmissionRejection(t *testing.T) {
+	fabCtx := setupCustomTestContext(t, txnmocks.NewMockSelectionService(nil), txnmocks.NewMockDiscoveryService(nil), nil)
+	chClient, err := New(createChannelContext(fabCtx, channelID),
+		WithClientLimit(admission.Opts{MaxInFlight: 1, QueueSize: 1}),
+		WithFunctionLimit("testCC", "move", admission.Opts{Rate: 0.001, Burst: 2}))
+	require.NoError(t, err)
+
+	handler := &blockingHandler{started: make(chan struct{}), unblock: make(chan struct{})}
+	done := make(chan error)
+	go func() {
+		_, err := chClient.InvokeHandler(handler, Request{ChaincodeID: "testCC", Fcn: "move"})
+		done <- err
+	}()
+	<-handler.started
+
+	// the client limit has no queue timeout, so the wait is bounded by the timeout of the request
+	_, err = chClient.InvokeHandler(&blockingHandler{}, Request{ChaincodeID: "testCC", Fcn: "move"},
+		WithTimeout(fab.Execute, 20*time.Millisecond))
+	s, ok := status.FromError(err)
+	require.True(t, ok, "expected status error")
+	assert.EqualValues(t, status.AdmissionRejected, s.Code, "expected request to be rejected once its timeout expired")
+
+	close(handler.unblock)
+	require.NoError(t, <-done)
+
+	_, err = chClient.InvokeHandler(&blockingHandler{}, Request{ChaincodeID: "testCC", Fcn: "move"})
+	assert.NoError(t, err, "expected the token of the rejected request to be refunded")
+}
+
+// blockingHandler blocks until unblock is closed, if set
+type blockingHandler struct {
+	started chan struct{}
+	unblock chan struct{}
+}
+
+func (h *blockingHandler) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	if h.started != nil {
+		close(h.started)
+	}
+	if h.unblock != nil {
+		<-h.unblock
+	}
+}
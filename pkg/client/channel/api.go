@@ -15,6 +15,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
 	"github.com/pkg/errors"
 )
@@ -29,6 +30,10 @@ type requestOptions struct {
 	Timeouts      map[fab.TimeoutType]time.Duration //timeout options for channel client operations
 	ParentContext reqContext.Context                //parent grpc context for channel client operations (query, execute, invokehandler)
 	CCFilter      invoke.CCFilter
+	Identity      msp.SigningIdentity //signing identity for the request, overrides the identity of the channel context
+	ConflictRetry retry.Opts          //retries of transactions invalidated by read conflicts
+	Nonce         []byte              //nonce pinning the transaction ID across retries
+	Quorum        invoke.QuorumOpts   //number of peers whose query results must match
 }
 
 // RequestOption func for each Opts argument
@@ -61,6 +66,8 @@ type Response struct {
 	TxValidationCode pb.TxValidationCode
 	ChaincodeStatus  int32
 	Payload          []byte
+	// Attempts contains the outcome of each attempt to commit the transaction when WithConflictRetry is used
+	Attempts []*invoke.TransactionAttempt
 }
 
 //WithTargets allows overriding of the target peers for the request
@@ -157,6 +164,70 @@ func WithParentContext(parentContext reqContext.Context) RequestOption {
 	}
 }
 
+// WithSigningIdentity signs the proposal and transaction of the request as the given identity instead of the
+// identity of the channel context. The channel context, discovery, selection and event services of the client
+// are reused, so a single client can serve requests on behalf of many users.
+func WithSigningIdentity(identity msp.SigningIdentity) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if identity == nil {
+			return errors.New("signing identity is nil")
+		}
+		o.Identity = identity
+		return nil
+	}
+}
+
+// WithConflictRetry re-runs the whole select, endorse and commit chain of the request, with a new transaction ID,
+// when the transaction is invalidated with MVCC_READ_CONFLICT or PHANTOM_READ_CONFLICT. The attempt limit and
+// backoff are taken from the given options, while RetryableCodes is ignored. Other errors continue to be
+// retried according to WithRetry. The outcome of every attempt is reported in Response.Attempts.
+// Note that the execute timeout of the request applies to all attempts.
+func WithConflictRetry(retryOpt retry.Opts) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if retryOpt.Attempts <= 0 {
+			return errors.New("conflict retry attempts must be greater than zero")
+		}
+		o.ConflictRetry = retryOpt
+		return nil
+	}
+}
+
+// WithNonce pins the nonce, and therefore the transaction ID, of the request across retries and across calls,
+// e.g. when the caller resubmits a request that timed out. Before the transaction is submitted, including the
+// first attempt, the ledger is queried for the transaction ID, and if the transaction has already been committed
+// its outcome is returned instead, so that the transaction is never submitted twice. The transaction is only
+// submitted if the peers report that it is not in the ledger; if the ledger cannot be queried, the error is
+// returned along with the pinned transaction ID. A transaction invalidated as a duplicate is reported with the
+// outcome of the committed transaction.
+// If the request times out, the pinned transaction ID is returned with the response so that the outcome of
+// the transaction can be looked up, e.g. using ledger.Client.QueryTransaction. A nonce may be created using
+// NewNonce. WithNonce cannot be combined with WithConflictRetry, which needs a new transaction ID for every attempt.
+func WithNonce(nonce []byte) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if len(nonce) == 0 {
+			return errors.New("nonce must not be empty")
+		}
+		o.Nonce = nonce
+		return nil
+	}
+}
+
+// WithQuorum makes a query consistent across peers: the proposal is sent to the given number of target peers,
+// spread across as many organizations as possible, and the query succeeds only if at least the given number of
+// peers return matching results, so that a single lagging or untrusted peer cannot return a stale or forged result.
+// If the results do not match, an EndorsementMismatch error is returned along with all the responses, whose
+// differences are available using txn.EndorsementMismatchFromError. Peers are taken from WithTargets if given,
+// otherwise from the discovery service, filtered by the target filter. WithQuorum only applies to queries.
+func WithQuorum(targets, matches int) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if matches <= 0 || targets < matches {
+			return errors.New("quorum matches must be greater than zero and must not exceed the number of targets")
+		}
+		o.Quorum = invoke.QuorumOpts{Targets: targets, Matches: matches}
+		return nil
+	}
+}
+
 //WithChaincodeFilter adds a chaincode filter for figuring out additional endorsers
 func WithChaincodeFilter(ccFilter invoke.CCFilter) RequestOption {
 	return func(ctx context.Client, o *requestOptions) error {
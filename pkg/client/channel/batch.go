@@ -0,0 +1,282 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	reqContext "context"
+	"sync"
+	"time"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/filter"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+)
+
+const defaultBatchConcurrency = 10
+
+// BatchResponse contains the outcome of one of the requests executed by ExecuteBatch
+type BatchResponse struct {
+	Response
+	// Error is set if the request was not endorsed, ordered or committed successfully
+	Error error
+}
+
+// BatchOption describes a functional parameter for ExecuteBatch
+type BatchOption func(opts *batchOptions) error
+
+type batchOptions struct {
+	concurrency    int
+	requestOptions []RequestOption
+}
+
+// WithConcurrency sets the maximum number of requests of a batch that are endorsed and sent to the
+// orderer concurrently. The default is 10.
+func WithConcurrency(concurrency int) BatchOption {
+	return func(opts *batchOptions) error {
+		if concurrency < 1 {
+			return errors.Errorf("invalid concurrency: %d", concurrency)
+		}
+		opts.concurrency = concurrency
+		return nil
+	}
+}
+
+// WithRequestOptions sets the request options that apply to every request of a batch
+func WithRequestOptions(options ...RequestOption) BatchOption {
+	return func(opts *batchOptions) error {
+		opts.requestOptions = append(opts.requestOptions, options...)
+		return nil
+	}
+}
+
+// ExecuteBatch prepares and executes many independent transactions. Requests are endorsed concurrently,
+// up to the configured concurrency, and each transaction is sent to the orderer as soon as it has been
+// endorsed. The commits of all the transactions are tracked by a single filtered block listener rather
+// than by a registration per transaction.
+//  Parameters:
+//  requests holds the requests to be executed
+//  options holds optional batch options, e.g. WithConcurrency and WithRequestOptions
+//
+//  Returns:
+//  a response for each request, in the order of the requests, or an error if the batch could not be started
+func (cc *Client) ExecuteBatch(requests []Request, options ...BatchOption) ([]BatchResponse, error) {
+	opts := batchOptions{concurrency: defaultBatchConcurrency}
+	for _, option := range options {
+		if err := option(&opts); err != nil {
+			return nil, errors.WithMessage(err, "option failed")
+		}
+	}
+
+	requestOptions := make([]RequestOption, 0, len(opts.requestOptions)+2)
+	requestOptions = append(requestOptions, opts.requestOptions...)
+	requestOptions = append(requestOptions, addDefaultTimeout(fab.Execute))
+	requestOptions = append(requestOptions, addDefaultTargetFilter(cc.context, filter.EndorsingPeer))
+
+	txnOpts, err := cc.prepareOptsFromOptions(cc.context, requestOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]BatchResponse, len(requests))
+	if len(requests) == 0 {
+		return responses, nil
+	}
+
+	tracker, err := newCommitTracker(cc.eventService)
+	if err != nil {
+		return nil, err
+	}
+	defer tracker.close()
+
+	parentContext := txnOpts.ParentContext
+	if parentContext == nil {
+		parentContext = reqContext.Background()
+	}
+
+	var commits sync.WaitGroup
+	var workers sync.WaitGroup
+	indexes := make(chan int)
+
+	for i := 0; i < opts.concurrency && i < len(requests); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for index := range indexes {
+				handler := &broadcastTxHandler{tracker: tracker}
+				response, err := cc.InvokeHandler(newBatchHandler(handler), requests[index], requestOptions...)
+				if err != nil {
+					responses[index] = BatchResponse{Response: response, Error: err}
+					continue
+				}
+
+				commits.Add(1)
+				go func(index int, response Response) {
+					defer commits.Done()
+					responses[index] = tracker.wait(parentContext, txnOpts.Timeouts[fab.Execute], handler.notifier, response)
+				}(index, response)
+			}
+		}()
+	}
+
+	for index := range requests {
+		indexes <- index
+	}
+	close(indexes)
+
+	workers.Wait()
+	commits.Wait()
+
+	return responses, nil
+}
+
+func newBatchHandler(broadcastHandler invoke.Handler) invoke.Handler {
+	return invoke.NewSelectAndEndorseHandler(
+		invoke.NewEndorsementValidationHandler(
+			invoke.NewSignatureValidationHandler(broadcastHandler),
+		),
+	)
+}
+
+// broadcastTxHandler sends the endorsed transaction to the orderer without waiting for it to be committed.
+// The commit of the transaction is tracked by the shared commit tracker of the batch.
+type broadcastTxHandler struct {
+	tracker  *commitTracker
+	notifier <-chan *fab.TxStatusEvent
+}
+
+//Handle handles broadcast of the transaction
+func (h *broadcastTxHandler) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	txnID := string(requestContext.Response.TransactionID)
+
+	// track the transaction before it is sent so that its commit cannot be missed
+	notifier := h.tracker.add(txnID)
+
+	tx, err := clientContext.Transactor.CreateTransaction(fab.TransactionRequest{
+		Proposal:          requestContext.Response.Proposal,
+		ProposalResponses: requestContext.Response.Responses,
+	})
+	if err != nil {
+		h.tracker.remove(txnID)
+		requestContext.Error = errors.WithMessage(err, "CreateTransaction failed")
+		return
+	}
+
+	if _, err := clientContext.Transactor.SendTransaction(tx); err != nil {
+		h.tracker.remove(txnID)
+		requestContext.Error = errors.WithMessage(err, "SendTransaction failed")
+		return
+	}
+
+	h.notifier = notifier
+}
+
+// commitTracker receives the filtered blocks of the channel and notifies the transactions of a batch
+// when they are committed
+type commitTracker struct {
+	eventService fab.EventService
+	registration fab.Registration
+	mutex        sync.Mutex
+	pending      map[string]chan *fab.TxStatusEvent
+	done         chan struct{}
+}
+
+func newCommitTracker(eventService fab.EventService) (*commitTracker, error) {
+	reg, eventch, err := eventService.RegisterFilteredBlockEvent()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error registering for filtered block events")
+	}
+
+	t := &commitTracker{
+		eventService: eventService,
+		registration: reg,
+		pending:      make(map[string]chan *fab.TxStatusEvent),
+		done:         make(chan struct{}),
+	}
+	go t.listen(eventch)
+
+	return t, nil
+}
+
+func (t *commitTracker) add(txnID string) <-chan *fab.TxStatusEvent {
+	notifier := make(chan *fab.TxStatusEvent, 1)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.pending[txnID] = notifier
+
+	return notifier
+}
+
+func (t *commitTracker) remove(txnID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.pending, txnID)
+}
+
+func (t *commitTracker) listen(eventch <-chan *fab.FilteredBlockEvent) {
+	for {
+		select {
+		case event, ok := <-eventch:
+			if !ok {
+				return
+			}
+			t.notify(event)
+		case <-t.done:
+			return
+		}
+	}
+}
+
+func (t *commitTracker) notify(event *fab.FilteredBlockEvent) {
+	if event.FilteredBlock == nil {
+		return
+	}
+
+	for _, tx := range event.FilteredBlock.FilteredTransactions {
+		t.mutex.Lock()
+		notifier, ok := t.pending[tx.Txid]
+		delete(t.pending, tx.Txid)
+		t.mutex.Unlock()
+
+		if ok {
+			notifier <- &fab.TxStatusEvent{
+				TxID:             tx.Txid,
+				TxValidationCode: tx.TxValidationCode,
+				BlockNumber:      event.FilteredBlock.Number,
+				SourceURL:        event.SourceURL,
+			}
+		}
+	}
+}
+
+// wait waits for the commit of a transaction that has been sent to the orderer
+func (t *commitTracker) wait(parentContext reqContext.Context, timeout time.Duration, notifier <-chan *fab.TxStatusEvent, response Response) BatchResponse {
+	ctx, cancel := reqContext.WithTimeout(parentContext, timeout)
+	defer cancel()
+
+	select {
+	case txStatus := <-notifier:
+		response.TxValidationCode = txStatus.TxValidationCode
+		if txStatus.TxValidationCode != pb.TxValidationCode_VALID {
+			return BatchResponse{Response: response, Error: status.New(status.EventServerStatus, int32(txStatus.TxValidationCode),
+				"received invalid transaction", nil)}
+		}
+		return BatchResponse{Response: response}
+	case <-ctx.Done():
+		t.remove(string(response.TransactionID))
+		return BatchResponse{Response: response, Error: status.New(status.ClientStatus, status.Timeout.ToInt32(),
+			"Execute didn't receive block event", nil)}
+	}
+}
+
+func (t *commitTracker) close() {
+	close(t.done)
+	t.eventService.Unregister(t.registration)
+}
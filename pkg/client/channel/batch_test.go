@@ -0,0 +1,194 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestExecuteBatch(t *testing.T) {
+	chClient, events := setupBatchChannelClient(t)
+
+	var requests []Request
+	for i := 0; i < 5; i++ {
+		requests = append(requests, Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}})
+	}
+	requests = append(requests, Request{Fcn: "invoke"})
+
+	responses, err := chClient.ExecuteBatch(requests, WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("Failed to execute batch: %s", err)
+	}
+
+	if len(responses) != len(requests) {
+		t.Fatalf("Expecting %d responses but got %d", len(requests), len(responses))
+	}
+
+	txnIDs := make(map[fab.TransactionID]bool)
+	for i, response := range responses[:5] {
+		if response.Error != nil {
+			t.Fatalf("Request %d failed: %s", i, response.Error)
+		}
+		if string(response.Payload) != "value" || response.TxValidationCode != pb.TxValidationCode_VALID {
+			t.Fatalf("Unexpected response for request %d: %#v", i, response)
+		}
+		txnIDs[response.TransactionID] = true
+	}
+
+	if len(txnIDs) != 5 {
+		t.Fatalf("Expecting 5 distinct transactions but got %d", len(txnIDs))
+	}
+
+	if responses[5].Error == nil {
+		t.Fatal("Should have failed for request without chaincode ID")
+	}
+
+	if events.registrations() != 1 {
+		t.Fatalf("Expecting a single filtered block registration but got %d", events.registrations())
+	}
+}
+
+func TestExecuteBatchInvalidTransaction(t *testing.T) {
+	chClient, events := setupBatchChannelClient(t)
+	events.validationCode = pb.TxValidationCode_MVCC_READ_CONFLICT
+
+	responses, err := chClient.ExecuteBatch([]Request{{ChaincodeID: "testCC", Fcn: "invoke"}})
+	if err != nil {
+		t.Fatalf("Failed to execute batch: %s", err)
+	}
+
+	if responses[0].Error == nil || !strings.Contains(responses[0].Error.Error(), "MVCC_READ_CONFLICT") {
+		t.Fatalf("Expecting MVCC_READ_CONFLICT error but got %v", responses[0].Error)
+	}
+
+	if responses[0].TxValidationCode != pb.TxValidationCode_MVCC_READ_CONFLICT {
+		t.Fatalf("Unexpected validation code: %s", responses[0].TxValidationCode)
+	}
+}
+
+func TestExecuteBatchTimeout(t *testing.T) {
+	chClient, events := setupBatchChannelClient(t)
+	events.silent = true
+
+	responses, err := chClient.ExecuteBatch([]Request{{ChaincodeID: "testCC", Fcn: "invoke"}},
+		WithRequestOptions(WithTimeout(fab.Execute, 100*time.Millisecond)))
+	if err != nil {
+		t.Fatalf("Failed to execute batch: %s", err)
+	}
+
+	s, ok := status.FromError(responses[0].Error)
+	if !ok || s.Code != status.Timeout.ToInt32() {
+		t.Fatalf("Expecting timeout error but got %v", responses[0].Error)
+	}
+}
+
+func TestExecuteBatchOptions(t *testing.T) {
+	chClient, _ := setupBatchChannelClient(t)
+
+	if _, err := chClient.ExecuteBatch(nil, WithConcurrency(0)); err == nil {
+		t.Fatal("Should have failed for invalid concurrency")
+	}
+
+	responses, err := chClient.ExecuteBatch(nil)
+	if err != nil || len(responses) != 0 {
+		t.Fatalf("Unexpected result for empty batch: %v, %v", responses, err)
+	}
+}
+
+func setupBatchChannelClient(t *testing.T) (*Client, *batchEventService) {
+	testPeer := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer.Payload = []byte("value")
+	chClient := setupChannelClient([]fab.Peer{testPeer}, t)
+
+	events := &batchEventService{MockEventService: fcmocks.NewMockEventService()}
+	chClient.eventService = events
+
+	chService := chClient.context.ChannelService().(*fcmocks.MockChannelService)
+	transactor, err := chService.Transactor(nil)
+	if err != nil {
+		t.Fatalf("Failed to get transactor: %s", err)
+	}
+	chService.SetTransactor(&batchTransactor{Transactor: transactor, events: events})
+
+	return chClient, events
+}
+
+// batchTransactor publishes a filtered block for each transaction sent to the orderer
+type batchTransactor struct {
+	fab.Transactor
+	events *batchEventService
+}
+
+func (t *batchTransactor) SendTransaction(tx *fab.Transaction) (*fab.TransactionResponse, error) {
+	response, err := t.Transactor.SendTransaction(tx)
+	if err == nil {
+		t.events.publish(string(tx.Proposal.TxnID))
+	}
+	return response, err
+}
+
+// batchEventService delivers filtered blocks to the filtered block registrations
+type batchEventService struct {
+	*fcmocks.MockEventService
+	mutex          sync.Mutex
+	eventchs       []chan *fab.FilteredBlockEvent
+	blockNumber    uint64
+	validationCode pb.TxValidationCode
+	silent         bool
+}
+
+func (s *batchEventService) RegisterFilteredBlockEvent() (fab.Registration, <-chan *fab.FilteredBlockEvent, error) {
+	reg, _, err := s.MockEventService.RegisterFilteredBlockEvent()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	eventch := make(chan *fab.FilteredBlockEvent, 100)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.eventchs = append(s.eventchs, eventch)
+
+	return reg, eventch, nil
+}
+
+func (s *batchEventService) registrations() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.eventchs)
+}
+
+func (s *batchEventService) publish(txID string) {
+	if s.silent {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.blockNumber++
+	event := &fab.FilteredBlockEvent{
+		FilteredBlock: &pb.FilteredBlock{
+			Number: s.blockNumber,
+			FilteredTransactions: []*pb.FilteredTransaction{
+				{Txid: "other"},
+				{Txid: txID, TxValidationCode: s.validationCode},
+			},
+		},
+	}
+	for _, eventch := range s.eventchs {
+		eventch <- event
+	}
+}
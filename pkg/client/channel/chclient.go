@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/admission"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery/greylist"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/filter"
 	selectopts "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
@@ -27,6 +28,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/metrics"
 	"github.com/pkg/errors"
@@ -43,6 +45,7 @@ type Client struct {
 	eventService fab.EventService
 	greylist     *greylist.Filter
 	metrics      *metrics.ClientMetrics
+	limiters     map[limitKey]*admission.Limiter
 }
 
 // ClientOption describes a functional parameter for the New constructor
@@ -141,22 +144,54 @@ func addDefaultTimeout(tt fab.TimeoutType) RequestOption {
 //
 //  Returns:
 //  the proposal responses from peer(s)
-func (cc *Client) InvokeHandler(handler invoke.Handler, request Request, options ...RequestOption) (Response, error) {
+func (cc *Client) InvokeHandler(handler invoke.Handler, request Request, options ...RequestOption) (resp Response, err error) {
 	//Read execute tx options
 	txnOpts, err := cc.prepareOptsFromOptions(cc.context, options...)
 	if err != nil {
 		return Response{}, err
 	}
 
+	// requests wait for admission before their timeout starts
+	release, err := cc.admit(txnOpts.ParentContext, request, cc.admissionTimeout(&txnOpts))
+	if err != nil {
+		return Response{}, err
+	}
+	defer release()
+
 	reqCtx, cancel := cc.createReqContext(&txnOpts)
 	defer cancel()
 
+	reqCtx, span := tracing.StartSpan(reqCtx, "channel.Invoke",
+		tracing.String(tracing.ChannelKey, cc.context.ChannelID()),
+		tracing.String(tracing.ChaincodeKey, request.ChaincodeID),
+		tracing.String(tracing.FunctionKey, request.Fcn))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	//Prepare context objects for handler
 	requestContext, clientContext, err := cc.prepareHandlerContexts(reqCtx, request, txnOpts)
 	if err != nil {
 		return Response{}, err
 	}
 
+	// attempts are only recorded if transactions are re-run on read conflicts
+	conflictRetry := txnOpts.ConflictRetry.Attempts > 0
+	if conflictRetry {
+		requestContext.RetryHandler = newConflictRetryHandler(txnOpts.ConflictRetry, requestContext.RetryHandler)
+	}
+	var attempts []*invoke.TransactionAttempt
+
+	var pinned *pinnedTransaction
+	if txnOpts.Nonce != nil {
+		if conflictRetry {
+			return Response{}, errors.New("nonce cannot be pinned when transactions are re-run on read conflicts")
+		}
+		pinned, err = cc.newPinnedTransaction(&txnOpts, requestContext.RetryHandler)
+		if err != nil {
+			return Response{}, err
+		}
+		requestContext.RetryHandler = pinned
+	}
+
 	invoker := retry.NewInvoker(
 		requestContext.RetryHandler,
 		retry.WithBeforeRetry(
@@ -167,6 +202,14 @@ func (cc *Client) InvokeHandler(handler invoke.Handler, request Request, options
 
 				cc.greylist.Greylist(err)
 
+				if conflictRetry {
+					attempts = append(attempts, newTransactionAttempt(requestContext))
+				}
+
+				if pinned != nil {
+					pinned.beforeRetry(requestContext.Response)
+				}
+
 				// Reset context parameters
 				requestContext.Opts.Targets = txnOpts.Targets
 				requestContext.Error = nil
@@ -179,16 +222,30 @@ func (cc *Client) InvokeHandler(handler invoke.Handler, request Request, options
 	go func() {
 		_, _ = invoker.Invoke( // nolint: gas
 			func() (interface{}, error) {
+				if pinned != nil && pinned.resolveCommitted(requestContext, clientContext) {
+					return nil, requestContext.Error
+				}
 				handler.Handle(requestContext, clientContext)
+				if pinned != nil {
+					pinned.resolveDuplicate(requestContext, clientContext)
+				}
 				return nil, requestContext.Error
 			})
+		if conflictRetry {
+			requestContext.Response.Attempts = append(attempts, newTransactionAttempt(requestContext))
+		}
 		complete <- true
 	}()
 	select {
 	case <-complete:
+		span.SetAttributes(tracing.String(tracing.TxIDKey, string(requestContext.Response.TransactionID)))
 		return Response(requestContext.Response), requestContext.Error
 	case <-reqCtx.Done():
-		return Response{}, status.New(status.ClientStatus, status.Timeout.ToInt32(),
+		if pinned != nil {
+			// the transaction ID allows the caller to look up the outcome of the transaction
+			resp.TransactionID = pinned.txID
+		}
+		return resp, status.New(status.ClientStatus, status.Timeout.ToInt32(),
 			"request timed out or been cancelled", nil)
 	}
 }
@@ -205,7 +262,7 @@ func (cc *Client) createReqContext(txnOpts *requestOptions) (reqContext.Context,
 		txnOpts.Timeouts[fab.Execute] = cc.context.EndpointConfig().Timeout(fab.Execute)
 	}
 
-	reqCtx, cancel := contextImpl.NewRequest(cc.context, contextImpl.WithTimeout(txnOpts.Timeouts[fab.Execute]),
+	reqCtx, cancel := contextImpl.NewRequest(cc.requestClient(txnOpts), contextImpl.WithTimeout(txnOpts.Timeouts[fab.Execute]),
 		contextImpl.WithParent(txnOpts.ParentContext))
 	//Add timeout overrides here as a value so that it can be used by immediate child contexts (in handlers/transactors)
 	reqCtx = reqContext.WithValue(reqCtx, contextImpl.ReqContextTimeoutOverrides, txnOpts.Timeouts)
@@ -213,6 +270,15 @@ func (cc *Client) createReqContext(txnOpts *requestOptions) (reqContext.Context,
 	return reqCtx, cancel
 }
 
+//requestClient returns the client context used to sign the request, which carries the signing identity
+//of the request options if one was given
+func (cc *Client) requestClient(txnOpts *requestOptions) context.Client {
+	if txnOpts.Identity == nil {
+		return cc.context
+	}
+	return &contextImpl.Client{Providers: cc.context, SigningIdentity: txnOpts.Identity}
+}
+
 //prepareHandlerContexts prepares context objects for handlers
 func (cc *Client) prepareHandlerContexts(reqCtx reqContext.Context, request Request, o requestOptions) (*invoke.RequestContext, *invoke.ClientContext, error) {
 
@@ -253,6 +319,7 @@ func (cc *Client) prepareHandlerContexts(reqCtx reqContext.Context, request Requ
 	}
 
 	clientContext := &invoke.ClientContext{
+		CryptoSuite:  cc.context.CryptoSuite(),
 		Selection:    selection,
 		Discovery:    discovery,
 		Membership:   cc.membership,
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package channel
 
 import (
+	reqContext "context"
 	"fmt"
 	"strings"
 	"testing"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/fabric-protos-go/common"
 	pb "github.com/hyperledger/fabric-protos-go/peer"
@@ -24,6 +26,8 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
@@ -114,6 +118,31 @@ func TestQuery(t *testing.T) {
 
 }
 
+func TestQueryWithQuorum(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.Payload = []byte("value")
+	testPeer2 := fcmocks.NewMockPeer("Peer2", "http://peer2.com")
+	testPeer2.Payload = []byte("value")
+	testPeer3 := fcmocks.NewMockPeer("Peer3", "http://peer3.com")
+	testPeer3.Payload = []byte("forged")
+	chClient := setupChannelClient(nil, t)
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+
+	_, err := chClient.Query(request, WithQuorum(1, 2))
+	assert.Error(t, err, "expected error for more matches than targets")
+
+	response, err := chClient.Query(request, WithTargets(testPeer1, testPeer2, testPeer3), WithQuorum(3, 2))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), response.Payload)
+	assert.Len(t, response.Responses, 2)
+
+	response, err = chClient.Query(request, WithTargets(testPeer1, testPeer2, testPeer3), WithQuorum(3, 3))
+	require.Error(t, err)
+	_, ok := txn.EndorsementMismatchFromError(err)
+	assert.True(t, ok, "expected endorsement mismatch diagnostics")
+	assert.Len(t, response.Responses, 3, "expected divergent responses to be returned")
+}
+
 func TestQuerySelectionError(t *testing.T) {
 	chClient := setupChannelClientWithError(nil, errors.New("Test Error"), nil, t)
 
@@ -251,6 +280,67 @@ func TestInvokeHandler(t *testing.T) {
 	}
 }
 
+// clientContextHandler records the client context passed to the handler
+type clientContextHandler struct {
+	clientContext *invoke.ClientContext
+}
+
+func (h *clientContextHandler) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	h.clientContext = clientContext
+}
+
+func TestInvokeHandlerClientContext(t *testing.T) {
+	chClient := setupChannelClient(nil, t)
+
+	handler := &clientContextHandler{}
+	if _, err := chClient.InvokeHandler(handler, Request{ChaincodeID: "testCC", Fcn: "move"}); err != nil {
+		t.Fatalf("Should have succeeded but got error %s", err)
+	}
+	if handler.clientContext.CryptoSuite == nil {
+		t.Fatal("Expecting crypto suite to be set in the client context, as required by the endorsement policy handler")
+	}
+}
+
+// identityHandler records the identity of the request context
+type identityHandler struct {
+	identity msp.SigningIdentity
+}
+
+func (h *identityHandler) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	ctx, ok := contextImpl.RequestClientContext(requestContext.Ctx)
+	if !ok {
+		requestContext.Error = errors.New("client context not found in request context")
+		return
+	}
+	h.identity = ctx
+}
+
+func TestInvokeHandlerWithSigningIdentity(t *testing.T) {
+	chClient := setupChannelClient(nil, t)
+	request := Request{ChaincodeID: "testCC", Fcn: "move", Args: [][]byte{[]byte("a"), []byte("b"), []byte("1")}}
+
+	handler := &identityHandler{}
+	if _, err := chClient.InvokeHandler(handler, request); err != nil {
+		t.Fatalf("Should have succeeded but got error %s", err)
+	}
+	if handler.identity.Identifier().ID != "test" {
+		t.Fatalf("Expecting identity of channel context but got [%s]", handler.identity.Identifier().ID)
+	}
+
+	user := mspmocks.NewMockSigningIdentity("user1", "Org2MSP")
+	if _, err := chClient.InvokeHandler(handler, request, WithSigningIdentity(user)); err != nil {
+		t.Fatalf("Should have succeeded but got error %s", err)
+	}
+	if handler.identity.Identifier().ID != "user1" || handler.identity.Identifier().MSPID != "Org2MSP" {
+		t.Fatalf("Expecting identity [user1] but got [%s]", handler.identity.Identifier().ID)
+	}
+
+	_, err := chClient.InvokeHandler(handler, request, WithSigningIdentity(nil))
+	if err == nil {
+		t.Fatal("Should have failed for nil signing identity")
+	}
+}
+
 // customEndorsementHandler ignores the channel in the ClientContext
 // and instead sends the proposal to the given channel
 type customEndorsementHandler struct {
@@ -511,6 +601,54 @@ func TestDiscoveryGreylist(t *testing.T) {
 
 }
 
+func TestTracing(t *testing.T) {
+	tracer := tracing.NewInMemoryTracer()
+	clientProvider := setupCustomTestContext(t, txnmocks.NewMockSelectionService(nil, fcmocks.NewMockPeer("Peer1", "http://peer1.com")), txnmocks.NewMockDiscoveryService(nil), nil)
+	clientCtx, err := clientProvider()
+	require.NoError(t, err)
+	clientCtx.(*fcmocks.MockContext).SetTracer(tracer)
+
+	chClient, err := New(createChannelContext(clientProvider, channelID))
+	require.NoError(t, err)
+
+	parentCtx, parentSpan := tracer.Start(reqContext.Background(), "application")
+	response, err := chClient.Execute(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}},
+		WithParentContext(parentCtx))
+	require.NoError(t, err)
+	parentSpan.End()
+
+	application := tracer.SpansByName("application")
+	require.Len(t, application, 1)
+
+	invocations := tracer.SpansByName("channel.Invoke")
+	require.Len(t, invocations, 1)
+	invocation := invocations[0]
+	assert.Equal(t, application[0].ID, invocation.ParentID, "expected span to be a child of the parent context's span")
+	assert.Equal(t, channelID, invocation.Attributes[tracing.ChannelKey])
+	assert.Equal(t, "testCC", invocation.Attributes[tracing.ChaincodeKey])
+	assert.Equal(t, "invoke", invocation.Attributes[tracing.FunctionKey])
+	assert.Equal(t, string(response.TransactionID), invocation.Attributes[tracing.TxIDKey])
+	assert.True(t, invocation.Ended)
+	assert.Empty(t, invocation.Errors)
+
+	for _, name := range []string{"selection.GetEndorsers", "invoke.ValidateSignatures", "invoke.WaitForCommit"} {
+		spans := tracer.SpansByName(name)
+		require.Len(t, spans, 1, "expected span [%s]", name)
+		assert.Equal(t, invocation.ID, spans[0].ParentID, "expected span [%s] to be a child of the invocation", name)
+		assert.True(t, spans[0].Ended)
+	}
+	commit := tracer.SpansByName("invoke.WaitForCommit")[0]
+	assert.Equal(t, string(response.TransactionID), commit.Attributes[tracing.TxIDKey])
+	assert.Equal(t, pb.TxValidationCode_VALID.String(), commit.Attributes[tracing.TxValidationCodeKey])
+
+	tracer.Reset()
+	_, err = chClient.Execute(Request{ChaincodeID: "testCC"})
+	require.Error(t, err)
+	invocations = tracer.SpansByName("channel.Invoke")
+	require.Len(t, invocations, 1)
+	assert.NotEmpty(t, invocations[0].Errors, "expected error to be recorded")
+}
+
 func setupTestChannelService(ctx context.Client, orderers []fab.Orderer) (fab.ChannelService, error) {
 	chProvider, err := fcmocks.NewMockChannelProvider(ctx)
 	if err != nil {
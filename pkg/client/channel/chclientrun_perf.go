@@ -70,3 +70,11 @@ func callExecute(cc *Client, request Request, options ...RequestOption) (Respons
 	cc.metrics.ExecutionDuration.With(meterLabels...).Observe(time.Since(startTime).Seconds())
 	return r, err
 }
+
+func recordAdmissionWait(cc *Client, request Request, wait time.Duration) {
+	cc.metrics.AdmissionWaitDuration.With("chaincode", request.ChaincodeID, "Fcn", request.Fcn).Observe(wait.Seconds())
+}
+
+func recordAdmissionRejection(cc *Client, request Request, reason string) {
+	cc.metrics.AdmissionRejections.With("chaincode", request.ChaincodeID, "Fcn", request.Fcn, "reason", reason).Add(1)
+}
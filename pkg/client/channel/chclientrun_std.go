@@ -10,6 +10,8 @@ SPDX-License-Identifier: Apache-2.0
 package channel
 
 import (
+	"time"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
 )
 
@@ -20,3 +22,9 @@ func callQuery(cc *Client, request Request, options ...RequestOption) (Response,
 func callExecute(cc *Client, request Request, options ...RequestOption) (Response, error) {
 	return cc.InvokeHandler(invoke.NewExecuteHandler(), request, options...)
 }
+
+func recordAdmissionWait(cc *Client, request Request, wait time.Duration) {
+}
+
+func recordAdmissionRejection(cc *Client, request Request, reason string) {
+}
@@ -0,0 +1,59 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+)
+
+// conflictRetryableCodes are the validation codes of transactions that are re-run when WithConflictRetry is used
+var conflictRetryableCodes = map[status.Group][]status.Code{
+	status.EventServerStatus: {
+		status.Code(pb.TxValidationCode_MVCC_READ_CONFLICT),
+		status.Code(pb.TxValidationCode_PHANTOM_READ_CONFLICT),
+	},
+}
+
+// conflictRetryHandler retries transactions that were invalidated because of read conflicts according to the
+// conflict retry options, and delegates the decision for all other errors to the retry handler of the request
+type conflictRetryHandler struct {
+	conflict retry.Handler
+	other    retry.Handler
+}
+
+func newConflictRetryHandler(opts retry.Opts, other retry.Handler) *conflictRetryHandler {
+	opts.RetryableCodes = conflictRetryableCodes
+	return &conflictRetryHandler{conflict: retry.New(opts), other: other}
+}
+
+// Required determines if retry is required for the given error
+func (h *conflictRetryHandler) Required(err error) bool {
+	if isReadConflict(err) {
+		return h.conflict.Required(err)
+	}
+	return h.other.Required(err)
+}
+
+func isReadConflict(err error) bool {
+	s, ok := status.FromError(err)
+	if !ok || s.Group != status.EventServerStatus {
+		return false
+	}
+	return s.Code == int32(pb.TxValidationCode_MVCC_READ_CONFLICT) || s.Code == int32(pb.TxValidationCode_PHANTOM_READ_CONFLICT)
+}
+
+// newTransactionAttempt records the outcome of an attempt to commit the transaction of the request
+func newTransactionAttempt(requestContext *invoke.RequestContext) *invoke.TransactionAttempt {
+	return &invoke.TransactionAttempt{
+		TransactionID:    requestContext.Response.TransactionID,
+		TxValidationCode: requestContext.Response.TxValidationCode,
+		Error:            requestContext.Error,
+	}
+}
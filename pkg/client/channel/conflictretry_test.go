@@ -0,0 +1,97 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+var testConflictRetry = retry.Opts{Attempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, BackoffFactor: 1}
+
+func TestConflictRetry(t *testing.T) {
+	chClient := setupChannelClient(nil, t)
+	request := Request{ChaincodeID: "testCC", Fcn: "move", Args: [][]byte{[]byte("a"), []byte("b"), []byte("1")}}
+
+	t.Run("committed after conflicts", func(t *testing.T) {
+		handler := &conflictHandler{codes: []pb.TxValidationCode{pb.TxValidationCode_MVCC_READ_CONFLICT, pb.TxValidationCode_PHANTOM_READ_CONFLICT}}
+		resp, err := chClient.InvokeHandler(handler, request, WithConflictRetry(testConflictRetry))
+		require.NoError(t, err)
+		assert.Equal(t, fab.TransactionID("tx3"), resp.TransactionID)
+
+		require.Len(t, resp.Attempts, 3)
+		assert.Equal(t, fab.TransactionID("tx1"), resp.Attempts[0].TransactionID)
+		assert.Equal(t, pb.TxValidationCode_MVCC_READ_CONFLICT, resp.Attempts[0].TxValidationCode)
+		assert.Error(t, resp.Attempts[0].Error)
+		assert.Equal(t, pb.TxValidationCode_PHANTOM_READ_CONFLICT, resp.Attempts[1].TxValidationCode)
+		assert.Equal(t, fab.TransactionID("tx3"), resp.Attempts[2].TransactionID)
+		assert.Equal(t, pb.TxValidationCode_VALID, resp.Attempts[2].TxValidationCode)
+		assert.NoError(t, resp.Attempts[2].Error)
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		handler := &conflictHandler{codes: []pb.TxValidationCode{pb.TxValidationCode_MVCC_READ_CONFLICT, pb.TxValidationCode_MVCC_READ_CONFLICT, pb.TxValidationCode_MVCC_READ_CONFLICT}}
+		resp, err := chClient.InvokeHandler(handler, request, WithConflictRetry(testConflictRetry))
+		require.Error(t, err)
+		s, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.EqualValues(t, pb.TxValidationCode_MVCC_READ_CONFLICT, s.Code)
+		assert.Len(t, resp.Attempts, 3)
+		assert.Equal(t, 3, handler.calls)
+	})
+
+	t.Run("other validation codes not retried", func(t *testing.T) {
+		handler := &conflictHandler{codes: []pb.TxValidationCode{pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE}}
+		resp, err := chClient.InvokeHandler(handler, request, WithConflictRetry(testConflictRetry))
+		require.Error(t, err)
+		assert.Len(t, resp.Attempts, 1)
+		assert.Equal(t, 1, handler.calls)
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		handler := &conflictHandler{codes: []pb.TxValidationCode{pb.TxValidationCode_MVCC_READ_CONFLICT}}
+		resp, err := chClient.InvokeHandler(handler, request)
+		require.Error(t, err)
+		assert.Nil(t, resp.Attempts)
+		assert.Equal(t, 1, handler.calls)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := chClient.InvokeHandler(&conflictHandler{}, request, WithConflictRetry(retry.Opts{}))
+		assert.Error(t, err)
+	})
+}
+
+// conflictHandler commits a new transaction on each call, which is invalidated with the given validation codes in turn
+type conflictHandler struct {
+	codes []pb.TxValidationCode
+	calls int
+}
+
+func (h *conflictHandler) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	h.calls++
+	requestContext.Response.TransactionID = fab.TransactionID(fmt.Sprintf("tx%d", h.calls))
+
+	if h.calls > len(h.codes) {
+		requestContext.Response.TxValidationCode = pb.TxValidationCode_VALID
+		return
+	}
+
+	code := h.codes[h.calls-1]
+	requestContext.Response.TxValidationCode = code
+	requestContext.Error = status.New(status.EventServerStatus, int32(code), "received invalid transaction", nil)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"regexp"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/blockdecoder"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	fabchannel "github.com/hyperledger/fabric-sdk-go/pkg/fab/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+// NewNonce creates a random nonce that may be passed to WithNonce
+func NewNonce() ([]byte, error) {
+	return crypto.GetRandomNonce()
+}
+
+// transactionNotFoundPattern matches the errors returned by the peers for a transaction ID which is not in
+// the ledger (Fabric 2.x and 1.4 respectively)
+var transactionNotFoundPattern = regexp.MustCompile(`(no such transaction ID)|(Entry not found in index)`)
+
+// committedTransactionQuery queries the ledger for a committed transaction
+type committedTransactionQuery func(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext, txID fab.TransactionID) (*pb.ProcessedTransaction, error)
+
+// pinnedTransaction tracks a transaction whose transaction ID is pinned by the caller using WithNonce.
+// Before the transaction is submitted, including the first attempt, the ledger is queried for the transaction
+// ID so that a transaction which was committed, although its outcome was not received, is not submitted again.
+type pinnedTransaction struct {
+	txID      fab.TransactionID
+	query     committedTransactionQuery
+	next      retry.Handler
+	previous  invoke.Response
+	committed bool
+}
+
+func (cc *Client) newPinnedTransaction(txnOpts *requestOptions, next retry.Handler) (*pinnedTransaction, error) {
+	header, err := txn.NewHeader(cc.requestClient(txnOpts), cc.context.ChannelID(), fab.WithNonce(txnOpts.Nonce))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to compute transaction ID")
+	}
+
+	return &pinnedTransaction{
+		txID:  header.TransactionID(),
+		query: cc.queryCommittedTransaction,
+		next:  next,
+	}, nil
+}
+
+// Required determines if retry is required for the given error. A transaction that was found on the
+// ledger is never retried, since a transaction ID may only be committed once.
+func (p *pinnedTransaction) Required(err error) bool {
+	if p.committed {
+		return false
+	}
+	return p.next.Required(err)
+}
+
+// beforeRetry records the response of the failed attempt, which is returned if the transaction turns out
+// to have been committed
+func (p *pinnedTransaction) beforeRetry(response invoke.Response) {
+	p.previous = response
+}
+
+// resolveCommitted queries the ledger for the transaction before it is submitted. It returns true if the
+// transaction must not be submitted, in which case the response and error of the request context are set:
+// either the transaction has already been committed, or the ledger could not be queried and the error is
+// returned along with the pinned transaction ID. The transaction is only submitted if the peers report that
+// the transaction ID is not in the ledger.
+func (p *pinnedTransaction) resolveCommitted(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) bool {
+	tx, err := p.query(requestContext, clientContext, p.txID)
+	if err != nil {
+		if isTransactionNotFound(err) {
+			logger.Debugf("transaction [%s] not found on the ledger, submitting", p.txID)
+			return false
+		}
+
+		requestContext.Response = p.previous
+		requestContext.Response.TransactionID = p.txID
+		requestContext.Error = errors.WithMessagef(err, "failed to determine whether transaction [%s] has been committed", p.txID)
+		return true
+	}
+
+	p.setCommitted(requestContext, p.previous, tx)
+	return true
+}
+
+// resolveDuplicate queries the ledger for the transaction if it was invalidated as a duplicate, which means that
+// a previous submission of the transaction was committed, and sets the response and error of the request context
+// from the committed transaction
+func (p *pinnedTransaction) resolveDuplicate(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	s, ok := status.FromError(requestContext.Error)
+	if !ok || s.Group != status.EventServerStatus || s.Code != int32(pb.TxValidationCode_DUPLICATE_TXID) {
+		return
+	}
+
+	// the transaction has been committed, so it is never resubmitted
+	p.committed = true
+
+	tx, err := p.query(requestContext, clientContext, p.txID)
+	if err != nil {
+		requestContext.Response.TransactionID = p.txID
+		requestContext.Error = errors.WithMessagef(err, "transaction [%s] has already been committed, but its validation code could not be queried", p.txID)
+		return
+	}
+
+	p.setCommitted(requestContext, requestContext.Response, tx)
+}
+
+// setCommitted sets the response and error of the request context from the committed transaction. If the given
+// response doesn't contain the response of the chaincode, e.g. if the transaction was committed by a previous
+// call of the caller, it is taken from the committed transaction.
+func (p *pinnedTransaction) setCommitted(requestContext *invoke.RequestContext, response invoke.Response, tx *pb.ProcessedTransaction) {
+	logger.Debugf("transaction [%s] has already been committed with validation code [%s]", p.txID, pb.TxValidationCode(tx.ValidationCode))
+
+	p.committed = true
+	requestContext.Response = response
+	requestContext.Response.TransactionID = p.txID
+	requestContext.Response.TxValidationCode = pb.TxValidationCode(tx.ValidationCode)
+	requestContext.Error = nil
+	if requestContext.Response.Payload == nil {
+		setChaincodeResponse(&requestContext.Response, tx)
+	}
+	if requestContext.Response.TxValidationCode != pb.TxValidationCode_VALID {
+		requestContext.Error = status.New(status.EventServerStatus, tx.ValidationCode, "received invalid transaction", nil)
+	}
+}
+
+// setChaincodeResponse sets the chaincode status and payload of the response from the committed transaction
+func setChaincodeResponse(response *invoke.Response, tx *pb.ProcessedTransaction) {
+	if tx.TransactionEnvelope == nil {
+		return
+	}
+	decoded, err := blockdecoder.DecodeEnvelope(tx.TransactionEnvelope)
+	if err != nil {
+		logger.Warnf("failed to decode committed transaction [%s]: %s", response.TransactionID, err)
+		return
+	}
+	if len(decoded.Actions) == 0 || decoded.Actions[0].Response == nil {
+		return
+	}
+	response.ChaincodeStatus = decoded.Actions[0].Response.Status
+	response.Payload = decoded.Actions[0].Response.Payload
+}
+
+// isTransactionNotFound returns true if all of the queried peers reported that the transaction is not in the ledger
+func isTransactionNotFound(err error) bool {
+	errs, ok := errors.Cause(err).(multi.Errors)
+	if !ok {
+		errs = multi.Errors{err}
+	}
+	for _, e := range errs {
+		s, ok := status.FromError(e)
+		if !ok || s.Group != status.ChaincodeStatus || !transactionNotFoundPattern.MatchString(s.Message) {
+			return false
+		}
+	}
+	return len(errs) > 0
+}
+
+// queryCommittedTransaction queries the peers of the organization of the request identity, or all peers of
+// the channel if the organization has none, for the transaction
+func (cc *Client) queryCommittedTransaction(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext, txID fab.TransactionID) (*pb.ProcessedTransaction, error) {
+	ctx, ok := contextImpl.RequestClientContext(requestContext.Ctx)
+	if !ok {
+		return nil, errors.New("failed get client context from reqContext for transaction query")
+	}
+
+	peers, err := clientContext.Discovery.GetPeers()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get peers")
+	}
+
+	var targets []fab.Peer
+	for _, p := range peers {
+		if p.MSPID() == ctx.Identifier().MSPID {
+			targets = append(targets, p)
+		}
+	}
+	if len(targets) == 0 {
+		targets = peers
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("no peers to query")
+	}
+
+	ledger, err := fabchannel.NewLedger(cc.context.ChannelID())
+	if err != nil {
+		return nil, err
+	}
+
+	txs, err := ledger.QueryTransaction(requestContext.Ctx, txID, peer.PeersToTxnProcessors(targets), nil)
+	if len(txs) == 0 {
+		if err == nil {
+			err = errors.New("no response")
+		}
+		return nil, err
+	}
+	return txs[0], nil
+}
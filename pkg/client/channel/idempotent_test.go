@@ -0,0 +1,220 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+)
+
+var testTimeoutRetry = retry.Opts{
+	Attempts:       1,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     time.Millisecond,
+	BackoffFactor:  1,
+	RetryableCodes: map[status.Group][]status.Code{status.ClientStatus: {status.Timeout}},
+}
+
+func TestWithNonce(t *testing.T) {
+	chClient := newNonceTestClient(t, newNotFoundPeer())
+	request := Request{ChaincodeID: "testCC", Fcn: "move", Args: [][]byte{[]byte("a"), []byte("b"), []byte("1")}}
+
+	_, err := chClient.InvokeHandler(&timeoutHandler{}, request, WithNonce(nil))
+	assert.Error(t, err, "expected error for empty nonce")
+
+	_, err = chClient.InvokeHandler(&timeoutHandler{}, request, WithNonce([]byte("nonce")), WithConflictRetry(testConflictRetry))
+	assert.Error(t, err, "expected error for nonce combined with conflict retry")
+
+	nonce, err := NewNonce()
+	require.NoError(t, err)
+
+	handler := &timeoutHandler{}
+	_, err = chClient.InvokeHandler(handler, request, WithNonce(nonce), WithRetry(testTimeoutRetry))
+	require.Error(t, err)
+	assert.Equal(t, 2, handler.calls, "expected transaction to be resubmitted when it is not found on the ledger")
+}
+
+func TestWithNonceCommitted(t *testing.T) {
+	org := fcmocks.NewMockOrdererOrg("Org1MSP")
+	envelopeBytes, _ := fcmocks.NewMockEndorserTransaction(channelID, org, org)
+	envelope := &common.Envelope{}
+	require.NoError(t, proto.Unmarshal(envelopeBytes, envelope))
+
+	payload, err := proto.Marshal(&pb.ProcessedTransaction{TransactionEnvelope: envelope, ValidationCode: int32(pb.TxValidationCode_VALID)})
+	require.NoError(t, err)
+
+	peer := fcmocks.NewMockPeer("peer1", "peer1.example.com:7051")
+	peer.Payload = payload
+	chClient := newNonceTestClient(t, peer)
+
+	// the caller resubmits a request whose transaction was committed after the first call timed out
+	handler := &timeoutHandler{}
+	request := Request{ChaincodeID: "testCC", Fcn: "move", Args: [][]byte{[]byte("a"), []byte("b"), []byte("1")}}
+	resp, err := chClient.InvokeHandler(handler, request, WithNonce([]byte("nonce")), WithRetry(testTimeoutRetry))
+	require.NoError(t, err)
+	assert.Equal(t, 0, handler.calls, "expected committed transaction not to be submitted again")
+	assert.Equal(t, pb.TxValidationCode_VALID, resp.TxValidationCode)
+	assert.Equal(t, []byte("result"), resp.Payload, "expected response of the committed transaction")
+	assert.NotEmpty(t, resp.TransactionID)
+}
+
+func TestWithNonceQueryError(t *testing.T) {
+	peer := fcmocks.NewMockPeer("peer1", "peer1.example.com:7051")
+	peer.Error = status.New(status.EndorserClientStatus, status.ConnectionFailed.ToInt32(), "connection failed", nil)
+	chClient := newNonceTestClient(t, peer)
+
+	handler := &timeoutHandler{}
+	request := Request{ChaincodeID: "testCC", Fcn: "move", Args: [][]byte{[]byte("a"), []byte("b"), []byte("1")}}
+	resp, err := chClient.InvokeHandler(handler, request, WithNonce([]byte("nonce")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to determine whether transaction")
+	assert.Equal(t, 0, handler.calls, "expected transaction not to be submitted when the ledger cannot be queried")
+	assert.NotEmpty(t, resp.TransactionID, "expected pinned transaction ID with the error")
+}
+
+func TestWithNonceSigningIdentity(t *testing.T) {
+	org1Peer := newNotFoundPeer()
+	org2Peer := newNotFoundPeer()
+	org2Peer.MockMSP = "Org2MSP"
+	chClient := newNonceTestClient(t, org1Peer, org2Peer)
+
+	request := Request{ChaincodeID: "testCC", Fcn: "move", Args: [][]byte{[]byte("a"), []byte("b"), []byte("1")}}
+	user := mspmocks.NewMockSigningIdentity("user1", "Org2MSP")
+	_, err := chClient.InvokeHandler(&timeoutHandler{}, request, WithNonce([]byte("nonce")), WithSigningIdentity(user))
+	require.Error(t, err)
+	assert.Equal(t, 0, org1Peer.ProcessProposalCalls, "expected peers of the client's organization not to be queried")
+	assert.Equal(t, 1, org2Peer.ProcessProposalCalls, "expected peers of the organization of the request identity to be queried")
+}
+
+func TestPinnedTransaction(t *testing.T) {
+	committed := func(code pb.TxValidationCode) committedTransactionQuery {
+		return func(*invoke.RequestContext, *invoke.ClientContext, fab.TransactionID) (*pb.ProcessedTransaction, error) {
+			return &pb.ProcessedTransaction{ValidationCode: int32(code)}, nil
+		}
+	}
+	notFound := func(*invoke.RequestContext, *invoke.ClientContext, fab.TransactionID) (*pb.ProcessedTransaction, error) {
+		return nil, multi.Errors{newNotFoundStatus(), newNotFoundStatus()}
+	}
+	timeout := status.New(status.ClientStatus, status.Timeout.ToInt32(), "timeout", nil)
+
+	t.Run("first attempt committed", func(t *testing.T) {
+		pinned := &pinnedTransaction{txID: "txid", query: committed(pb.TxValidationCode_VALID), next: retry.New(testTimeoutRetry)}
+		requestContext := &invoke.RequestContext{}
+		require.True(t, pinned.resolveCommitted(requestContext, &invoke.ClientContext{}))
+		require.NoError(t, requestContext.Error)
+		assert.Equal(t, fab.TransactionID("txid"), requestContext.Response.TransactionID)
+	})
+
+	t.Run("not committed", func(t *testing.T) {
+		pinned := &pinnedTransaction{txID: "txid", next: retry.New(testTimeoutRetry), query: notFound}
+		pinned.beforeRetry(invoke.Response{})
+		assert.False(t, pinned.resolveCommitted(&invoke.RequestContext{}, &invoke.ClientContext{}))
+		assert.True(t, pinned.Required(timeout))
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		queryErr := multi.Errors{newNotFoundStatus(), status.New(status.GRPCTransportStatus, 14, "unavailable", nil)}
+		pinned := &pinnedTransaction{txID: "txid", next: retry.New(testTimeoutRetry),
+			query: func(*invoke.RequestContext, *invoke.ClientContext, fab.TransactionID) (*pb.ProcessedTransaction, error) {
+				return nil, queryErr
+			},
+		}
+		requestContext := &invoke.RequestContext{}
+		require.True(t, pinned.resolveCommitted(requestContext, &invoke.ClientContext{}), "expected transaction not to be submitted unless it is definitely not found")
+		require.Error(t, requestContext.Error)
+		assert.Equal(t, fab.TransactionID("txid"), requestContext.Response.TransactionID)
+
+		queryErr = multi.Errors{status.New(status.ChaincodeStatus, 500, "chaincode error", nil)}
+		requestContext = &invoke.RequestContext{}
+		assert.True(t, pinned.resolveCommitted(requestContext, &invoke.ClientContext{}))
+		assert.Error(t, requestContext.Error)
+	})
+
+	t.Run("committed invalid", func(t *testing.T) {
+		pinned := &pinnedTransaction{txID: "txid", query: committed(pb.TxValidationCode_MVCC_READ_CONFLICT), next: retry.New(testTimeoutRetry)}
+		pinned.beforeRetry(invoke.Response{Payload: []byte("payload")})
+
+		requestContext := &invoke.RequestContext{}
+		require.True(t, pinned.resolveCommitted(requestContext, &invoke.ClientContext{}))
+		assert.Equal(t, fab.TransactionID("txid"), requestContext.Response.TransactionID)
+		assert.Equal(t, []byte("payload"), requestContext.Response.Payload)
+
+		s, ok := status.FromError(requestContext.Error)
+		require.True(t, ok)
+		assert.Equal(t, status.EventServerStatus, s.Group)
+		assert.EqualValues(t, pb.TxValidationCode_MVCC_READ_CONFLICT, s.Code)
+		assert.False(t, pinned.Required(requestContext.Error), "expected committed transaction not to be retried")
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		pinned := &pinnedTransaction{txID: "txid", query: committed(pb.TxValidationCode_VALID), next: retry.New(testTimeoutRetry)}
+		requestContext := &invoke.RequestContext{
+			Response: invoke.Response{TransactionID: "txid", Payload: []byte("payload"), TxValidationCode: pb.TxValidationCode_DUPLICATE_TXID},
+			Error:    status.New(status.EventServerStatus, int32(pb.TxValidationCode_DUPLICATE_TXID), "received invalid transaction", nil),
+		}
+		pinned.resolveDuplicate(requestContext, &invoke.ClientContext{})
+		require.NoError(t, requestContext.Error, "expected outcome of the committed transaction")
+		assert.Equal(t, pb.TxValidationCode_VALID, requestContext.Response.TxValidationCode)
+		assert.Equal(t, []byte("payload"), requestContext.Response.Payload)
+
+		pinned = &pinnedTransaction{txID: "txid", query: notFound, next: retry.New(testTimeoutRetry)}
+		requestContext.Error = status.New(status.EventServerStatus, int32(pb.TxValidationCode_DUPLICATE_TXID), "received invalid transaction", nil)
+		pinned.resolveDuplicate(requestContext, &invoke.ClientContext{})
+		require.Error(t, requestContext.Error)
+		assert.False(t, pinned.Required(timeout), "expected duplicate transaction not to be resubmitted")
+
+		pinned = &pinnedTransaction{txID: "txid", query: committed(pb.TxValidationCode_VALID), next: retry.New(testTimeoutRetry)}
+		requestContext = &invoke.RequestContext{Error: timeout}
+		pinned.resolveDuplicate(requestContext, &invoke.ClientContext{})
+		assert.Equal(t, timeout, requestContext.Error, "expected other errors to be left unchanged")
+	})
+}
+
+func newNonceTestClient(t *testing.T, peers ...fab.Peer) *Client {
+	fabCtx := setupCustomTestContext(t, txnmocks.NewMockSelectionService(nil), txnmocks.NewMockDiscoveryService(nil, peers...), nil)
+	chClient, err := New(createChannelContext(fabCtx, channelID))
+	require.NoError(t, err)
+	return chClient
+}
+
+// newNotFoundPeer returns a peer which reports that transactions are not in its ledger
+func newNotFoundPeer() *fcmocks.MockPeer {
+	peer := fcmocks.NewMockPeer("peer1", "peer1.example.com:7051")
+	peer.Status = 500
+	peer.Error = newNotFoundStatus()
+	return peer
+}
+
+func newNotFoundStatus() *status.Status {
+	return status.New(status.ChaincodeStatus, 500, "Failed to get transaction with id txid, error no such transaction ID [txid] in index", nil)
+}
+
+// timeoutHandler endorses a transaction on each call, whose commit times out
+type timeoutHandler struct {
+	calls int
+}
+
+func (h *timeoutHandler) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	h.calls++
+	requestContext.Response.Payload = []byte("payload")
+	requestContext.Error = status.New(status.ClientStatus, status.Timeout.ToInt32(), "Execute didn't receive block event", nil)
+}
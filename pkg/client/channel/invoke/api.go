@@ -16,6 +16,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 )
 
 // CCFilter returns true if the given chaincode should be included
@@ -32,6 +33,18 @@ type Opts struct {
 	Timeouts      map[fab.TimeoutType]time.Duration
 	ParentContext reqContext.Context //parent grpc context
 	CCFilter      CCFilter
+	Identity      msp.SigningIdentity //signing identity overriding the identity of the channel context
+	ConflictRetry retry.Opts          //retries of transactions invalidated by read conflicts
+	Nonce         []byte              //nonce pinning the transaction ID across retries
+	Quorum        QuorumOpts          //number of peers whose query results must match
+}
+
+// QuorumOpts requires the results of a query to match across peers
+type QuorumOpts struct {
+	// Targets is the number of peers the query is sent to
+	Targets int
+	// Matches is the number of peers which must return matching results
+	Matches int
 }
 
 // Request contains the parameters to execute transaction
@@ -61,6 +74,14 @@ type Response struct {
 	TxValidationCode pb.TxValidationCode
 	ChaincodeStatus  int32
 	Payload          []byte
+	Attempts         []*TransactionAttempt
+}
+
+// TransactionAttempt is the outcome of an attempt to commit a transaction
+type TransactionAttempt struct {
+	TransactionID    fab.TransactionID
+	TxValidationCode pb.TxValidationCode
+	Error            error
 }
 
 //Handler for chaining transaction executions
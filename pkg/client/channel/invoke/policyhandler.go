@@ -0,0 +1,467 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	lb "github.com/hyperledger/fabric-protos-go/peer/lifecycle"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/internal/policyeval"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/channel/membership"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+)
+
+// defaultPolicyCacheExpiry is the time for which the endorsement policies of a chaincode are cached
+const defaultPolicyCacheExpiry = time.Minute
+
+// EndorsementPolicies contains the endorsement policies defined by the definition of a chaincode. A policy is
+// either a signature policy or a reference to a policy of the channel config (e.g. /Channel/Application/Endorsement).
+type EndorsementPolicies struct {
+	// Chaincode is the endorsement policy of the chaincode
+	Chaincode *pb.ApplicationPolicy
+	// Collections contains the endorsement policies of the private data collections that define one
+	Collections map[string]*pb.ApplicationPolicy
+}
+
+// EndorsementPolicyProvider returns the endorsement policies defined by the definition of the given chaincode, or
+// nil if the chaincode has no definition whose policies can be checked
+type EndorsementPolicyProvider func(requestContext *RequestContext, clientContext *ClientContext, chaincodeID string) (*EndorsementPolicies, error)
+
+// EndorsementPolicyHandler checks that the endorsements collected for a transaction satisfy the endorsement
+// policies of the chaincodes and private data collections written by the transaction, so that a transaction
+// which would be invalidated with ENDORSEMENT_POLICY_FAILURE is not sent to the orderer.
+// The policy of a chaincode is used for its public writes, and for writes to collections that do not define
+// an endorsement policy of their own. Channel config policy references are evaluated against the policies of
+// the channel config. Key-level endorsement policies are not checked.
+// The policies of a chaincode are cached; they are queried again when they expire, or when the endorsements
+// don't satisfy the cached policies since the chaincode definition may have been updated.
+type EndorsementPolicyHandler struct {
+	next           Handler
+	channelCfg     fab.ChannelCfg
+	policyProvider EndorsementPolicyProvider
+
+	mutex        sync.Mutex
+	deserializer msp.IdentityDeserializer
+
+	cacheMutex  sync.Mutex
+	cacheExpiry time.Duration
+	policyCache map[string]*cachedPolicies
+}
+
+// cachedPolicies are the endorsement policies of a chaincode and the time at which they expire
+type cachedPolicies struct {
+	policies *EndorsementPolicies
+	expiry   time.Time
+}
+
+// Handle checks the endorsements of the transaction against the endorsement policies
+func (h *EndorsementPolicyHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	if err := h.check(requestContext, clientContext); err != nil {
+		requestContext.Error = errors.WithMessage(err, "endorsement policy check failed")
+		return
+	}
+
+	//Delegate to next step if any
+	if h.next != nil {
+		h.next.Handle(requestContext, clientContext)
+	}
+}
+
+// policyRequirement is an endorsement policy that must be satisfied by the endorsements of a transaction
+type policyRequirement struct {
+	name   string
+	policy *pb.ApplicationPolicy
+}
+
+func (h *EndorsementPolicyHandler) check(requestContext *RequestContext, clientContext *ClientContext) error {
+	responses := requestContext.Response.Responses
+	if len(responses) == 0 {
+		return errors.New("no endorsements to check")
+	}
+
+	rwSets, err := getRWSetsFromProposalResponse(responses[0].ProposalResponse)
+	if err != nil {
+		return errors.Wrap(err, "failed to get read/write sets from proposal response")
+	}
+
+	requirements, cached, err := h.requirements(requestContext, clientContext, rwSets)
+	if err != nil {
+		return err
+	}
+	if len(requirements) == 0 {
+		return nil
+	}
+
+	deserializer, err := h.identityDeserializer(clientContext)
+	if err != nil {
+		return err
+	}
+
+	identities := endorserIdentities(deserializer, responses)
+
+	failures := h.evaluate(requirements, identities)
+	if len(failures) > 0 && len(cached) > 0 {
+		logger.Debugf("Endorsements do not satisfy the cached endorsement policies of chaincodes %s - querying the policies again", cached)
+		h.invalidate(cached)
+
+		requirements, _, err = h.requirements(requestContext, clientContext, rwSets)
+		if err != nil {
+			return err
+		}
+		failures = h.evaluate(requirements, identities)
+	}
+
+	if len(failures) > 0 {
+		return status.New(status.EndorserClientStatus, status.EndorsementPolicyNotSatisfied.ToInt32(),
+			fmt.Sprintf("endorsements do not satisfy the endorsement policy: %s", strings.Join(failures, "; ")), nil)
+	}
+
+	return nil
+}
+
+// requirements returns the endorsement policies that apply to the writes of the transaction. If the
+// transaction does not write anything, the policy of the invoked chaincode applies. The chaincodes whose
+// policies were returned from the cache are also returned.
+func (h *EndorsementPolicyHandler) requirements(requestContext *RequestContext, clientContext *ClientContext, rwSets []*rwsetutil.NsRwSet) ([]*policyRequirement, []string, error) {
+	ccFilter := requestContext.Opts.CCFilter
+
+	var requirements []*policyRequirement
+	var cached []string
+	written := false
+	for _, rwSet := range rwSets {
+		publicWrites := len(rwSet.KvRwSet.GetWrites()) > 0 || len(rwSet.KvRwSet.GetMetadataWrites()) > 0
+
+		var collections []string
+		for _, collRWSet := range rwSet.CollHashedRwSets {
+			if len(collRWSet.HashedRwSet.GetHashedWrites()) > 0 || len(collRWSet.HashedRwSet.GetMetadataWrites()) > 0 {
+				collections = append(collections, collRWSet.CollectionName)
+			}
+		}
+
+		if !publicWrites && len(collections) == 0 {
+			continue
+		}
+		written = true
+
+		if ccFilter != nil && !ccFilter(rwSet.NameSpace) {
+			logger.Debugf("Chaincode [%s] is excluded by the chaincode filter - not checking its endorsement policy", rwSet.NameSpace)
+			continue
+		}
+
+		policies, fromCache, err := h.endorsementPolicies(requestContext, clientContext, rwSet.NameSpace)
+		if err != nil {
+			return nil, nil, errors.WithMessagef(err, "failed to get endorsement policies of chaincode [%s]", rwSet.NameSpace)
+		}
+		if fromCache {
+			cached = append(cached, rwSet.NameSpace)
+		}
+		if policies == nil {
+			continue
+		}
+
+		for _, coll := range collections {
+			if policy := policies.Collections[coll]; policy != nil {
+				requirements = append(requirements, &policyRequirement{name: fmt.Sprintf("collection [%s/%s]", rwSet.NameSpace, coll), policy: policy})
+			} else {
+				publicWrites = true
+			}
+		}
+
+		if publicWrites && policies.Chaincode != nil {
+			requirements = append(requirements, &policyRequirement{name: fmt.Sprintf("chaincode [%s]", rwSet.NameSpace), policy: policies.Chaincode})
+		}
+	}
+
+	if written {
+		return requirements, cached, nil
+	}
+
+	ccID := requestContext.Request.ChaincodeID
+	policies, fromCache, err := h.endorsementPolicies(requestContext, clientContext, ccID)
+	if err != nil {
+		return nil, nil, errors.WithMessagef(err, "failed to get endorsement policies of chaincode [%s]", ccID)
+	}
+	if fromCache {
+		cached = append(cached, ccID)
+	}
+	if policies == nil || policies.Chaincode == nil {
+		return nil, cached, nil
+	}
+	return []*policyRequirement{{name: fmt.Sprintf("chaincode [%s]", ccID), policy: policies.Chaincode}}, cached, nil
+}
+
+// evaluate returns a description of each requirement which is not satisfied by the identities
+func (h *EndorsementPolicyHandler) evaluate(requirements []*policyRequirement, identities []msp.Identity) []string {
+	var failures []string
+	for _, r := range requirements {
+		switch policy := r.policy.GetType().(type) {
+		case *pb.ApplicationPolicy_SignaturePolicy:
+			if ok, missing := policyeval.EvaluateSignaturePolicy(policy.SignaturePolicy, identities); !ok {
+				failures = append(failures, fmt.Sprintf("%s requires %s", r.name, missing))
+			}
+
+		case *pb.ApplicationPolicy_ChannelConfigPolicyReference:
+			var channelGroup *common.ConfigGroup
+			if h.channelCfg != nil && h.channelCfg.Versions() != nil {
+				channelGroup = h.channelCfg.Versions().Channel
+			}
+			if channelGroup == nil {
+				logger.Warnf("The channel config does not contain the policies of the channel - not checking channel config policy [%s] of %s", policy.ChannelConfigPolicyReference, r.name)
+				continue
+			}
+
+			if err := evaluateChannelConfigPolicy(channelGroup, policy.ChannelConfigPolicyReference, identities); err != nil {
+				failures = append(failures, fmt.Sprintf("%s requires channel config policy [%s]: %s", r.name, policy.ChannelConfigPolicyReference, err))
+			}
+
+		default:
+			failures = append(failures, fmt.Sprintf("%s has unsupported policy type %T", r.name, policy))
+		}
+	}
+	return failures
+}
+
+// evaluateChannelConfigPolicy evaluates a policy of the channel config, which is referenced by its absolute
+// path (e.g. /Channel/Application/Endorsement) or by its path relative to the channel group, in the same way
+// as the peers
+func evaluateChannelConfigPolicy(channelGroup *common.ConfigGroup, reference string, identities []msp.Identity) error {
+	path := strings.Split(reference, "/")
+	if strings.HasPrefix(reference, "/") {
+		if len(path) < 3 || path[1] != channelconfig.ChannelGroupKey {
+			return errors.Errorf("invalid channel config policy reference [%s]", reference)
+		}
+		path = path[2:]
+	}
+
+	group := channelGroup
+	for _, name := range path[:len(path)-1] {
+		group = group.Groups[name]
+		if group == nil {
+			return errors.Errorf("group %s is not defined", name)
+		}
+	}
+
+	return policyeval.EvaluateConfigPolicy(group, path[len(path)-1], identities)
+}
+
+// endorsementPolicies returns the endorsement policies of the chaincode from the cache, or from the policy
+// provider if they are not cached or have expired. It also returns whether the policies were cached.
+func (h *EndorsementPolicyHandler) endorsementPolicies(requestContext *RequestContext, clientContext *ClientContext, chaincodeID string) (*EndorsementPolicies, bool, error) {
+	h.cacheMutex.Lock()
+	entry, ok := h.policyCache[chaincodeID]
+	h.cacheMutex.Unlock()
+
+	if ok && time.Now().Before(entry.expiry) {
+		return entry.policies, true, nil
+	}
+
+	policies, err := h.policyProvider(requestContext, clientContext, chaincodeID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	h.cacheMutex.Lock()
+	h.policyCache[chaincodeID] = &cachedPolicies{policies: policies, expiry: time.Now().Add(h.cacheExpiry)}
+	h.cacheMutex.Unlock()
+
+	return policies, false, nil
+}
+
+// invalidate removes the endorsement policies of the given chaincodes from the cache
+func (h *EndorsementPolicyHandler) invalidate(chaincodeIDs []string) {
+	h.cacheMutex.Lock()
+	defer h.cacheMutex.Unlock()
+
+	for _, ccID := range chaincodeIDs {
+		delete(h.policyCache, ccID)
+	}
+}
+
+// identityDeserializer returns the deserializer for the identities of the channel members, which is created
+// from the MSP configuration of the channel on first use
+func (h *EndorsementPolicyHandler) identityDeserializer(clientContext *ClientContext) (msp.IdentityDeserializer, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.deserializer == nil {
+		if clientContext.CryptoSuite == nil {
+			return nil, errors.New("crypto suite is required to deserialize the identities of the endorsers")
+		}
+		mspManager, err := membership.NewMSPManager(h.channelCfg, clientContext.CryptoSuite)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to create MSP manager from channel config")
+		}
+		h.deserializer = mspManager
+	}
+
+	return h.deserializer, nil
+}
+
+// endorserIdentities returns the identities of the endorsers whose identity is valid and whose signature can be verified.
+// Each identity is included only once, since the committing peers ignore duplicate endorsements.
+func endorserIdentities(deserializer msp.IdentityDeserializer, responses []*fab.TransactionProposalResponse) []msp.Identity {
+	var identities []msp.Identity
+	seen := make(map[string]bool)
+	for _, r := range responses {
+		endorsement := r.ProposalResponse.GetEndorsement()
+		if endorsement == nil {
+			logger.Warnf("Proposal response from [%s] does not contain an endorsement", r.Endorser)
+			continue
+		}
+
+		if seen[string(endorsement.Endorser)] {
+			continue
+		}
+		seen[string(endorsement.Endorser)] = true
+
+		identity, err := deserializer.DeserializeIdentity(endorsement.Endorser)
+		if err != nil {
+			logger.Warnf("Failed to deserialize the identity of endorser [%s]: %s", r.Endorser, err)
+			continue
+		}
+
+		if err := identity.Validate(); err != nil {
+			logger.Warnf("Identity of endorser [%s] is not valid: %s", r.Endorser, err)
+			continue
+		}
+
+		digest := append(append([]byte{}, r.ProposalResponse.Payload...), endorsement.Endorser...)
+		if err := identity.Verify(digest, endorsement.Signature); err != nil {
+			logger.Warnf("Failed to verify the signature of endorser [%s]: %s", r.Endorser, err)
+			continue
+		}
+
+		identities = append(identities, identity)
+	}
+	return identities
+}
+
+// newLifecyclePolicyProvider returns a provider which queries the endorsement policies of the chaincode definition
+// from one of the endorsers of the transaction
+func newLifecyclePolicyProvider() EndorsementPolicyProvider {
+	lc := resource.NewLifecycle()
+
+	return func(requestContext *RequestContext, clientContext *ClientContext, chaincodeID string) (*EndorsementPolicies, error) {
+		targets, err := endorsingPeers(requestContext, clientContext)
+		if err != nil {
+			return nil, err
+		}
+
+		txh, err := clientContext.Transactor.CreateTransactionHeader()
+		if err != nil {
+			return nil, errors.WithMessage(err, "creating transaction header failed")
+		}
+
+		proposal, err := lc.CreateQueryCommittedProposal(txh, &resource.QueryCommittedChaincodesRequest{Name: chaincodeID})
+		if err != nil {
+			return nil, err
+		}
+
+		responses, err := clientContext.Transactor.SendTransactionProposal(proposal, peer.PeersToTxnProcessors(targets[:1]))
+		if err != nil {
+			if isChaincodeNotDefined(err) {
+				logger.Warnf("Chaincode [%s] is not defined by _lifecycle (e.g. it was deployed with LSCC) - not checking its endorsement policy", chaincodeID)
+				return nil, nil
+			}
+			return nil, errors.WithMessage(err, "querying chaincode definition failed")
+		}
+
+		result := &lb.QueryChaincodeDefinitionResult{}
+		if err := proto.Unmarshal(responses[0].GetResponse().GetPayload(), result); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal chaincode definition")
+		}
+
+		return newEndorsementPolicies(result)
+	}
+}
+
+// isChaincodeNotDefined returns true if the error is the 404 status returned by _lifecycle for a chaincode
+// which it doesn't define
+func isChaincodeNotDefined(err error) bool {
+	s, ok := status.FromError(err)
+	return ok && s.Group == status.ChaincodeStatus && s.Code == int32(common.Status_NOT_FOUND)
+}
+
+// endorsingPeers returns the channel peers that endorsed the transaction
+func endorsingPeers(requestContext *RequestContext, clientContext *ClientContext) ([]fab.Peer, error) {
+	channelPeers, err := clientContext.Discovery.GetPeers()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get channel peers")
+	}
+
+	endorsers := make(map[string]bool)
+	for _, r := range requestContext.Response.Responses {
+		endorsers[r.Endorser] = true
+	}
+
+	var peers []fab.Peer
+	for _, p := range channelPeers {
+		if endorsers[p.URL()] {
+			peers = append(peers, p)
+		}
+	}
+
+	if len(peers) == 0 {
+		return nil, errors.New("none of the endorsers were found in the channel peers")
+	}
+	return peers, nil
+}
+
+func newEndorsementPolicies(result *lb.QueryChaincodeDefinitionResult) (*EndorsementPolicies, error) {
+	policies := &EndorsementPolicies{Collections: make(map[string]*pb.ApplicationPolicy)}
+
+	policies.Chaincode = &pb.ApplicationPolicy{}
+	if err := proto.Unmarshal(result.ValidationParameter, policies.Chaincode); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal application policy")
+	}
+	if policies.Chaincode.Type == nil {
+		return nil, errors.New("application policy of chaincode definition is empty")
+	}
+
+	for _, config := range result.GetCollections().GetConfig() {
+		collConfig := config.GetStaticCollectionConfig()
+		if collConfig == nil || collConfig.GetEndorsementPolicy().GetType() == nil {
+			continue
+		}
+		policies.Collections[collConfig.Name] = collConfig.EndorsementPolicy
+	}
+
+	return policies, nil
+}
+
+//NewEndorsementPolicyHandler returns a handler that checks the endorsements of a transaction against the
+//endorsement policies of the _lifecycle chaincode definitions, which are queried from the endorsers.
+//The endorsement policies of chaincodes that are not defined by _lifecycle are not checked.
+func NewEndorsementPolicyHandler(channelCfg fab.ChannelCfg, next ...Handler) *EndorsementPolicyHandler {
+	return NewEndorsementPolicyHandlerWithProvider(channelCfg, newLifecyclePolicyProvider(), next...)
+}
+
+//NewEndorsementPolicyHandlerWithProvider returns a handler that checks the endorsements of a transaction against
+//the endorsement policies returned by the given provider
+func NewEndorsementPolicyHandlerWithProvider(channelCfg fab.ChannelCfg, provider EndorsementPolicyProvider, next ...Handler) *EndorsementPolicyHandler {
+	return &EndorsementPolicyHandler{
+		channelCfg:     channelCfg,
+		policyProvider: provider,
+		next:           getNext(next),
+		cacheExpiry:    defaultPolicyCacheExpiry,
+		policyCache:    make(map[string]*cachedPolicies),
+	}
+}
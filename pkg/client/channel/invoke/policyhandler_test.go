@@ -0,0 +1,468 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	mb "github.com/hyperledger/fabric-protos-go/msp"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	lb "github.com/hyperledger/fabric-protos-go/peer/lifecycle"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/policydsl"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+)
+
+func TestEndorsementPolicyHandler(t *testing.T) {
+	policies := &EndorsementPolicies{
+		Chaincode:   mustPolicy(t, "AND('Org1MSP.peer','Org2MSP.peer')"),
+		Collections: map[string]*pb.ApplicationPolicy{"coll1": mustPolicy(t, "OR('Org2MSP.peer')")},
+	}
+	publicWrite := &rwsetutil.NsRwSet{NameSpace: "testcc", KvRwSet: &kvrwset.KVRWSet{Writes: []*kvrwset.KVWrite{{Key: "a", Value: []byte("1")}}}}
+
+	t.Run("policy satisfied", func(t *testing.T) {
+		requestContext := newPolicyRequestContext(t, publicWrite, "Org1MSP", "Org2MSP")
+		next := &testHandler{}
+		runPolicyHandler(policies, next, requestContext)
+		require.NoError(t, requestContext.Error)
+		assert.True(t, next.called, "expected next handler to be called")
+	})
+
+	t.Run("missing principal", func(t *testing.T) {
+		requestContext := newPolicyRequestContext(t, publicWrite, "Org1MSP")
+		next := &testHandler{}
+		runPolicyHandler(policies, next, requestContext)
+		require.Error(t, requestContext.Error)
+		assert.False(t, next.called, "expected next handler not to be called")
+
+		s, ok := status.FromError(requestContext.Error)
+		require.True(t, ok)
+		assert.EqualValues(t, status.EndorsementPolicyNotSatisfied, s.Code)
+		assert.Contains(t, s.Message, "chaincode [testcc] requires 1 more of ['Org2MSP.peer']")
+	})
+
+	t.Run("duplicate endorsements", func(t *testing.T) {
+		requestContext := newPolicyRequestContext(t, publicWrite, "Org1MSP", "Org1MSP")
+		runPolicyHandler(&EndorsementPolicies{Chaincode: mustPolicy(t, "OutOf(2,'Org1MSP.peer','Org1MSP.peer')")}, nil, requestContext)
+		require.Error(t, requestContext.Error)
+		assert.Contains(t, requestContext.Error.Error(), "1 more of ['Org1MSP.peer']")
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		requestContext := newPolicyRequestContext(t, publicWrite, "Org1MSP", "Org2MSP")
+		requestContext.Response.Responses[1].Endorsement.Signature = []byte("invalid")
+		runPolicyHandler(policies, nil, requestContext)
+		require.Error(t, requestContext.Error)
+		assert.Contains(t, requestContext.Error.Error(), "'Org2MSP.peer'")
+	})
+
+	t.Run("collection policy", func(t *testing.T) {
+		privateWrite := &rwsetutil.NsRwSet{
+			NameSpace: "testcc",
+			KvRwSet:   &kvrwset.KVRWSet{},
+			CollHashedRwSets: []*rwsetutil.CollHashedRwSet{{
+				CollectionName: "coll1",
+				HashedRwSet:    &kvrwset.HashedRWSet{HashedWrites: []*kvrwset.KVWriteHash{{KeyHash: []byte("k"), ValueHash: []byte("v")}}},
+			}},
+		}
+
+		requestContext := newPolicyRequestContext(t, privateWrite, "Org2MSP")
+		runPolicyHandler(policies, nil, requestContext)
+		require.NoError(t, requestContext.Error, "expected only the collection policy to apply")
+
+		requestContext = newPolicyRequestContext(t, privateWrite, "Org1MSP")
+		runPolicyHandler(policies, nil, requestContext)
+		require.Error(t, requestContext.Error)
+		assert.Contains(t, requestContext.Error.Error(), "collection [testcc/coll1] requires 1 more of ['Org2MSP.peer']")
+	})
+
+	t.Run("read only transaction", func(t *testing.T) {
+		readOnly := &rwsetutil.NsRwSet{NameSpace: "testcc", KvRwSet: &kvrwset.KVRWSet{Reads: []*kvrwset.KVRead{{Key: "a"}}}}
+		requestContext := newPolicyRequestContext(t, readOnly, "Org1MSP")
+		runPolicyHandler(policies, nil, requestContext)
+		require.Error(t, requestContext.Error)
+		assert.Contains(t, requestContext.Error.Error(), "chaincode [testcc]")
+	})
+
+	t.Run("channel config policy", func(t *testing.T) {
+		channelCfg := newPolicyChannelCfg(t)
+		endorsementRef := &pb.ApplicationPolicy{Type: &pb.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: "/Channel/Application/Endorsement"}}
+		refPolicies := &EndorsementPolicies{Chaincode: endorsementRef}
+
+		requestContext := newPolicyRequestContext(t, publicWrite, "Org1MSP", "Org2MSP")
+		runPolicyHandlerWithConfig(channelCfg, refPolicies, requestContext)
+		require.NoError(t, requestContext.Error)
+
+		requestContext = newPolicyRequestContext(t, publicWrite, "Org1MSP")
+		runPolicyHandlerWithConfig(channelCfg, refPolicies, requestContext)
+		require.Error(t, requestContext.Error)
+		assert.Contains(t, requestContext.Error.Error(), "chaincode [testcc] requires channel config policy [/Channel/Application/Endorsement]: policy Endorsement requires MAJORITY Endorsement, satisfied by 1 of 2")
+
+		requestContext = newPolicyRequestContext(t, publicWrite, "Org1MSP")
+		runPolicyHandlerWithConfig(channelCfg, &EndorsementPolicies{Chaincode: &pb.ApplicationPolicy{Type: &pb.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: "Application/Org1MSP/Endorsement"}}}, requestContext)
+		require.NoError(t, requestContext.Error, "expected relative reference to be resolved from the channel group")
+
+		requestContext = newPolicyRequestContext(t, publicWrite, "Org1MSP")
+		runPolicyHandlerWithConfig(channelCfg, &EndorsementPolicies{Chaincode: &pb.ApplicationPolicy{Type: &pb.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: "/Channel/Application/Unknown"}}}, requestContext)
+		require.Error(t, requestContext.Error)
+		assert.Contains(t, requestContext.Error.Error(), "policy Unknown is not defined")
+
+		requestContext = newPolicyRequestContext(t, publicWrite, "Org1MSP")
+		runPolicyHandler(refPolicies, nil, requestContext)
+		require.NoError(t, requestContext.Error, "expected policy not to be checked without the policies of the channel config")
+	})
+
+	t.Run("collection channel config policy", func(t *testing.T) {
+		privateWrite := &rwsetutil.NsRwSet{
+			NameSpace: "testcc",
+			KvRwSet:   &kvrwset.KVRWSet{},
+			CollHashedRwSets: []*rwsetutil.CollHashedRwSet{{
+				CollectionName: "coll1",
+				HashedRwSet:    &kvrwset.HashedRWSet{HashedWrites: []*kvrwset.KVWriteHash{{KeyHash: []byte("k"), ValueHash: []byte("v")}}},
+			}},
+		}
+		collPolicies := &EndorsementPolicies{
+			Chaincode: mustPolicy(t, "OR('Org1MSP.peer')"),
+			Collections: map[string]*pb.ApplicationPolicy{
+				"coll1": {Type: &pb.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: "/Channel/Application/Endorsement"}},
+			},
+		}
+
+		requestContext := newPolicyRequestContext(t, privateWrite, "Org1MSP")
+		runPolicyHandlerWithConfig(newPolicyChannelCfg(t), collPolicies, requestContext)
+		require.Error(t, requestContext.Error, "expected the collection policy to apply instead of the chaincode policy")
+		assert.Contains(t, requestContext.Error.Error(), "collection [testcc/coll1] requires channel config policy")
+
+		requestContext = newPolicyRequestContext(t, privateWrite, "Org1MSP", "Org2MSP")
+		runPolicyHandlerWithConfig(newPolicyChannelCfg(t), collPolicies, requestContext)
+		require.NoError(t, requestContext.Error)
+	})
+
+	t.Run("chaincode not defined", func(t *testing.T) {
+		requestContext := newPolicyRequestContext(t, publicWrite, "Org1MSP")
+		runPolicyHandler(nil, nil, requestContext)
+		require.NoError(t, requestContext.Error)
+	})
+
+	t.Run("policy provider error", func(t *testing.T) {
+		requestContext := newPolicyRequestContext(t, publicWrite, "Org1MSP")
+		handler := NewEndorsementPolicyHandlerWithProvider(nil, func(*RequestContext, *ClientContext, string) (*EndorsementPolicies, error) {
+			return nil, errors.New("provider error")
+		})
+		handler.deserializer = &testDeserializer{}
+		handler.Handle(requestContext, &ClientContext{})
+		require.Error(t, requestContext.Error)
+		assert.Contains(t, requestContext.Error.Error(), "provider error")
+	})
+}
+
+func TestEndorsementPolicyHandlerWithChannelMSPs(t *testing.T) {
+	cs, err := sw.GetSuiteWithDefaultEphemeral()
+	require.NoError(t, err)
+
+	org1 := fcmocks.NewMockOrdererOrg("Org1MSP")
+	org2 := fcmocks.NewMockOrdererOrg("Org2MSP")
+	unknown := fcmocks.NewMockOrdererOrg("Org2MSP")
+
+	channelCfg := fcmocks.NewMockChannelCfg("mychannel")
+	channelCfg.MockMSPs = []*mb.MSPConfig{org1.MSPConfig(), org2.MSPConfig()}
+
+	policies := &EndorsementPolicies{Chaincode: mustPolicy(t, "AND('Org1MSP.member','Org2MSP.member')")}
+	publicWrite := &rwsetutil.NsRwSet{NameSpace: "testcc", KvRwSet: &kvrwset.KVRWSet{Writes: []*kvrwset.KVWrite{{Key: "a", Value: []byte("1")}}}}
+	newHandler := func() *EndorsementPolicyHandler {
+		return NewEndorsementPolicyHandlerWithProvider(channelCfg, func(*RequestContext, *ClientContext, string) (*EndorsementPolicies, error) {
+			return policies, nil
+		})
+	}
+
+	requestContext := newSignedPolicyRequestContext(t, publicWrite, org1, org2)
+	newHandler().Handle(requestContext, &ClientContext{CryptoSuite: cs})
+	require.NoError(t, requestContext.Error)
+
+	requestContext = newSignedPolicyRequestContext(t, publicWrite, org1, unknown)
+	newHandler().Handle(requestContext, &ClientContext{CryptoSuite: cs})
+	require.Error(t, requestContext.Error, "expected endorsement by an identity which is not issued by the MSP to be ignored")
+	assert.Contains(t, requestContext.Error.Error(), "1 more of ['Org2MSP.member']")
+
+	requestContext = newSignedPolicyRequestContext(t, publicWrite, org1, org2)
+	newHandler().Handle(requestContext, &ClientContext{})
+	require.Error(t, requestContext.Error, "expected error without crypto suite")
+	assert.Contains(t, requestContext.Error.Error(), "crypto suite is required")
+}
+
+func TestEndorsementPolicyCache(t *testing.T) {
+	publicWrite := &rwsetutil.NsRwSet{NameSpace: "testcc", KvRwSet: &kvrwset.KVRWSet{Writes: []*kvrwset.KVWrite{{Key: "a", Value: []byte("1")}}}}
+
+	queries := 0
+	policy := mustPolicy(t, "OR('Org1MSP.peer')")
+	handler := NewEndorsementPolicyHandlerWithProvider(nil, func(*RequestContext, *ClientContext, string) (*EndorsementPolicies, error) {
+		queries++
+		return &EndorsementPolicies{Chaincode: policy}, nil
+	})
+	handler.deserializer = &testDeserializer{}
+
+	requestContext := newPolicyRequestContext(t, publicWrite, "Org1MSP")
+	handler.Handle(requestContext, &ClientContext{})
+	require.NoError(t, requestContext.Error)
+	handler.Handle(requestContext, &ClientContext{})
+	require.NoError(t, requestContext.Error)
+	assert.Equal(t, 1, queries, "expected the policies to be cached")
+
+	// the chaincode definition is updated, so the cached policy is no longer satisfied
+	policy = mustPolicy(t, "OR('Org2MSP.peer')")
+	requestContext = newPolicyRequestContext(t, publicWrite, "Org2MSP")
+	handler.Handle(requestContext, &ClientContext{})
+	require.NoError(t, requestContext.Error)
+	assert.Equal(t, 2, queries, "expected the policies to be queried again after the check failed")
+
+	handler.cacheExpiry = 0
+	requestContext = newPolicyRequestContext(t, publicWrite, "Org1MSP")
+	handler.Handle(requestContext, &ClientContext{})
+	require.Error(t, requestContext.Error)
+	assert.Equal(t, 3, queries)
+
+	requestContext = newPolicyRequestContext(t, publicWrite, "Org2MSP")
+	handler.Handle(requestContext, &ClientContext{})
+	require.NoError(t, requestContext.Error)
+	assert.Equal(t, 4, queries, "expected the policies to be queried again after they expired")
+}
+
+func TestLifecyclePolicyProvider(t *testing.T) {
+	endorser := fcmocks.NewMockPeer("Peer1", "org1msp.peer:7051")
+	requestContext := &RequestContext{}
+	requestContext.Response.Responses = []*fab.TransactionProposalResponse{{Endorser: endorser.URL()}}
+
+	transactor := &policyTestTransactor{MockTransactor: &fcmocks.MockTransactor{}, err: status.New(status.ChaincodeStatus, 404, "not defined", nil)}
+	clientContext := &ClientContext{Discovery: fcmocks.NewMockDiscoveryService(nil, endorser), Transactor: transactor}
+
+	provider := newLifecyclePolicyProvider()
+	policies, err := provider(requestContext, clientContext, "testcc")
+	require.NoError(t, err, "expected chaincode which is not defined by _lifecycle to be skipped")
+	assert.Nil(t, policies)
+
+	transactor.err = status.New(status.ChaincodeStatus, 500, "namespace testcc is not defined", nil)
+	_, err = provider(requestContext, clientContext, "testcc")
+	require.Error(t, err, "expected error for a status other than not found")
+	assert.Contains(t, err.Error(), "querying chaincode definition failed")
+
+	transactor.err = status.New(status.GRPCTransportStatus, 14, "connection refused", nil)
+	_, err = provider(requestContext, clientContext, "testcc")
+	require.Error(t, err, "expected error for transport error")
+	assert.Contains(t, err.Error(), "connection refused")
+}
+
+func TestNewEndorsementPolicies(t *testing.T) {
+	lc := resource.NewLifecycle()
+	ccPolicy := mustSignaturePolicy(t, "AND('Org1MSP.peer','Org2MSP.peer')")
+	collPolicy := mustSignaturePolicy(t, "OR('Org1MSP.member')")
+
+	validationParameter, err := lc.MarshalApplicationPolicy(ccPolicy, "")
+	require.NoError(t, err)
+
+	result := &lb.QueryChaincodeDefinitionResult{
+		ValidationParameter: validationParameter,
+		Collections: &pb.CollectionConfigPackage{Config: []*pb.CollectionConfig{
+			{Payload: &pb.CollectionConfig_StaticCollectionConfig{StaticCollectionConfig: &pb.StaticCollectionConfig{
+				Name:              "coll1",
+				EndorsementPolicy: &pb.ApplicationPolicy{Type: &pb.ApplicationPolicy_SignaturePolicy{SignaturePolicy: collPolicy}},
+			}}},
+			{Payload: &pb.CollectionConfig_StaticCollectionConfig{StaticCollectionConfig: &pb.StaticCollectionConfig{
+				Name:              "coll2",
+				EndorsementPolicy: &pb.ApplicationPolicy{Type: &pb.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: "/Channel/Application/Endorsement"}},
+			}}},
+			{Payload: &pb.CollectionConfig_StaticCollectionConfig{StaticCollectionConfig: &pb.StaticCollectionConfig{Name: "coll3"}}},
+		}},
+	}
+
+	policies, err := newEndorsementPolicies(result)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(ccPolicy, policies.Chaincode.GetSignaturePolicy()))
+	require.Len(t, policies.Collections, 2)
+	assert.True(t, proto.Equal(collPolicy, policies.Collections["coll1"].GetSignaturePolicy()))
+	assert.Equal(t, "/Channel/Application/Endorsement", policies.Collections["coll2"].GetChannelConfigPolicyReference())
+
+	validationParameter, err = lc.MarshalApplicationPolicy(nil, "/Channel/Application/Endorsement")
+	require.NoError(t, err)
+	policies, err = newEndorsementPolicies(&lb.QueryChaincodeDefinitionResult{ValidationParameter: validationParameter})
+	require.NoError(t, err)
+	assert.Equal(t, "/Channel/Application/Endorsement", policies.Chaincode.GetChannelConfigPolicyReference())
+}
+
+func runPolicyHandler(policies *EndorsementPolicies, next Handler, requestContext *RequestContext) {
+	runPolicyHandlerWithConfig(nil, policies, requestContext, next)
+}
+
+func runPolicyHandlerWithConfig(channelCfg fab.ChannelCfg, policies *EndorsementPolicies, requestContext *RequestContext, next ...Handler) {
+	var nextHandlers []Handler
+	for _, h := range next {
+		if h != nil {
+			nextHandlers = append(nextHandlers, h)
+		}
+	}
+
+	handler := NewEndorsementPolicyHandlerWithProvider(channelCfg, func(*RequestContext, *ClientContext, string) (*EndorsementPolicies, error) {
+		return policies, nil
+	}, nextHandlers...)
+	handler.deserializer = &testDeserializer{}
+	handler.Handle(requestContext, &ClientContext{})
+}
+
+func newPolicyRequestContext(t *testing.T, nsRWSet *rwsetutil.NsRwSet, mspIDs ...string) *RequestContext {
+	payload := newPolicyResponsePayload(t, nsRWSet)
+
+	requestContext := &RequestContext{Request: Request{ChaincodeID: "testcc"}}
+	for _, mspID := range mspIDs {
+		endorser := []byte(mspID)
+		requestContext.Response.Responses = append(requestContext.Response.Responses, newPolicyResponse(mspID, payload, endorser, testSignature(payload, endorser)))
+	}
+	return requestContext
+}
+
+// newSignedPolicyRequestContext returns a request context with endorsements signed by the identities of the organizations
+func newSignedPolicyRequestContext(t *testing.T, nsRWSet *rwsetutil.NsRwSet, orgs ...*fcmocks.MockOrdererOrg) *RequestContext {
+	payload := newPolicyResponsePayload(t, nsRWSet)
+
+	requestContext := &RequestContext{Request: Request{ChaincodeID: "testcc"}}
+	for _, org := range orgs {
+		endorser := org.SerializedIdentity()
+		signature := org.Sign(append(append([]byte{}, payload...), endorser...))
+		requestContext.Response.Responses = append(requestContext.Response.Responses, newPolicyResponse(org.MSPID, payload, endorser, signature))
+	}
+	return requestContext
+}
+
+func newPolicyResponsePayload(t *testing.T, nsRWSet *rwsetutil.NsRwSet) []byte {
+	txRWSet := &rwsetutil.TxRwSet{NsRwSets: []*rwsetutil.NsRwSet{nsRWSet}}
+	results, err := txRWSet.ToProtoBytes()
+	require.NoError(t, err)
+
+	extension, err := proto.Marshal(&pb.ChaincodeAction{Results: results, Response: &pb.Response{Status: 200}})
+	require.NoError(t, err)
+
+	payload, err := proto.Marshal(&pb.ProposalResponsePayload{Extension: extension})
+	require.NoError(t, err)
+	return payload
+}
+
+func newPolicyResponse(mspID string, payload, endorser, signature []byte) *fab.TransactionProposalResponse {
+	return &fab.TransactionProposalResponse{
+		Endorser: strings.ToLower(mspID) + ".peer:7051",
+		ProposalResponse: &pb.ProposalResponse{
+			Response:    &pb.Response{Status: 200},
+			Payload:     payload,
+			Endorsement: &pb.Endorsement{Endorser: endorser, Signature: signature},
+		},
+	}
+}
+
+func mustPolicy(t *testing.T, policy string) *pb.ApplicationPolicy {
+	return &pb.ApplicationPolicy{Type: &pb.ApplicationPolicy_SignaturePolicy{SignaturePolicy: mustSignaturePolicy(t, policy)}}
+}
+
+func mustSignaturePolicy(t *testing.T, policy string) *common.SignaturePolicyEnvelope {
+	envelope, err := policydsl.FromString(policy)
+	require.NoError(t, err)
+	return envelope
+}
+
+// newPolicyChannelCfg returns a channel config whose application group requires the endorsement of a majority
+// of the two application organizations
+func newPolicyChannelCfg(t *testing.T) fab.ChannelCfg {
+	newPolicy := func(policyType common.Policy_PolicyType, value proto.Message) *common.ConfigPolicy {
+		valueBytes, err := proto.Marshal(value)
+		require.NoError(t, err)
+		return &common.ConfigPolicy{Policy: &common.Policy{Type: int32(policyType), Value: valueBytes}}
+	}
+	newOrgGroup := func(mspID string) *common.ConfigGroup {
+		return &common.ConfigGroup{Policies: map[string]*common.ConfigPolicy{
+			"Endorsement": newPolicy(common.Policy_SIGNATURE, mustSignaturePolicy(t, fmt.Sprintf("OR('%s.peer')", mspID))),
+		}}
+	}
+
+	channelCfg := fcmocks.NewMockChannelCfg("mychannel")
+	channelCfg.MockVersions = &fab.Versions{Channel: &common.ConfigGroup{Groups: map[string]*common.ConfigGroup{
+		"Application": {
+			Groups: map[string]*common.ConfigGroup{"Org1MSP": newOrgGroup("Org1MSP"), "Org2MSP": newOrgGroup("Org2MSP")},
+			Policies: map[string]*common.ConfigPolicy{
+				"Endorsement": newPolicy(common.Policy_IMPLICIT_META, &common.ImplicitMetaPolicy{Rule: common.ImplicitMetaPolicy_MAJORITY, SubPolicy: "Endorsement"}),
+			},
+		},
+	}}}
+	return channelCfg
+}
+
+func testSignature(payload, endorser []byte) []byte {
+	return append(append([]byte("signed:"), payload...), endorser...)
+}
+
+// policyTestTransactor returns the given error for the query of a chaincode definition
+type policyTestTransactor struct {
+	*fcmocks.MockTransactor
+	err error
+}
+
+func (t *policyTestTransactor) SendTransactionProposal(proposal *fab.TransactionProposal, targets []fab.ProposalProcessor) ([]*fab.TransactionProposalResponse, error) {
+	return nil, t.err
+}
+
+type testHandler struct {
+	called bool
+}
+
+func (h *testHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	h.called = true
+}
+
+// testDeserializer deserializes identities which are serialized as their MSP ID
+type testDeserializer struct{}
+
+func (d *testDeserializer) DeserializeIdentity(serializedIdentity []byte) (msp.Identity, error) {
+	return &testIdentity{MockIdentity: &fcmocks.MockIdentity{}, mspID: string(serializedIdentity)}, nil
+}
+
+func (d *testDeserializer) IsWellFormed(identity *mb.SerializedIdentity) error {
+	return nil
+}
+
+// testIdentity satisfies the principals of its MSP and verifies signatures created by testSignature
+type testIdentity struct {
+	*fcmocks.MockIdentity
+	mspID string
+}
+
+func (id *testIdentity) SatisfiesPrincipal(principal *mb.MSPPrincipal) error {
+	role := &mb.MSPRole{}
+	if err := proto.Unmarshal(principal.Principal, role); err != nil {
+		return err
+	}
+	if role.MspIdentifier != id.mspID {
+		return errors.Errorf("identity of [%s] does not satisfy principal of [%s]", id.mspID, role.MspIdentifier)
+	}
+	return nil
+}
+
+func (id *testIdentity) Verify(msg []byte, sig []byte) error {
+	if !bytes.Equal(append([]byte("signed:"), msg...), sig) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
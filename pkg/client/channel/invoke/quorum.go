@@ -0,0 +1,247 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
+	"github.com/pkg/errors"
+)
+
+//QuorumTargetsHandler selects the peers a quorum query is sent to
+type QuorumTargetsHandler struct {
+	next Handler
+}
+
+//Handle selects Quorum.Targets peers, spread across as many organizations as possible
+func (h *QuorumTargetsHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	quorum := requestContext.Opts.Quorum
+
+	peers := requestContext.Opts.Targets
+	if len(peers) == 0 {
+		_, span := tracing.StartSpan(requestContext.Ctx, "discovery.GetPeers")
+		discovered, err := clientContext.Discovery.GetPeers()
+		tracing.EndSpan(span, err)
+		if err != nil {
+			requestContext.Error = errors.WithMessage(err, "Failed to get peers")
+			return
+		}
+
+		for _, p := range discovered {
+			if requestContext.SelectionFilter == nil || requestContext.SelectionFilter(p) {
+				peers = append(peers, p)
+			}
+		}
+	}
+
+	if len(peers) < quorum.Matches {
+		requestContext.Error = status.New(status.ClientStatus, status.NoPeersFound.ToInt32(),
+			fmt.Sprintf("%d peers available, %d matching results are required", len(peers), quorum.Matches), nil)
+		return
+	}
+
+	if requestContext.PeerSorter != nil {
+		peers = requestContext.PeerSorter(peers)
+	} else {
+		peers = shufflePeers(peers)
+	}
+	requestContext.Opts.Targets = pickAcrossOrgs(peers, quorum.Targets)
+
+	//Delegate to next step if any
+	if h.next != nil {
+		h.next.Handle(requestContext, clientContext)
+	}
+}
+
+//QuorumEndorsementHandler sends the query proposal to the targets and requires Quorum.Matches matching responses
+type QuorumEndorsementHandler struct {
+	next Handler
+}
+
+//Handle endorses the query proposal. Unlike EndorsementHandler, it tolerates failures of individual peers as
+//long as enough of the others return matching results. Responses with invalid signatures are dropped and
+//count as failures of their peers.
+func (h *QuorumEndorsementHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	quorum := requestContext.Opts.Quorum
+
+	if len(requestContext.Opts.Targets) == 0 {
+		requestContext.Error = status.New(status.ClientStatus, status.NoPeersFound.ToInt32(), "targets were not provided", nil)
+		return
+	}
+
+	responses, proposal, err := createAndSendTransactionProposal(
+		clientContext.Transactor,
+		&requestContext.Request,
+		peer.PeersToTxnProcessors(requestContext.Opts.Targets),
+	)
+	if proposal != nil {
+		requestContext.Response.Proposal = proposal
+		requestContext.Response.TransactionID = proposal.TxnID
+	}
+
+	_, span := tracing.StartSpan(requestContext.Ctx, "invoke.ValidateSignatures", tracing.String(tracing.TxIDKey, string(requestContext.Response.TransactionID)))
+	responses, err = validSignatures(responses, err, clientContext)
+	tracing.EndSpan(span, nil)
+
+	if len(responses) < quorum.Matches {
+		if err == nil {
+			err = errors.New("no error returned by the peers")
+		}
+		requestContext.Error = errors.WithMessagef(checkEndorserServerError(err),
+			"%d of %d peers returned query results, %d matching results are required", len(responses), len(requestContext.Opts.Targets), quorum.Matches)
+		return
+	}
+	if err != nil {
+		logger.Debugf("Some peers failed to return query results: %s", err)
+	}
+
+	groups := groupMatchingResponses(responses)
+	if len(groups[0]) < quorum.Matches {
+		// the largest group comes first, so that differences are reported against the most common result
+		var divergent []*fab.TransactionProposalResponse
+		for _, group := range groups {
+			divergent = append(divergent, group...)
+		}
+		requestContext.Response.Responses = divergent
+		requestContext.Error = txn.NewEndorsementMismatchStatus(
+			"query results do not match across peers", divergent)
+		return
+	}
+
+	matching := groups[0]
+	payload, err := getResultFromProposalResponse(matching[0].ProposalResponse)
+	if err != nil {
+		requestContext.Error = err
+		return
+	}
+	requestContext.Response.Responses = matching
+	requestContext.Response.Payload = payload
+	requestContext.Response.ChaincodeStatus = matching[0].ChaincodeStatus
+
+	//Delegate to next step if any
+	if h.next != nil {
+		h.next.Handle(requestContext, clientContext)
+	}
+}
+
+// validSignatures returns the responses whose signatures are valid. The signature validation errors of the
+// other responses are appended to the given errors of the peers that failed.
+func validSignatures(responses []*fab.TransactionProposalResponse, errs error, clientContext *ClientContext) ([]*fab.TransactionProposalResponse, error) {
+	var valid []*fab.TransactionProposalResponse
+	for _, r := range responses {
+		if err := verifyProposalResponse(r, clientContext); err != nil {
+			logger.Debugf("Dropping query result of [%s] with invalid signature: %s", r.Endorser, err)
+			errs = multi.Append(errs, errors.WithMessagef(err, "signature validation failed for [%s]", r.Endorser))
+			continue
+		}
+		valid = append(valid, r)
+	}
+	return valid, errs
+}
+
+// groupMatchingResponses groups the responses whose proposal response payloads and chaincode results match,
+// largest group first
+func groupMatchingResponses(responses []*fab.TransactionProposalResponse) [][]*fab.TransactionProposalResponse {
+	var groups [][]*fab.TransactionProposalResponse
+	for _, r := range responses {
+		matched := false
+		for i, group := range groups {
+			if bytes.Equal(group[0].GetPayload(), r.GetPayload()) &&
+				bytes.Equal(group[0].GetResponse().GetPayload(), r.GetResponse().GetPayload()) {
+				groups[i] = append(group, r)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			groups = append(groups, []*fab.TransactionProposalResponse{r})
+		}
+	}
+
+	// stable insertion sort keeps the order of the responses within groups of the same size
+	for i := 1; i < len(groups); i++ {
+		for j := i; j > 0 && len(groups[j]) > len(groups[j-1]); j-- {
+			groups[j], groups[j-1] = groups[j-1], groups[j]
+		}
+	}
+	return groups
+}
+
+// pickAcrossOrgs picks n peers, taking one peer of each organization in turn so that the peers are spread
+// across as many organizations as possible. The order of the peers within an organization is preserved.
+func pickAcrossOrgs(peers []fab.Peer, n int) []fab.Peer {
+	var orgs []string
+	peersByOrg := make(map[string][]fab.Peer)
+	for _, p := range peers {
+		if _, ok := peersByOrg[p.MSPID()]; !ok {
+			orgs = append(orgs, p.MSPID())
+		}
+		peersByOrg[p.MSPID()] = append(peersByOrg[p.MSPID()], p)
+	}
+
+	var picked []fab.Peer
+	for len(picked) < n && len(picked) < len(peers) {
+		for _, org := range orgs {
+			if len(picked) == n {
+				break
+			}
+			if orgPeers := peersByOrg[org]; len(orgPeers) > 0 {
+				picked = append(picked, orgPeers[0])
+				peersByOrg[org] = orgPeers[1:]
+			}
+		}
+	}
+	return picked
+}
+
+func shufflePeers(peers []fab.Peer) []fab.Peer {
+	shuffled := make([]fab.Peer, len(peers))
+	for i, j := range rand.Perm(len(peers)) {
+		shuffled[j] = peers[i]
+	}
+	return shuffled
+}
+
+//NewQuorumQueryHandler returns query handler with chain of QuorumTargetsHandler and QuorumEndorsementHandler,
+//which validates the signatures of the responses before matching them
+func NewQuorumQueryHandler(next ...Handler) Handler {
+	return NewQuorumTargetsHandler(
+		NewQuorumEndorsementHandler(next...),
+	)
+}
+
+//NewQuorumTargetsHandler returns a handler that selects the peers of a quorum query
+func NewQuorumTargetsHandler(next ...Handler) *QuorumTargetsHandler {
+	return &QuorumTargetsHandler{next: getNext(next)}
+}
+
+//NewQuorumEndorsementHandler returns a handler that requires matching query results from a quorum of peers
+func NewQuorumEndorsementHandler(next ...Handler) *QuorumEndorsementHandler {
+	return &QuorumEndorsementHandler{next: getNext(next)}
+}
+
+// queryHandler runs the quorum query chain if a quorum is requested, or the standard query chain otherwise
+type queryHandler struct {
+	standard Handler
+	quorum   Handler
+}
+
+func (h *queryHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	if requestContext.Opts.Quorum.Matches > 0 {
+		h.quorum.Handle(requestContext, clientContext)
+		return
+	}
+	h.standard.Handle(requestContext, clientContext)
+}
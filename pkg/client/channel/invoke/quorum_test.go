@@ -0,0 +1,155 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
+)
+
+func TestQuorumQueryHandler(t *testing.T) {
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+
+	newPeers := func() []*fcmocks.MockPeer {
+		return []*fcmocks.MockPeer{
+			{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "Org1MSP", Status: 200, Payload: []byte("value")},
+			{MockName: "Peer2", MockURL: "http://peer2.com", MockMSP: "Org2MSP", Status: 200, Payload: []byte("value")},
+			{MockName: "Peer3", MockURL: "http://peer3.com", MockMSP: "Org3MSP", Status: 200, Payload: []byte("stale")},
+		}
+	}
+	handle := func(quorum QuorumOpts, peers []*fcmocks.MockPeer) *RequestContext {
+		var discovered []fab.Peer
+		for _, p := range peers {
+			discovered = append(discovered, p)
+		}
+		clientContext := setupChannelClientContext(nil, errors.New("selection is not used by quorum queries"), nil, t)
+		clientContext.Discovery = txnmocks.NewMockDiscoveryService(nil, discovered...)
+
+		requestContext := prepareRequestContext(request, Opts{Quorum: quorum}, t)
+		NewQueryHandler().Handle(requestContext, clientContext)
+		return requestContext
+	}
+
+	t.Run("matching results", func(t *testing.T) {
+		requestContext := handle(QuorumOpts{Targets: 3, Matches: 2}, newPeers())
+		require.NoError(t, requestContext.Error)
+		assert.Equal(t, []byte("value"), requestContext.Response.Payload)
+		assert.Len(t, requestContext.Response.Responses, 2, "expected only the matching responses")
+	})
+
+	t.Run("divergent results", func(t *testing.T) {
+		requestContext := handle(QuorumOpts{Targets: 3, Matches: 3}, newPeers())
+		require.Error(t, requestContext.Error)
+
+		mismatch, ok := txn.EndorsementMismatchFromError(requestContext.Error)
+		require.True(t, ok, "expected endorsement mismatch diagnostics")
+		require.Len(t, mismatch.Endorsements, 3)
+		assert.Equal(t, []byte("stale"), mismatch.Endorsements[2].Payload, "expected divergent result to be reported last")
+		assert.Len(t, requestContext.Response.Responses, 3, "expected all the responses to be returned")
+	})
+
+	t.Run("peer failure", func(t *testing.T) {
+		peers := newPeers()
+		peers[1].Error = errors.New("peer unavailable")
+		peers[2].Payload = []byte("value")
+
+		requestContext := handle(QuorumOpts{Targets: 3, Matches: 2}, peers)
+		require.NoError(t, requestContext.Error)
+		assert.Len(t, requestContext.Response.Responses, 2)
+
+		peers[2].Payload = []byte("stale")
+		requestContext = handle(QuorumOpts{Targets: 3, Matches: 2}, peers)
+		_, ok := txn.EndorsementMismatchFromError(requestContext.Error)
+		assert.True(t, ok, "expected endorsement mismatch")
+
+		peers[2].Error = errors.New("peer unavailable")
+		requestContext = handle(QuorumOpts{Targets: 3, Matches: 2}, peers)
+		require.Error(t, requestContext.Error)
+		assert.Contains(t, requestContext.Error.Error(), "1 of 3 peers returned query results")
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		peers := newPeers()
+		peers[0].Payload = []byte("forged")
+		peers[0].Endorser = []byte("forger")
+		peers[1].Payload = []byte("forged")
+		peers[1].Endorser = []byte("forger")
+		peers[2].Payload = []byte("value")
+
+		membership := &forgerMembership{MockMembership: fcmocks.NewMockMembership()}
+		handleWithMembership := func(quorum QuorumOpts) *RequestContext {
+			var discovered []fab.Peer
+			for _, p := range peers {
+				discovered = append(discovered, p)
+			}
+			clientContext := setupChannelClientContext(nil, errors.New("selection is not used by quorum queries"), nil, t)
+			clientContext.Discovery = txnmocks.NewMockDiscoveryService(nil, discovered...)
+			clientContext.Membership = membership
+
+			requestContext := prepareRequestContext(request, Opts{Quorum: quorum}, t)
+			NewQueryHandler().Handle(requestContext, clientContext)
+			return requestContext
+		}
+
+		requestContext := handleWithMembership(QuorumOpts{Targets: 3, Matches: 1})
+		require.NoError(t, requestContext.Error)
+		assert.Equal(t, []byte("value"), requestContext.Response.Payload, "expected forged results to be dropped before matching")
+		assert.Len(t, requestContext.Response.Responses, 1)
+
+		requestContext = handleWithMembership(QuorumOpts{Targets: 3, Matches: 2})
+		require.Error(t, requestContext.Error)
+		assert.Contains(t, requestContext.Error.Error(), "1 of 3 peers returned query results")
+		assert.Contains(t, requestContext.Error.Error(), "signature validation failed")
+	})
+
+	t.Run("not enough peers", func(t *testing.T) {
+		requestContext := handle(QuorumOpts{Targets: 5, Matches: 4}, newPeers())
+		s, ok := status.FromError(requestContext.Error)
+		require.True(t, ok, "expected status error")
+		assert.EqualValues(t, status.NoPeersFound, s.Code)
+	})
+}
+
+// forgerMembership fails to verify the signatures of the "forger" endorser
+type forgerMembership struct {
+	*fcmocks.MockMembership
+}
+
+func (m *forgerMembership) Verify(serializedID []byte, msg []byte, sig []byte) error {
+	if string(serializedID) == "forger" {
+		return errors.New("invalid signature")
+	}
+	return m.MockMembership.Verify(serializedID, msg, sig)
+}
+
+func TestPickAcrossOrgs(t *testing.T) {
+	org1Peer1 := fcmocks.NewMockPeer("org1peer1", "peer1.org1.com")
+	org1Peer1.MockMSP = "Org1MSP"
+	org1Peer2 := fcmocks.NewMockPeer("org1peer2", "peer2.org1.com")
+	org1Peer2.MockMSP = "Org1MSP"
+	org1Peer3 := fcmocks.NewMockPeer("org1peer3", "peer3.org1.com")
+	org1Peer3.MockMSP = "Org1MSP"
+	org2Peer1 := fcmocks.NewMockPeer("org2peer1", "peer1.org2.com")
+	org2Peer1.MockMSP = "Org2MSP"
+	org3Peer1 := fcmocks.NewMockPeer("org3peer1", "peer1.org3.com")
+	org3Peer1.MockMSP = "Org3MSP"
+
+	peers := []fab.Peer{org1Peer1, org1Peer2, org1Peer3, org2Peer1, org3Peer1}
+
+	assert.Equal(t, []fab.Peer{org1Peer1, org2Peer1, org3Peer1}, pickAcrossOrgs(peers, 3))
+	assert.Equal(t, []fab.Peer{org1Peer1, org2Peer1, org3Peer1, org1Peer2}, pickAcrossOrgs(peers, 4))
+	assert.Len(t, pickAcrossOrgs(peers, 10), 5)
+}
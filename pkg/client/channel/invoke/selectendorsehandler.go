@@ -11,6 +11,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
 	"github.com/pkg/errors"
 
@@ -49,7 +50,9 @@ func (e *SelectAndEndorseHandler) Handle(requestContext *RequestContext, clientC
 	targets := requestContext.Opts.Targets
 	if len(targets) == 0 {
 		var err error
+		_, span := tracing.StartSpan(requestContext.Ctx, "selection.GetEndorsers", tracing.String(tracing.ChaincodeKey, requestContext.Request.ChaincodeID))
 		ccCalls, requestContext.Opts.Targets, err = getEndorsers(requestContext, clientContext)
+		tracing.EndSpan(span, err)
 		if err != nil {
 			requestContext.Error = err
 			return
@@ -9,6 +9,7 @@ package invoke
 import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 
 	"github.com/pkg/errors"
 )
@@ -26,7 +27,9 @@ type SignatureValidationHandler struct {
 //Handle for Filtering proposal response
 func (f *SignatureValidationHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
 	//Filter tx proposal responses
+	_, span := tracing.StartSpan(requestContext.Ctx, "invoke.ValidateSignatures", tracing.String(tracing.TxIDKey, string(requestContext.Response.TransactionID)))
 	err := f.validate(requestContext.Response.Responses, clientContext)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		requestContext.Error = errors.WithMessage(err, "signature validation failed")
 		return
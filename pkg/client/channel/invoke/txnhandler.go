@@ -19,6 +19,7 @@ import (
 	pb "github.com/hyperledger/fabric-protos-go/peer"
 	selectopts "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
 )
@@ -46,6 +47,9 @@ func (e *EndorsementHandler) Handle(requestContext *RequestContext, clientContex
 	if e.headerOptsProvider != nil {
 		TxnHeaderOpts = e.headerOptsProvider()
 	}
+	if requestContext.Opts.Nonce != nil {
+		TxnHeaderOpts = append(TxnHeaderOpts, fab.WithNonce(requestContext.Opts.Nonce))
+	}
 
 	transactionProposalResponses, proposal, err := createAndSendTransactionProposal(
 		clientContext.Transactor,
@@ -115,7 +119,9 @@ func (h *ProposalProcessorHandler) Handle(requestContext *RequestContext, client
 			selectionOpts = append(selectionOpts, selectopts.WithPeerSorter(requestContext.PeerSorter))
 		}
 
+		_, span := tracing.StartSpan(requestContext.Ctx, "selection.GetEndorsers", tracing.String(tracing.ChaincodeKey, requestContext.Request.ChaincodeID))
 		endorsers, err := clientContext.Selection.GetEndorsersForChaincode(newInvocationChain(requestContext), selectionOpts...)
+		tracing.EndSpan(span, err)
 		if err != nil {
 			requestContext.Error = errors.WithMessage(err, "Failed to get endorsing peers")
 			return
@@ -176,8 +182,7 @@ func (f *EndorsementValidationHandler) validate(txProposalResponse []*fab.Transa
 
 		if !bytes.Equal(a1.Payload, r.ProposalResponse.Payload) ||
 			!bytes.Equal(a1.GetResponse().Payload, response.Payload) {
-			return status.New(status.EndorserClientStatus, status.EndorsementMismatch.ToInt32(),
-				"ProposalResponsePayloads do not match", nil)
+			return txn.NewEndorsementMismatchStatus("ProposalResponsePayloads do not match", txProposalResponse)
 		}
 	}
 
@@ -207,20 +212,25 @@ func (c *CommitTxHandler) Handle(requestContext *RequestContext, clientContext *
 		return
 	}
 
+	_, span := tracing.StartSpan(requestContext.Ctx, "invoke.WaitForCommit", tracing.String(tracing.TxIDKey, string(txnID)))
 	select {
 	case txStatus := <-statusNotifier:
 		requestContext.Response.TxValidationCode = txStatus.TxValidationCode
+		span.SetAttributes(tracing.String(tracing.TxValidationCodeKey, txStatus.TxValidationCode.String()))
 
 		if txStatus.TxValidationCode != pb.TxValidationCode_VALID {
 			requestContext.Error = status.New(status.EventServerStatus, int32(txStatus.TxValidationCode),
 				"received invalid transaction", nil)
+			tracing.EndSpan(span, requestContext.Error)
 			return
 		}
 	case <-requestContext.Ctx.Done():
 		requestContext.Error = status.New(status.ClientStatus, status.Timeout.ToInt32(),
 			"Execute didn't receive block event", nil)
+		tracing.EndSpan(span, requestContext.Error)
 		return
 	}
+	span.End()
 
 	//Delegate to next step if any
 	if c.next != nil {
@@ -228,15 +238,19 @@ func (c *CommitTxHandler) Handle(requestContext *RequestContext, clientContext *
 	}
 }
 
-//NewQueryHandler returns query handler with chain of ProposalProcessorHandler, EndorsementHandler, EndorsementValidationHandler and SignatureValidationHandler
+//NewQueryHandler returns query handler with chain of ProposalProcessorHandler, EndorsementHandler, EndorsementValidationHandler and SignatureValidationHandler,
+//or with the chain of NewQuorumQueryHandler if a quorum is requested
 func NewQueryHandler(next ...Handler) Handler {
-	return NewProposalProcessorHandler(
-		NewEndorsementHandler(
-			NewEndorsementValidationHandler(
-				NewSignatureValidationHandler(next...),
+	return &queryHandler{
+		standard: NewProposalProcessorHandler(
+			NewEndorsementHandler(
+				NewEndorsementValidationHandler(
+					NewSignatureValidationHandler(next...),
+				),
 			),
 		),
-	)
+		quorum: NewQuorumQueryHandler(next...),
+	}
 }
 
 //NewExecuteHandler returns execute handler with chain of SelectAndEndorseHandler, EndorsementValidationHandler, SignatureValidationHandler and CommitHandler
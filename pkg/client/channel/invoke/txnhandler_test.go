@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
 )
 
@@ -202,6 +203,21 @@ func TestEndorsementHandler(t *testing.T) {
 		require.True(t, optsProviderCalled, "expecting opts provider to be called")
 	})
 
+	t.Run("pins the nonce", func(t *testing.T) {
+		clientContext := setupChannelClientContext(nil, nil, nil, t)
+		opts := Opts{Targets: []fab.Peer{fcmocks.NewMockPeer("p2", "")}, Nonce: []byte("somenonce")}
+
+		requestContext := prepareRequestContext(request, opts, t)
+		NewEndorsementHandler().Handle(requestContext, clientContext)
+		require.NoError(t, requestContext.Error)
+		txID := requestContext.Response.TransactionID
+
+		requestContext = prepareRequestContext(request, opts, t)
+		NewEndorsementHandler().Handle(requestContext, clientContext)
+		require.NoError(t, requestContext.Error)
+		require.Equal(t, txID, requestContext.Response.TransactionID, "expecting the same transaction ID for the same nonce")
+	})
+
 	t.Run("returns EndorserServerStatus error from Transactor", func(t *testing.T) {
 		clientContext := setupChannelClientContext(nil, nil, nil, t)
 		requestContext := prepareRequestContext(request, Opts{Targets: []fab.Peer{fcmocks.NewMockPeer("p2", "")}}, t)
@@ -311,6 +327,11 @@ func TestResponseValidation(t *testing.T) {
 	s, ok := status.FromError(err)
 	assert.True(t, ok, "expected status error")
 	assert.EqualValues(t, int32(status.EndorsementMismatch), s.Code, "expected endorsement mismatch")
+
+	mismatch, ok := txn.EndorsementMismatchFromError(err)
+	assert.True(t, ok, "expected endorsement mismatch details")
+	assert.Len(t, mismatch.Endorsements, 2)
+	assert.Len(t, mismatch.Differences, 1)
 }
 
 func TestProposalProcessorHandlerError(t *testing.T) {
@@ -0,0 +1,102 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/blockdecoder"
+)
+
+// SimulationResult contains the decoded results of the simulation of a transaction by an endorser. The results
+// are decoded using the block decoder, so they have the same representation as the actions of decoded blocks.
+type SimulationResult struct {
+	// ChaincodeID identifies the chaincode that was invoked
+	ChaincodeID *blockdecoder.ChaincodeID
+	// Response is the response returned by the chaincode
+	Response *blockdecoder.ChaincodeResponse
+	// Namespaces contains the read/write set of each chaincode touched by the transaction
+	Namespaces []*blockdecoder.NamespaceRWSet
+	// Event is the chaincode event set by the transaction, or nil if no event was set
+	Event *blockdecoder.ChaincodeEvent
+}
+
+// SimulationResult decodes the simulation results endorsed by the peers.
+// The endorsements are validated to match, so the results are decoded from the first endorsement.
+func (r *Response) SimulationResult() (*SimulationResult, error) {
+	if len(r.Responses) == 0 || r.Responses[0].ProposalResponse == nil {
+		return nil, errors.New("no proposal responses")
+	}
+
+	return NewSimulationResult(r.Responses[0].Payload)
+}
+
+// NewSimulationResult decodes the simulation results of a transaction from a marshalled ProposalResponsePayload
+//  Parameters:
+//  proposalResponsePayload is the payload of a proposal response, or the ProposalResponsePayload of an endorsed action
+//
+//  Returns:
+//  the decoded simulation results
+func NewSimulationResult(proposalResponsePayload []byte) (*SimulationResult, error) {
+	action, err := blockdecoder.DecodeProposalResponsePayload(proposalResponsePayload)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSimulationResult(action), nil
+}
+
+// SimulationResultsFromTransaction decodes the simulation results of a transaction fetched from the ledger,
+// e.g. using ledger.Client.QueryTransaction.
+//  Parameters:
+//  tx is the processed transaction
+//
+//  Returns:
+//  the decoded simulation results of each action of the transaction
+func SimulationResultsFromTransaction(tx *pb.ProcessedTransaction) ([]*SimulationResult, error) {
+	if tx == nil || tx.TransactionEnvelope == nil {
+		return nil, errors.New("transaction envelope is required")
+	}
+
+	return SimulationResultsFromEnvelope(tx.TransactionEnvelope)
+}
+
+// SimulationResultsFromEnvelope decodes the simulation results of an endorser transaction envelope, e.g. a
+// transaction of a block.
+//  Parameters:
+//  envelope is the transaction envelope
+//
+//  Returns:
+//  the decoded simulation results of each action of the transaction
+func SimulationResultsFromEnvelope(envelope *common.Envelope) ([]*SimulationResult, error) {
+	tx, err := blockdecoder.DecodeEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.Type != common.HeaderType_ENDORSER_TRANSACTION.String() {
+		return nil, errors.Errorf("transaction is not an endorser transaction: %s", tx.Type)
+	}
+
+	var results []*SimulationResult
+	for _, action := range tx.Actions {
+		results = append(results, newSimulationResult(action))
+	}
+
+	return results, nil
+}
+
+func newSimulationResult(action *blockdecoder.Action) *SimulationResult {
+	return &SimulationResult{
+		ChaincodeID: action.Chaincode,
+		Response:    action.Response,
+		Namespaces:  action.RWSets,
+		Event:       action.Event,
+	}
+}
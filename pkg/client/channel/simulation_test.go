@@ -0,0 +1,151 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+func TestResponseSimulationResult(t *testing.T) {
+	resp := &Response{}
+	_, err := resp.SimulationResult()
+	assert.Error(t, err, "expected error for response without proposal responses")
+
+	resp.Responses = []*fab.TransactionProposalResponse{{
+		Endorser:         "peer1",
+		ProposalResponse: &pb.ProposalResponse{Payload: newTestProposalResponsePayload(t)},
+	}}
+
+	result, err := resp.SimulationResult()
+	require.NoError(t, err)
+	verifySimulationResult(t, result)
+}
+
+func TestSimulationResultsFromTransaction(t *testing.T) {
+	actionPayload, err := proto.Marshal(&pb.ChaincodeActionPayload{
+		Action: &pb.ChaincodeEndorsedAction{ProposalResponsePayload: newTestProposalResponsePayload(t)},
+	})
+	require.NoError(t, err)
+
+	tx := newTestProcessedTransaction(t, common.HeaderType_ENDORSER_TRANSACTION, &pb.Transaction{
+		Actions: []*pb.TransactionAction{{Payload: actionPayload}},
+	})
+
+	results, err := SimulationResultsFromTransaction(tx)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	verifySimulationResult(t, results[0])
+
+	_, err = SimulationResultsFromTransaction(newTestProcessedTransaction(t, common.HeaderType_CONFIG, &pb.Transaction{}))
+	assert.Error(t, err, "expected error for config transaction")
+
+	_, err = SimulationResultsFromTransaction(&pb.ProcessedTransaction{})
+	assert.Error(t, err, "expected error for missing envelope")
+}
+
+func verifySimulationResult(t *testing.T, result *SimulationResult) {
+	assert.Equal(t, "example_cc", result.ChaincodeID.Name)
+	assert.EqualValues(t, 200, result.Response.Status)
+	assert.Equal(t, []byte("result"), result.Response.Payload)
+	require.NotNil(t, result.Event)
+	assert.Equal(t, "moved", result.Event.EventName)
+
+	require.Len(t, result.Namespaces, 1)
+	ns := result.Namespaces[0]
+	assert.Equal(t, "example_cc", ns.Namespace)
+
+	require.Len(t, ns.Reads, 2)
+	assert.Equal(t, "a", ns.Reads[0].Key)
+	assert.EqualValues(t, 3, ns.Reads[0].Version.BlockNum)
+	assert.Nil(t, ns.Reads[1].Version)
+
+	require.Len(t, ns.Writes, 2)
+	assert.Equal(t, "a", ns.Writes[0].Key)
+	assert.Equal(t, []byte("90"), ns.Writes[0].Value)
+	assert.False(t, ns.Writes[0].IsDelete)
+	assert.Equal(t, "b", ns.Writes[1].Key)
+	assert.True(t, ns.Writes[1].IsDelete)
+
+	require.Len(t, ns.RangeQueries, 1)
+	assert.Equal(t, "k1", ns.RangeQueries[0].StartKey)
+	require.Len(t, ns.MetadataWrites, 1)
+	assert.Equal(t, "a", ns.MetadataWrites[0].Key)
+
+	require.Len(t, ns.Collections, 1)
+	coll := ns.Collections[0]
+	assert.Equal(t, "coll1", coll.Collection)
+	assert.Equal(t, hex.EncodeToString([]byte("pvthash")), coll.PvtRWSetHash)
+	require.Len(t, coll.HashedWrites, 1)
+	assert.Equal(t, hex.EncodeToString([]byte("keyhash")), coll.HashedWrites[0].KeyHash)
+}
+
+func newTestProposalResponsePayload(t *testing.T) []byte {
+	txRWSet := &rwsetutil.TxRwSet{NsRwSets: []*rwsetutil.NsRwSet{{
+		NameSpace: "example_cc",
+		KvRwSet: &kvrwset.KVRWSet{
+			Reads: []*kvrwset.KVRead{
+				{Key: "a", Version: &kvrwset.Version{BlockNum: 3, TxNum: 1}},
+				{Key: "c"},
+			},
+			Writes: []*kvrwset.KVWrite{
+				{Key: "a", Value: []byte("90")},
+				{Key: "b", IsDelete: true},
+			},
+			RangeQueriesInfo: []*kvrwset.RangeQueryInfo{{StartKey: "k1", EndKey: "k9", ItrExhausted: true}},
+			MetadataWrites:   []*kvrwset.KVMetadataWrite{{Key: "a", Entries: []*kvrwset.KVMetadataEntry{{Name: "VALIDATION_PARAMETER", Value: []byte("policy")}}}},
+		},
+		CollHashedRwSets: []*rwsetutil.CollHashedRwSet{{
+			CollectionName: "coll1",
+			HashedRwSet:    &kvrwset.HashedRWSet{HashedWrites: []*kvrwset.KVWriteHash{{KeyHash: []byte("keyhash"), ValueHash: []byte("valuehash")}}},
+			PvtRwSetHash:   []byte("pvthash"),
+		}},
+	}}}
+	results, err := txRWSet.ToProtoBytes()
+	require.NoError(t, err)
+
+	event, err := proto.Marshal(&pb.ChaincodeEvent{ChaincodeId: "example_cc", EventName: "moved", Payload: []byte("a->b")})
+	require.NoError(t, err)
+
+	action, err := proto.Marshal(&pb.ChaincodeAction{
+		ChaincodeId: &pb.ChaincodeID{Name: "example_cc"},
+		Response:    &pb.Response{Status: 200, Payload: []byte("result")},
+		Results:     results,
+		Events:      event,
+	})
+	require.NoError(t, err)
+
+	prp, err := proto.Marshal(&pb.ProposalResponsePayload{Extension: action})
+	require.NoError(t, err)
+	return prp
+}
+
+func newTestProcessedTransaction(t *testing.T, headerType common.HeaderType, tx *pb.Transaction) *pb.ProcessedTransaction {
+	chdr, err := proto.Marshal(&common.ChannelHeader{Type: int32(headerType), ChannelId: "mychannel", TxId: "txid"})
+	require.NoError(t, err)
+
+	data, err := proto.Marshal(tx)
+	require.NoError(t, err)
+
+	payload, err := proto.Marshal(&common.Payload{Header: &common.Header{ChannelHeader: chdr}, Data: data})
+	require.NoError(t, err)
+
+	return &pb.ProcessedTransaction{
+		TransactionEnvelope: &common.Envelope{Payload: payload},
+		ValidationCode:      int32(pb.TxValidationCode_VALID),
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package admission provides client-side admission control, which protects the peers from load spikes by
+// limiting the rate and the concurrency of the requests sent by a client.
+// It is used in conjunction with the WithClientLimit, WithChaincodeLimit and WithFunctionLimit options of the
+// channel client:
+// https://godoc.org/github.com/hyperledger/fabric-sdk-go/pkg/client/channel#WithClientLimit
+package admission
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrQueueFull is returned when a request cannot be admitted immediately and the queue of waiting requests is full
+	ErrQueueFull = errors.New("admission queue is full")
+
+	// ErrQueueTimeout is returned when a request is not admitted within the queue timeout
+	ErrQueueTimeout = errors.New("timed out waiting for admission")
+)
+
+// Opts defines the admission control parameters
+type Opts struct {
+	// Rate is the number of requests per second admitted by the token bucket. Zero disables rate limiting.
+	Rate float64
+	// Burst is the maximum number of requests admitted at once by the token bucket (the size of the bucket).
+	// It defaults to 1 if a rate is set.
+	Burst int
+	// MaxInFlight is the maximum number of admitted requests which haven't completed yet. Zero means no limit.
+	MaxInFlight int
+	// QueueSize is the maximum number of requests waiting for admission. Requests are rejected with ErrQueueFull
+	// when the queue is full, so with a zero queue size requests which cannot be admitted immediately are rejected.
+	QueueSize int
+	// QueueTimeout is the maximum time a request waits in the queue before being rejected with ErrQueueTimeout.
+	// Zero means that requests wait until they are admitted or their context is done.
+	QueueTimeout time.Duration
+}
+
+// Limiter admits requests according to a token-bucket rate limit and a maximum number of requests in flight
+type Limiter struct {
+	opts     Opts
+	mutex    sync.Mutex
+	tokens   float64
+	last     time.Time
+	inFlight int
+	waiting  int
+	// released is closed, and replaced, when an admitted request completes
+	released chan struct{}
+}
+
+// New returns a Limiter with the given opts
+func New(opts Opts) (*Limiter, error) {
+	if opts.Rate < 0 || opts.Burst < 0 || opts.MaxInFlight < 0 || opts.QueueSize < 0 || opts.QueueTimeout < 0 {
+		return nil, errors.New("admission limits must not be negative")
+	}
+	if opts.Rate == 0 && opts.MaxInFlight == 0 {
+		return nil, errors.New("either a rate or a maximum number of requests in flight is required")
+	}
+	if opts.Rate > 0 && opts.Burst == 0 {
+		opts.Burst = 1
+	}
+
+	return &Limiter{
+		opts:     opts,
+		tokens:   float64(opts.Burst),
+		last:     time.Now(),
+		released: make(chan struct{}),
+	}, nil
+}
+
+// Acquire waits until the request is admitted, the queue timeout expires or ctx is done. Requests which cannot be
+// admitted immediately wait in the queue, unless it is full.
+//  Parameters:
+//  ctx is the context of the request
+//
+//  Returns:
+//  the admission of the request, which must be released when the request completes, or an error if the request
+//  was rejected
+func (l *Limiter) Acquire(ctx context.Context) (*Admission, error) {
+	l.mutex.Lock()
+	// requests are only admitted immediately if no requests are waiting, so that the queue is not overtaken
+	if l.waiting == 0 {
+		if ok, _ := l.admit(); ok {
+			l.mutex.Unlock()
+			return &Admission{limiter: l}, nil
+		}
+	}
+	if l.waiting >= l.opts.QueueSize {
+		l.mutex.Unlock()
+		return nil, ErrQueueFull
+	}
+	l.waiting++
+	l.mutex.Unlock()
+
+	defer func() {
+		l.mutex.Lock()
+		l.waiting--
+		l.mutex.Unlock()
+	}()
+
+	var queueTimeout <-chan time.Time
+	if l.opts.QueueTimeout > 0 {
+		timer := time.NewTimer(l.opts.QueueTimeout)
+		defer timer.Stop()
+		queueTimeout = timer.C
+	}
+
+	for {
+		l.mutex.Lock()
+		ok, retryAfter := l.admit()
+		released := l.released
+		l.mutex.Unlock()
+
+		if ok {
+			return &Admission{limiter: l}, nil
+		}
+
+		if err := l.wait(ctx, released, retryAfter, queueTimeout); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// wait waits for an admitted request to complete or for the next token of the bucket, if retryAfter is set
+func (l *Limiter) wait(ctx context.Context, released chan struct{}, retryAfter time.Duration, queueTimeout <-chan time.Time) error {
+	var retry <-chan time.Time
+	if retryAfter > 0 {
+		timer := time.NewTimer(retryAfter)
+		defer timer.Stop()
+		retry = timer.C
+	}
+
+	select {
+	case <-released:
+		return nil
+	case <-retry:
+		return nil
+	case <-queueTimeout:
+		return ErrQueueTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// admit admits the request if the limits allow it. Otherwise, it returns the time after which a token is
+// available, or zero if the request has to wait for an admitted request to complete.
+func (l *Limiter) admit() (bool, time.Duration) {
+	if l.opts.MaxInFlight > 0 && l.inFlight >= l.opts.MaxInFlight {
+		return false, 0
+	}
+
+	if l.opts.Rate > 0 {
+		now := time.Now()
+		l.tokens = math.Min(float64(l.opts.Burst), l.tokens+now.Sub(l.last).Seconds()*l.opts.Rate)
+		l.last = now
+
+		if l.tokens < 1 {
+			return false, time.Duration((1 - l.tokens) / l.opts.Rate * float64(time.Second))
+		}
+		l.tokens--
+	}
+
+	l.inFlight++
+	return true, 0
+}
+
+// release releases the capacity held by an admitted request. If refund is set, the token consumed by the
+// request is returned to the bucket.
+func (l *Limiter) release(refund bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.inFlight--
+	if refund && l.opts.Rate > 0 {
+		l.tokens = math.Min(float64(l.opts.Burst), l.tokens+1)
+	}
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// Admission is the capacity held by an admitted request
+type Admission struct {
+	limiter *Limiter
+	once    sync.Once
+}
+
+// Release releases the capacity held by the request. It must be called when the admitted request completes.
+func (a *Admission) Release() {
+	a.once.Do(func() { a.limiter.release(false) })
+}
+
+// Cancel releases the capacity held by a request which is abandoned before it is sent, e.g. because another
+// limit rejected it, and returns its token to the bucket so that it doesn't count against the rate.
+func (a *Admission) Cancel() {
+	a.once.Do(func() { a.limiter.release(true) })
+}
@@ -0,0 +1,119 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(Opts{})
+	assert.Error(t, err, "expected error without limits")
+
+	_, err = New(Opts{Rate: -1})
+	assert.Error(t, err, "expected error for negative rate")
+
+	l, err := New(Opts{Rate: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, l.opts.Burst, "expected burst to default to 1")
+}
+
+func TestMaxInFlight(t *testing.T) {
+	l, err := New(Opts{MaxInFlight: 1, QueueSize: 1})
+	require.NoError(t, err)
+
+	release, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+
+	admitted := make(chan *Admission)
+	go func() {
+		r, err := l.Acquire(context.Background())
+		assert.NoError(t, err)
+		admitted <- r
+	}()
+
+	// wait for the second request to be queued
+	require.Eventually(t, func() bool { return l.queued() == 1 }, time.Second, time.Millisecond)
+
+	_, err = l.Acquire(context.Background())
+	assert.Equal(t, ErrQueueFull, err)
+
+	release.Release()
+	release.Release()
+	select {
+	case r := <-admitted:
+		r.Release()
+	case <-time.After(time.Second):
+		t.Fatal("expected queued request to be admitted once the in-flight request completed")
+	}
+	assert.Equal(t, 0, l.queued())
+}
+
+func TestRate(t *testing.T) {
+	l, err := New(Opts{Rate: 20, Burst: 2, QueueSize: 1})
+	require.NoError(t, err)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := l.Acquire(context.Background())
+		require.NoError(t, err)
+		release.Release()
+	}
+	assert.True(t, time.Since(start) >= 40*time.Millisecond, "expected the third request to wait for a token")
+}
+
+func TestCancel(t *testing.T) {
+	l, err := New(Opts{Rate: 0.001, Burst: 1, MaxInFlight: 1})
+	require.NoError(t, err)
+
+	admission, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+	admission.Cancel()
+	admission.Release()
+
+	admission, err = l.Acquire(context.Background())
+	require.NoError(t, err, "expected the token of the cancelled request to be refunded")
+	admission.Release()
+
+	_, err = l.Acquire(context.Background())
+	assert.Equal(t, ErrQueueFull, err, "expected the token of the released request to be consumed")
+}
+
+func TestQueueTimeout(t *testing.T) {
+	l, err := New(Opts{MaxInFlight: 1, QueueSize: 1, QueueTimeout: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	release, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+	defer release.Release()
+
+	_, err = l.Acquire(context.Background())
+	assert.Equal(t, ErrQueueTimeout, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = l.Acquire(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	noQueue, err := New(Opts{MaxInFlight: 1})
+	require.NoError(t, err)
+	_, err = noQueue.Acquire(context.Background())
+	require.NoError(t, err)
+	_, err = noQueue.Acquire(context.Background())
+	assert.Equal(t, ErrQueueFull, err, "expected request to be rejected without a queue")
+}
+
+func (l *Limiter) queued() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.waiting
+}
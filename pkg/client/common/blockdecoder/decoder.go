@@ -0,0 +1,432 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package blockdecoder decodes blocks into typed structures with a stable JSON representation,
+// for indexing and debugging. Its model of read/write sets is also used for the simulation results
+// of the channel client and the diagnostics of mismatched endorsements.
+package blockdecoder
+
+import (
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/sdkinternal/pkg/txflags"
+	"github.com/pkg/errors"
+)
+
+// Decode decodes a block. Transactions which cannot be decoded don't fail the block, instead
+// their Error field is set.
+//  Parameters:
+//  block is the block to decode
+//
+//  Returns:
+//  the decoded block
+func Decode(block *common.Block) (*Block, error) {
+	if block == nil || block.Header == nil {
+		return nil, errors.New("block header is missing")
+	}
+
+	decoded := &Block{
+		Number:       block.Header.Number,
+		Hash:         hex.EncodeToString(protoutil.BlockHeaderHash(block.Header)),
+		PreviousHash: hex.EncodeToString(block.Header.PreviousHash),
+		DataHash:     hex.EncodeToString(block.Header.DataHash),
+		Transactions: []*Transaction{},
+	}
+
+	var flags txflags.ValidationFlags
+	if len(block.GetMetadata().GetMetadata()) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		flags = txflags.ValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	}
+
+	for i, data := range block.GetData().GetData() {
+		tx := &Transaction{Index: i}
+		if i < len(flags) {
+			tx.ValidationCode = flags.Flag(i).String()
+		}
+
+		envelope, err := protoutil.GetEnvelopeFromBlock(data)
+		if err == nil {
+			err = decodeEnvelope(envelope, tx)
+		}
+		if err != nil {
+			tx.Error = err.Error()
+		}
+		decoded.Transactions = append(decoded.Transactions, tx)
+	}
+
+	return decoded, nil
+}
+
+// DecodeEnvelope decodes a transaction envelope. The index and the validation code of the
+// transaction are not set, since they are only known from the block of the transaction.
+//  Parameters:
+//  envelope is the transaction envelope to decode
+//
+//  Returns:
+//  the decoded transaction
+func DecodeEnvelope(envelope *common.Envelope) (*Transaction, error) {
+	tx := &Transaction{}
+	if err := decodeEnvelope(envelope, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func decodeEnvelope(envelope *common.Envelope, tx *Transaction) error {
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return errors.WithMessage(err, "failed to unmarshal payload")
+	}
+	if payload.Header == nil {
+		return errors.New("payload header is missing")
+	}
+
+	chdr, err := protoutil.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return errors.WithMessage(err, "failed to unmarshal channel header")
+	}
+	tx.TxID = chdr.TxId
+	tx.ChannelID = chdr.ChannelId
+	tx.Type = common.HeaderType(chdr.Type).String()
+	if chdr.Timestamp != nil {
+		timestamp, err := ptypes.Timestamp(chdr.Timestamp)
+		if err != nil {
+			return errors.WithMessage(err, "invalid timestamp")
+		}
+		tx.Timestamp = &timestamp
+	}
+
+	shdr, err := protoutil.UnmarshalSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		return errors.WithMessage(err, "failed to unmarshal signature header")
+	}
+	if len(shdr.Creator) > 0 {
+		if tx.Creator, err = decodeIdentity(shdr.Creator); err != nil {
+			return errors.WithMessage(err, "failed to decode creator")
+		}
+	}
+
+	switch common.HeaderType(chdr.Type) {
+	case common.HeaderType_ENDORSER_TRANSACTION:
+		tx.Actions, err = decodeActions(payload.Data)
+	case common.HeaderType_CONFIG:
+		tx.ConfigUpdate, err = decodeConfig(payload.Data)
+	}
+	return err
+}
+
+func decodeIdentity(serialized []byte) (*Identity, error) {
+	sid, err := protoutil.UnmarshalSerializedIdentity(serialized)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{MSPID: sid.Mspid}
+	block, _ := pem.Decode(sid.IdBytes)
+	if block == nil {
+		// not an X.509 identity, e.g. an idemix identity
+		return identity, nil
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to parse certificate")
+	}
+	identity.Subject = cert.Subject.String()
+	identity.Issuer = cert.Issuer.String()
+	return identity, nil
+}
+
+func decodeActions(data []byte) ([]*Action, error) {
+	transaction, err := protoutil.UnmarshalTransaction(data)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal transaction")
+	}
+
+	var actions []*Action
+	for i, txAction := range transaction.Actions {
+		action, err := decodeAction(txAction)
+		if err != nil {
+			return actions, errors.WithMessagef(err, "failed to decode action %d", i)
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+func decodeAction(txAction *pb.TransactionAction) (*Action, error) {
+	ccActionPayload, ccAction, err := protoutil.GetPayloads(txAction)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal chaincode action")
+	}
+
+	action := &Action{
+		Endorsements: []*Endorsement{},
+		RWSets:       []*NamespaceRWSet{},
+	}
+
+	if len(ccActionPayload.ChaincodeProposalPayload) > 0 {
+		if err := decodeInvocation(ccActionPayload.ChaincodeProposalPayload, action); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, endorsement := range ccActionPayload.Action.Endorsements {
+		endorser, err := decodeIdentity(endorsement.Endorser)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to decode endorser")
+		}
+		action.Endorsements = append(action.Endorsements, &Endorsement{Endorser: endorser, Signature: endorsement.Signature})
+	}
+
+	if err := decodeChaincodeAction(ccAction, action); err != nil {
+		return nil, err
+	}
+
+	return action, nil
+}
+
+// DecodeProposalResponsePayload decodes the results of the simulation of a transaction by an endorser. Only
+// the chaincode, response, read/write sets and event of the action are set, since the invocation and the
+// endorsements are not part of the payload.
+//  Parameters:
+//  payload is the payload of a proposal response, or the ProposalResponsePayload of an endorsed action
+//
+//  Returns:
+//  the decoded action
+func DecodeProposalResponsePayload(payload []byte) (*Action, error) {
+	prp, err := protoutil.UnmarshalProposalResponsePayload(payload)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal proposal response payload")
+	}
+	ccAction, err := protoutil.UnmarshalChaincodeAction(prp.Extension)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal chaincode action")
+	}
+
+	action := &Action{RWSets: []*NamespaceRWSet{}}
+	if err := decodeChaincodeAction(ccAction, action); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+// decodeChaincodeAction decodes the results of the simulation of an action
+func decodeChaincodeAction(ccAction *pb.ChaincodeAction, action *Action) error {
+	if ccID := ccAction.ChaincodeId; ccID != nil {
+		// the chaincode ID of the endorsed action includes the version of the chaincode
+		action.Chaincode = &ChaincodeID{Name: ccID.Name, Version: ccID.Version, Path: ccID.Path}
+	}
+
+	if response := ccAction.Response; response != nil {
+		action.Response = &ChaincodeResponse{Status: response.Status, Message: response.Message, Payload: response.Payload}
+	}
+
+	if len(ccAction.Results) > 0 {
+		txRWSet := &rwsetutil.TxRwSet{}
+		if err := txRWSet.FromProtoBytes(ccAction.Results); err != nil {
+			return errors.WithMessage(err, "failed to unmarshal read/write sets")
+		}
+		for _, nsRWSet := range txRWSet.NsRwSets {
+			action.RWSets = append(action.RWSets, decodeNsRWSet(nsRWSet))
+		}
+	}
+
+	if len(ccAction.Events) > 0 {
+		event, err := protoutil.UnmarshalChaincodeEvents(ccAction.Events)
+		if err != nil {
+			return errors.WithMessage(err, "failed to unmarshal chaincode event")
+		}
+		action.Event = &ChaincodeEvent{ChaincodeID: event.ChaincodeId, TxID: event.TxId, EventName: event.EventName, Payload: event.Payload}
+	}
+
+	return nil
+}
+
+func decodeInvocation(proposalPayload []byte, action *Action) error {
+	ccProposalPayload, err := protoutil.UnmarshalChaincodeProposalPayload(proposalPayload)
+	if err != nil {
+		return errors.WithMessage(err, "failed to unmarshal chaincode proposal payload")
+	}
+	cis, err := protoutil.UnmarshalChaincodeInvocationSpec(ccProposalPayload.Input)
+	if err != nil {
+		return errors.WithMessage(err, "failed to unmarshal chaincode invocation spec")
+	}
+
+	spec := cis.GetChaincodeSpec()
+	if ccID := spec.GetChaincodeId(); ccID != nil {
+		action.Chaincode = &ChaincodeID{Name: ccID.Name, Version: ccID.Version, Path: ccID.Path}
+	}
+	action.Args = spec.GetInput().GetArgs()
+	action.IsInit = spec.GetInput().GetIsInit()
+	return nil
+}
+
+func decodeNsRWSet(nsRWSet *rwsetutil.NsRwSet) *NamespaceRWSet {
+	decoded := &NamespaceRWSet{Namespace: nsRWSet.NameSpace}
+
+	if kvRWSet := nsRWSet.KvRwSet; kvRWSet != nil {
+		decoded.Reads = decodeReads(kvRWSet.Reads)
+		for _, w := range kvRWSet.Writes {
+			decoded.Writes = append(decoded.Writes, &Write{Key: w.Key, IsDelete: w.IsDelete, Value: w.Value})
+		}
+		for _, rq := range kvRWSet.RangeQueriesInfo {
+			decoded.RangeQueries = append(decoded.RangeQueries, decodeRangeQuery(rq))
+		}
+		for _, mw := range kvRWSet.MetadataWrites {
+			decoded.MetadataWrites = append(decoded.MetadataWrites, &MetadataWrite{Key: mw.Key, Entries: decodeMetadataEntries(mw.Entries)})
+		}
+	}
+
+	for _, collRWSet := range nsRWSet.CollHashedRwSets {
+		decoded.Collections = append(decoded.Collections, decodeCollHashedRWSet(collRWSet))
+	}
+
+	return decoded
+}
+
+func decodeCollHashedRWSet(collRWSet *rwsetutil.CollHashedRwSet) *CollectionHashedRWSet {
+	decoded := &CollectionHashedRWSet{
+		Collection:   collRWSet.CollectionName,
+		PvtRWSetHash: hex.EncodeToString(collRWSet.PvtRwSetHash),
+	}
+
+	hashedRWSet := collRWSet.HashedRwSet
+	if hashedRWSet == nil {
+		return decoded
+	}
+	for _, r := range hashedRWSet.HashedReads {
+		decoded.HashedReads = append(decoded.HashedReads, &HashedRead{KeyHash: hex.EncodeToString(r.KeyHash), Version: decodeVersion(r.Version)})
+	}
+	for _, w := range hashedRWSet.HashedWrites {
+		decoded.HashedWrites = append(decoded.HashedWrites, &HashedWrite{
+			KeyHash:   hex.EncodeToString(w.KeyHash),
+			IsDelete:  w.IsDelete,
+			ValueHash: hex.EncodeToString(w.ValueHash),
+		})
+	}
+	for _, mw := range hashedRWSet.MetadataWrites {
+		decoded.MetadataWrites = append(decoded.MetadataWrites, &HashedMetadataWrite{
+			KeyHash: hex.EncodeToString(mw.KeyHash),
+			Entries: decodeMetadataEntries(mw.Entries),
+		})
+	}
+	return decoded
+}
+
+func decodeReads(reads []*kvrwset.KVRead) []*Read {
+	var decoded []*Read
+	for _, r := range reads {
+		decoded = append(decoded, &Read{Key: r.Key, Version: decodeVersion(r.Version)})
+	}
+	return decoded
+}
+
+func decodeRangeQuery(rq *kvrwset.RangeQueryInfo) *RangeQuery {
+	decoded := &RangeQuery{StartKey: rq.StartKey, EndKey: rq.EndKey, ItrExhausted: rq.ItrExhausted}
+	if rawReads := rq.GetRawReads(); rawReads != nil {
+		decoded.Reads = decodeReads(rawReads.KvReads)
+	}
+	if merkleSummary := rq.GetReadsMerkleHashes(); merkleSummary != nil {
+		for _, hash := range merkleSummary.MaxLevelHashes {
+			decoded.ReadsMerkleHashes = append(decoded.ReadsMerkleHashes, hex.EncodeToString(hash))
+		}
+	}
+	return decoded
+}
+
+func decodeMetadataEntries(entries []*kvrwset.KVMetadataEntry) []*MetadataEntry {
+	var decoded []*MetadataEntry
+	for _, e := range entries {
+		decoded = append(decoded, &MetadataEntry{Name: e.Name, Value: e.Value})
+	}
+	return decoded
+}
+
+func decodeVersion(version *kvrwset.Version) *Version {
+	if version == nil {
+		return nil
+	}
+	return &Version{BlockNum: version.BlockNum, TxNum: version.TxNum}
+}
+
+func decodeConfig(data []byte) (*ConfigUpdate, error) {
+	configEnvelope := &common.ConfigEnvelope{}
+	if err := proto.Unmarshal(data, configEnvelope); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal config envelope")
+	}
+
+	decoded := &ConfigUpdate{Sequence: configEnvelope.GetConfig().GetSequence()}
+	if configEnvelope.LastUpdate == nil {
+		return decoded, nil
+	}
+
+	updateEnvelope, err := protoutil.EnvelopeToConfigUpdate(configEnvelope.LastUpdate)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal config update envelope")
+	}
+	update := &common.ConfigUpdate{}
+	if err := proto.Unmarshal(updateEnvelope.ConfigUpdate, update); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal config update")
+	}
+
+	decoded.ChannelID = update.ChannelId
+	decoded.ReadSet = decodeConfigGroup(update.ReadSet)
+	decoded.WriteSet = decodeConfigGroup(update.WriteSet)
+	for _, signature := range updateEnvelope.Signatures {
+		shdr, err := protoutil.UnmarshalSignatureHeader(signature.SignatureHeader)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to unmarshal config signature header")
+		}
+		signer, err := decodeIdentity(shdr.Creator)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to decode config signer")
+		}
+		decoded.Signers = append(decoded.Signers, signer)
+	}
+
+	return decoded, nil
+}
+
+func decodeConfigGroup(group *common.ConfigGroup) *ConfigGroup {
+	if group == nil {
+		return nil
+	}
+
+	decoded := &ConfigGroup{Version: group.Version, ModPolicy: group.ModPolicy}
+	for name, g := range group.Groups {
+		if decoded.Groups == nil {
+			decoded.Groups = make(map[string]*ConfigGroup)
+		}
+		decoded.Groups[name] = decodeConfigGroup(g)
+	}
+	for name, v := range group.Values {
+		if decoded.Values == nil {
+			decoded.Values = make(map[string]*ConfigValue)
+		}
+		decoded.Values[name] = &ConfigValue{Version: v.Version, ModPolicy: v.ModPolicy, Value: v.Value}
+	}
+	for name, p := range group.Policies {
+		if decoded.Policies == nil {
+			decoded.Policies = make(map[string]*ConfigPolicy)
+		}
+		policy := &ConfigPolicy{Version: p.Version, ModPolicy: p.ModPolicy}
+		if p.Policy != nil {
+			policy.Type = common.Policy_PolicyType(p.Policy.Type).String()
+			policy.Value = p.Policy.Value
+		}
+		decoded.Policies[name] = policy
+	}
+	return decoded
+}
@@ -0,0 +1,304 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockdecoder
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/sdkinternal/pkg/txflags"
+)
+
+const (
+	channelID = "mychannel"
+	txID      = "txid1"
+)
+
+func TestDecode(t *testing.T) {
+	creator := newSerializedIdentity(t, "Org1MSP", "user1")
+	endorser := newSerializedIdentity(t, "Org2MSP", "peer0")
+	txTime := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	block := newBlock(
+		newEndorserTransaction(t, creator, endorser, txTime),
+		newConfigTransaction(t, creator),
+		[]byte("invalid envelope"),
+	)
+	flags := txflags.New(3)
+	flags.SetFlag(0, pb.TxValidationCode_VALID)
+	flags.SetFlag(1, pb.TxValidationCode_VALID)
+	flags.SetFlag(2, pb.TxValidationCode_BAD_PAYLOAD)
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = flags
+
+	decoded, err := Decode(block)
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 3, decoded.Number)
+	assert.Equal(t, hex.EncodeToString(protoutil.BlockHeaderHash(block.Header)), decoded.Hash)
+	assert.Equal(t, "0102", decoded.PreviousHash)
+	assert.Equal(t, hex.EncodeToString(protoutil.BlockDataHash(block.Data)), decoded.DataHash)
+	require.Len(t, decoded.Transactions, 3)
+
+	t.Run("endorser transaction", func(t *testing.T) {
+		tx := decoded.Transactions[0]
+		require.Empty(t, tx.Error)
+		assert.Equal(t, txID, tx.TxID)
+		assert.Equal(t, channelID, tx.ChannelID)
+		assert.Equal(t, "ENDORSER_TRANSACTION", tx.Type)
+		assert.Equal(t, "VALID", tx.ValidationCode)
+		require.NotNil(t, tx.Timestamp)
+		assert.True(t, txTime.Equal(*tx.Timestamp))
+		assert.Equal(t, &Identity{MSPID: "Org1MSP", Subject: "CN=user1", Issuer: "CN=user1"}, tx.Creator)
+
+		require.Len(t, tx.Actions, 1)
+		action := tx.Actions[0]
+		assert.Equal(t, &ChaincodeID{Name: "example_cc", Version: "v1"}, action.Chaincode)
+		assert.Equal(t, [][]byte{[]byte("move"), []byte("a"), []byte("b")}, action.Args)
+		assert.Equal(t, &ChaincodeResponse{Status: 200, Payload: []byte("result")}, action.Response)
+		require.Len(t, action.Endorsements, 1)
+		assert.Equal(t, "Org2MSP", action.Endorsements[0].Endorser.MSPID)
+		assert.Equal(t, []byte("signature"), action.Endorsements[0].Signature)
+		assert.Equal(t, &ChaincodeEvent{ChaincodeID: "example_cc", TxID: txID, EventName: "moved", Payload: []byte("event")}, action.Event)
+
+		require.Len(t, action.RWSets, 1)
+		rwSet := action.RWSets[0]
+		assert.Equal(t, "example_cc", rwSet.Namespace)
+		assert.Equal(t, []*Read{{Key: "a", Version: &Version{BlockNum: 1, TxNum: 2}}, {Key: "new"}}, rwSet.Reads)
+		assert.Equal(t, []*Write{{Key: "a", Value: []byte("90")}, {Key: "b", IsDelete: true}}, rwSet.Writes)
+		require.Len(t, rwSet.Collections, 1)
+		assert.Equal(t, &CollectionHashedRWSet{
+			Collection:   "private",
+			PvtRWSetHash: "ff",
+			HashedReads:  []*HashedRead{{KeyHash: "0a", Version: &Version{BlockNum: 1}}},
+			HashedWrites: []*HashedWrite{{KeyHash: "0b", ValueHash: "0c"}},
+		}, rwSet.Collections[0])
+	})
+
+	t.Run("config transaction", func(t *testing.T) {
+		tx := decoded.Transactions[1]
+		require.Empty(t, tx.Error)
+		assert.Equal(t, "CONFIG", tx.Type)
+		require.NotNil(t, tx.ConfigUpdate)
+		assert.EqualValues(t, 4, tx.ConfigUpdate.Sequence)
+		assert.Equal(t, channelID, tx.ConfigUpdate.ChannelID)
+		assert.Equal(t, []*Identity{{MSPID: "Org1MSP", Subject: "CN=user1", Issuer: "CN=user1"}}, tx.ConfigUpdate.Signers)
+		require.NotNil(t, tx.ConfigUpdate.WriteSet)
+		application := tx.ConfigUpdate.WriteSet.Groups["Application"]
+		require.NotNil(t, application)
+		assert.EqualValues(t, 2, application.Version)
+		assert.Equal(t, &ConfigPolicy{Type: "SIGNATURE", Value: []byte("policy")}, application.Policies["Writers"])
+		assert.Equal(t, &ConfigValue{ModPolicy: "Admins", Value: []byte("value")}, application.Values["ACLs"])
+	})
+
+	t.Run("invalid transaction", func(t *testing.T) {
+		tx := decoded.Transactions[2]
+		assert.Equal(t, 2, tx.Index)
+		assert.Equal(t, "BAD_PAYLOAD", tx.ValidationCode)
+		assert.NotEmpty(t, tx.Error)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(decoded)
+		require.NoError(t, err)
+		again, err := json.Marshal(decoded)
+		require.NoError(t, err)
+		assert.Equal(t, data, again, "expected stable JSON output")
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &fields))
+		assert.Contains(t, fields, "previous_hash")
+		tx := fields["transactions"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "VALID", tx["validation_code"])
+		assert.Equal(t, "2020-03-04T05:06:07Z", tx["timestamp"])
+	})
+
+	_, err = Decode(&common.Block{})
+	assert.Error(t, err, "expected error for block without header")
+}
+
+func TestDecodeEnvelope(t *testing.T) {
+	envelope, err := protoutil.GetEnvelopeFromBlock(newEndorserTransaction(t, newSerializedIdentity(t, "Org1MSP", "user1"), nil, time.Now()))
+	require.NoError(t, err)
+
+	tx, err := DecodeEnvelope(envelope)
+	require.NoError(t, err)
+	assert.Equal(t, txID, tx.TxID)
+	assert.Empty(t, tx.ValidationCode)
+	require.Len(t, tx.Actions, 1)
+	assert.Empty(t, tx.Actions[0].Endorsements)
+
+	_, err = DecodeEnvelope(&common.Envelope{Payload: []byte("invalid")})
+	assert.Error(t, err)
+}
+
+func TestDecodeProposalResponsePayload(t *testing.T) {
+	results, err := (&rwsetutil.TxRwSet{NsRwSets: []*rwsetutil.NsRwSet{{
+		NameSpace: "example_cc",
+		KvRwSet:   &kvrwset.KVRWSet{Writes: []*kvrwset.KVWrite{{Key: "b", IsDelete: true}}},
+	}}}).ToProtoBytes()
+	require.NoError(t, err)
+
+	payload, err := protoutil.GetBytesProposalResponsePayload([]byte("hash"), &pb.Response{Status: 200, Payload: []byte("result")},
+		results, nil, &pb.ChaincodeID{Name: "example_cc", Version: "v1"})
+	require.NoError(t, err)
+
+	action, err := DecodeProposalResponsePayload(payload)
+	require.NoError(t, err)
+	assert.Equal(t, &ChaincodeID{Name: "example_cc", Version: "v1"}, action.Chaincode)
+	assert.Equal(t, &ChaincodeResponse{Status: 200, Payload: []byte("result")}, action.Response)
+	assert.Nil(t, action.Event)
+	require.Len(t, action.RWSets, 1)
+	assert.Equal(t, []*Write{{Key: "b", IsDelete: true}}, action.RWSets[0].Writes)
+
+	_, err = DecodeProposalResponsePayload([]byte("invalid"))
+	assert.Error(t, err)
+}
+
+func newBlock(envelopes ...[]byte) *common.Block {
+	block := protoutil.NewBlock(3, []byte{1, 2})
+	block.Data.Data = envelopes
+	block.Header.DataHash = protoutil.BlockDataHash(block.Data)
+	return block
+}
+
+func newEndorserTransaction(t *testing.T, creator, endorser []byte, txTime time.Time) []byte {
+	txRWSet := &rwsetutil.TxRwSet{
+		NsRwSets: []*rwsetutil.NsRwSet{{
+			NameSpace: "example_cc",
+			KvRwSet: &kvrwset.KVRWSet{
+				Reads: []*kvrwset.KVRead{
+					{Key: "a", Version: &kvrwset.Version{BlockNum: 1, TxNum: 2}},
+					{Key: "new"},
+				},
+				Writes: []*kvrwset.KVWrite{
+					{Key: "a", Value: []byte("90")},
+					{Key: "b", IsDelete: true},
+				},
+			},
+			CollHashedRwSets: []*rwsetutil.CollHashedRwSet{{
+				CollectionName: "private",
+				HashedRwSet: &kvrwset.HashedRWSet{
+					HashedReads:  []*kvrwset.KVReadHash{{KeyHash: []byte{0x0a}, Version: &kvrwset.Version{BlockNum: 1}}},
+					HashedWrites: []*kvrwset.KVWriteHash{{KeyHash: []byte{0x0b}, ValueHash: []byte{0x0c}}},
+				},
+				PvtRwSetHash: []byte{0xff},
+			}},
+		}},
+	}
+	results, err := txRWSet.ToProtoBytes()
+	require.NoError(t, err)
+
+	event := protoutil.MarshalOrPanic(&pb.ChaincodeEvent{ChaincodeId: "example_cc", TxId: txID, EventName: "moved", Payload: []byte("event")})
+	responsePayload, err := protoutil.GetBytesProposalResponsePayload([]byte("hash"), &pb.Response{Status: 200, Payload: []byte("result")},
+		results, event, &pb.ChaincodeID{Name: "example_cc", Version: "v1"})
+	require.NoError(t, err)
+
+	cis := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		ChaincodeId: &pb.ChaincodeID{Name: "example_cc"},
+		Input:       &pb.ChaincodeInput{Args: [][]byte{[]byte("move"), []byte("a"), []byte("b")}},
+	}}
+	var endorsements []*pb.Endorsement
+	if endorser != nil {
+		endorsements = append(endorsements, &pb.Endorsement{Endorser: endorser, Signature: []byte("signature")})
+	}
+	actionPayload := &pb.ChaincodeActionPayload{
+		ChaincodeProposalPayload: protoutil.MarshalOrPanic(&pb.ChaincodeProposalPayload{Input: protoutil.MarshalOrPanic(cis)}),
+		Action:                   &pb.ChaincodeEndorsedAction{ProposalResponsePayload: responsePayload, Endorsements: endorsements},
+	}
+	tx := &pb.Transaction{Actions: []*pb.TransactionAction{{Payload: protoutil.MarshalOrPanic(actionPayload)}}}
+
+	chdr := &common.ChannelHeader{
+		Type:      int32(common.HeaderType_ENDORSER_TRANSACTION),
+		ChannelId: channelID,
+		TxId:      txID,
+		Timestamp: &timestamp.Timestamp{Seconds: txTime.Unix()},
+	}
+	return newEnvelope(chdr, creator, protoutil.MarshalOrPanic(tx))
+}
+
+func newConfigTransaction(t *testing.T, signer []byte) []byte {
+	update := &common.ConfigUpdate{
+		ChannelId: channelID,
+		WriteSet: &common.ConfigGroup{
+			Groups: map[string]*common.ConfigGroup{
+				"Application": {
+					Version: 2,
+					Values: map[string]*common.ConfigValue{
+						"ACLs": {ModPolicy: "Admins", Value: []byte("value")},
+					},
+					Policies: map[string]*common.ConfigPolicy{
+						"Writers": {Policy: &common.Policy{Type: int32(common.Policy_SIGNATURE), Value: []byte("policy")}},
+					},
+				},
+			},
+		},
+	}
+	updateEnvelope := &common.ConfigUpdateEnvelope{
+		ConfigUpdate: protoutil.MarshalOrPanic(update),
+		Signatures: []*common.ConfigSignature{{
+			SignatureHeader: protoutil.MarshalOrPanic(&common.SignatureHeader{Creator: signer}),
+			Signature:       []byte("signature"),
+		}},
+	}
+	lastUpdate := &common.Envelope{Payload: protoutil.MarshalOrPanic(&common.Payload{
+		Header: &common.Header{ChannelHeader: protoutil.MarshalOrPanic(&common.ChannelHeader{Type: int32(common.HeaderType_CONFIG_UPDATE)})},
+		Data:   protoutil.MarshalOrPanic(updateEnvelope),
+	})}
+
+	configEnvelope := &common.ConfigEnvelope{Config: &common.Config{Sequence: 4}, LastUpdate: lastUpdate}
+	chdr := &common.ChannelHeader{Type: int32(common.HeaderType_CONFIG), ChannelId: channelID}
+	return newEnvelope(chdr, nil, protoutil.MarshalOrPanic(configEnvelope))
+}
+
+func newEnvelope(chdr *common.ChannelHeader, creator []byte, data []byte) []byte {
+	payload := &common.Payload{
+		Header: &common.Header{
+			ChannelHeader:   protoutil.MarshalOrPanic(chdr),
+			SignatureHeader: protoutil.MarshalOrPanic(&common.SignatureHeader{Creator: creator}),
+		},
+		Data: data,
+	}
+	return protoutil.MarshalOrPanic(&common.Envelope{Payload: protoutil.MarshalOrPanic(payload)})
+}
+
+func newSerializedIdentity(t *testing.T, mspID, commonName string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	identity, err := proto.Marshal(&msp.SerializedIdentity{Mspid: mspID, IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})})
+	require.NoError(t, err)
+	return identity
+}
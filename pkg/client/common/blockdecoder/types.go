@@ -0,0 +1,206 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockdecoder
+
+import (
+	"time"
+)
+
+// Block is a decoded block. Hashes are hex encoded, while values, payloads and signatures are
+// base64 encoded in JSON.
+type Block struct {
+	Number uint64 `json:"number"`
+	// Hash is the hash of the block header, which is the PreviousHash of the next block
+	Hash         string         `json:"hash"`
+	PreviousHash string         `json:"previous_hash"`
+	DataHash     string         `json:"data_hash"`
+	Transactions []*Transaction `json:"transactions"`
+}
+
+// Transaction is a decoded transaction of a block
+type Transaction struct {
+	// Index is the position of the transaction in the block
+	Index     int        `json:"index"`
+	TxID      string     `json:"tx_id,omitempty"`
+	ChannelID string     `json:"channel_id,omitempty"`
+	Type      string     `json:"type,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	// ValidationCode is the validation code of the transaction from the block metadata, if the block was committed
+	ValidationCode string    `json:"validation_code,omitempty"`
+	Creator        *Identity `json:"creator,omitempty"`
+	// Actions are set for endorser transactions
+	Actions []*Action `json:"actions,omitempty"`
+	// ConfigUpdate is set for config transactions
+	ConfigUpdate *ConfigUpdate `json:"config_update,omitempty"`
+	// Error is set if the transaction could not be decoded, in which case only the fields
+	// decoded before the error are set
+	Error string `json:"error,omitempty"`
+}
+
+// Identity is a decoded serialized identity
+type Identity struct {
+	MSPID string `json:"msp_id"`
+	// Subject and Issuer are the distinguished names of the certificate, if the identity is an X.509 certificate
+	Subject string `json:"subject,omitempty"`
+	Issuer  string `json:"issuer,omitempty"`
+}
+
+// Action is a decoded chaincode action of an endorser transaction
+type Action struct {
+	Chaincode *ChaincodeID `json:"chaincode"`
+	// Args are the input arguments of the chaincode invocation, the first of which is usually the function
+	Args         [][]byte           `json:"args"`
+	IsInit       bool               `json:"is_init,omitempty"`
+	Response     *ChaincodeResponse `json:"response,omitempty"`
+	Endorsements []*Endorsement     `json:"endorsements"`
+	RWSets       []*NamespaceRWSet  `json:"rw_sets"`
+	Event        *ChaincodeEvent    `json:"event,omitempty"`
+}
+
+// ChaincodeID identifies the chaincode invoked by an action
+type ChaincodeID struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Path    string `json:"path,omitempty"`
+}
+
+// ChaincodeResponse is the response returned by the chaincode at endorsement
+type ChaincodeResponse struct {
+	Status  int32  `json:"status"`
+	Message string `json:"message,omitempty"`
+	Payload []byte `json:"payload,omitempty"`
+}
+
+// Endorsement is the endorsement of an action by a peer
+type Endorsement struct {
+	Endorser  *Identity `json:"endorser"`
+	Signature []byte    `json:"signature"`
+}
+
+// NamespaceRWSet is the read/write set of an action in the namespace of a chaincode
+type NamespaceRWSet struct {
+	Namespace      string                   `json:"namespace"`
+	Reads          []*Read                  `json:"reads,omitempty"`
+	Writes         []*Write                 `json:"writes,omitempty"`
+	RangeQueries   []*RangeQuery            `json:"range_queries,omitempty"`
+	MetadataWrites []*MetadataWrite         `json:"metadata_writes,omitempty"`
+	Collections    []*CollectionHashedRWSet `json:"collections,omitempty"`
+}
+
+// Version is the height at which a key was committed
+type Version struct {
+	BlockNum uint64 `json:"block_num"`
+	TxNum    uint64 `json:"tx_num"`
+}
+
+// Read is a key read by a transaction. A nil version indicates that the key did not exist.
+type Read struct {
+	Key     string   `json:"key"`
+	Version *Version `json:"version"`
+}
+
+// Write is a key written or deleted by a transaction
+type Write struct {
+	Key      string `json:"key"`
+	IsDelete bool   `json:"is_delete,omitempty"`
+	Value    []byte `json:"value,omitempty"`
+}
+
+// RangeQuery is a range query executed by a transaction
+type RangeQuery struct {
+	StartKey     string  `json:"start_key"`
+	EndKey       string  `json:"end_key"`
+	ItrExhausted bool    `json:"itr_exhausted"`
+	Reads        []*Read `json:"reads,omitempty"`
+	// ReadsMerkleHashes are set instead of the reads if the range query read many keys
+	ReadsMerkleHashes []string `json:"reads_merkle_hashes,omitempty"`
+}
+
+// MetadataWrite is a write of the metadata of a key
+type MetadataWrite struct {
+	Key     string           `json:"key"`
+	Entries []*MetadataEntry `json:"entries,omitempty"`
+}
+
+// MetadataEntry is an entry of the metadata of a key
+type MetadataEntry struct {
+	Name  string `json:"name"`
+	Value []byte `json:"value,omitempty"`
+}
+
+// CollectionHashedRWSet contains the hashes of the private data accessed by a transaction in a collection.
+// Key and value hashes are hex encoded.
+type CollectionHashedRWSet struct {
+	Collection     string                 `json:"collection"`
+	PvtRWSetHash   string                 `json:"pvt_rwset_hash"`
+	HashedReads    []*HashedRead          `json:"hashed_reads,omitempty"`
+	HashedWrites   []*HashedWrite         `json:"hashed_writes,omitempty"`
+	MetadataWrites []*HashedMetadataWrite `json:"metadata_writes,omitempty"`
+}
+
+// HashedRead is a private key read by a transaction
+type HashedRead struct {
+	KeyHash string   `json:"key_hash"`
+	Version *Version `json:"version"`
+}
+
+// HashedWrite is a private key written or deleted by a transaction
+type HashedWrite struct {
+	KeyHash   string `json:"key_hash"`
+	IsDelete  bool   `json:"is_delete,omitempty"`
+	ValueHash string `json:"value_hash,omitempty"`
+}
+
+// HashedMetadataWrite is a write of the metadata of a private key
+type HashedMetadataWrite struct {
+	KeyHash string           `json:"key_hash"`
+	Entries []*MetadataEntry `json:"entries,omitempty"`
+}
+
+// ChaincodeEvent is the event set by a chaincode
+type ChaincodeEvent struct {
+	ChaincodeID string `json:"chaincode_id"`
+	TxID        string `json:"tx_id"`
+	EventName   string `json:"event_name"`
+	Payload     []byte `json:"payload,omitempty"`
+}
+
+// ConfigUpdate is the update applied by a config transaction
+type ConfigUpdate struct {
+	// Sequence is the sequence number of the config resulting from the update
+	Sequence uint64 `json:"sequence"`
+	// ChannelID, ReadSet, WriteSet and Signers are not set for the genesis block, whose config
+	// is not the result of an update
+	ChannelID string       `json:"channel_id,omitempty"`
+	ReadSet   *ConfigGroup `json:"read_set,omitempty"`
+	WriteSet  *ConfigGroup `json:"write_set,omitempty"`
+	Signers   []*Identity  `json:"signers,omitempty"`
+}
+
+// ConfigGroup is a group of the channel config
+type ConfigGroup struct {
+	Version   uint64                   `json:"version"`
+	ModPolicy string                   `json:"mod_policy,omitempty"`
+	Groups    map[string]*ConfigGroup  `json:"groups,omitempty"`
+	Values    map[string]*ConfigValue  `json:"values,omitempty"`
+	Policies  map[string]*ConfigPolicy `json:"policies,omitempty"`
+}
+
+// ConfigValue is a value of the channel config. The value is the marshalled protobuf message.
+type ConfigValue struct {
+	Version   uint64 `json:"version"`
+	ModPolicy string `json:"mod_policy,omitempty"`
+	Value     []byte `json:"value,omitempty"`
+}
+
+// ConfigPolicy is a policy of the channel config. The value is the marshalled protobuf policy of the given type.
+type ConfigPolicy struct {
+	Version   uint64 `json:"version"`
+	ModPolicy string `json:"mod_policy,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Value     []byte `json:"value,omitempty"`
+}
@@ -0,0 +1,288 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package blockverifier verifies the integrity of the blocks of a channel independently of the peers which
+// returned them, by checking the hash chain of the block headers, the data hashes and the orderer signatures.
+package blockverifier
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	mb "github.com/hyperledger/fabric-protos-go/msp"
+	channelConfig "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/internal/policyeval"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/channel/membership"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+// blockValidationPolicy is the policy of the orderer group which the orderer signatures of a block must satisfy
+const blockValidationPolicy = "BlockValidation"
+
+// Check identifies the verification a block failed
+type Check string
+
+const (
+	// SequenceCheck fails if a block doesn't follow the previously verified block
+	SequenceCheck Check = "sequence"
+	// PreviousHashCheck fails if the previous hash of a block is not the hash of the header of the previous block
+	PreviousHashCheck Check = "previous hash"
+	// DataHashCheck fails if the data hash of a block is not the hash of its data
+	DataHashCheck Check = "data hash"
+	// SignatureCheck fails if the orderer signatures of a block don't satisfy the BlockValidation policy
+	SignatureCheck Check = "orderer signature"
+	// ConfigCheck fails if the channel config of a config block cannot be loaded
+	ConfigCheck Check = "config"
+)
+
+// VerificationError is returned for a block which failed verification
+type VerificationError struct {
+	BlockNumber uint64
+	Check       Check
+	Reason      string
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("block %d failed %s verification: %s", e.BlockNumber, e.Check, e.Reason)
+}
+
+// Verifier verifies consecutive blocks of a channel. The orderer signatures of each block are verified against
+// the orderer organizations and the BlockValidation policy of the channel config in effect at the height of the
+// block, which is updated whenever a config block is verified.
+type Verifier struct {
+	cs       core.CryptoSuite
+	config   *ordererConfig
+	previous *common.BlockHeader
+}
+
+// New returns a verifier of the blocks following the given config block
+//  Parameters:
+//  configBlock is the config block in effect for the first block to be verified, which is trusted
+//  cs is the crypto suite used to verify the orderer signatures
+//
+//  Returns:
+//  the block verifier
+func New(configBlock *common.Block, cs core.CryptoSuite) (*Verifier, error) {
+	config, err := configFromBlock(configBlock)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, errors.Errorf("block %d is not a config block", configBlock.GetHeader().GetNumber())
+	}
+
+	ordererConfig, err := newOrdererConfig(config, cs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Verifier{cs: cs, config: ordererConfig}, nil
+}
+
+// Verify verifies the next block. The block must follow the previously verified block, if any.
+//  Parameters:
+//  block is the block to verify
+//
+//  Returns:
+//  a VerificationError if the block failed verification
+func (v *Verifier) Verify(block *common.Block) error {
+	header := block.GetHeader()
+	if header == nil {
+		return errors.New("block header is missing")
+	}
+
+	if v.previous != nil {
+		if header.Number != v.previous.Number+1 {
+			return &VerificationError{BlockNumber: header.Number, Check: SequenceCheck,
+				Reason: fmt.Sprintf("expected block %d", v.previous.Number+1)}
+		}
+		if previousHash := protoutil.BlockHeaderHash(v.previous); !bytes.Equal(header.PreviousHash, previousHash) {
+			return &VerificationError{BlockNumber: header.Number, Check: PreviousHashCheck,
+				Reason: fmt.Sprintf("previous hash %x does not match the hash %x of block %d", header.PreviousHash, previousHash, v.previous.Number)}
+		}
+	}
+
+	if block.Data == nil {
+		return &VerificationError{BlockNumber: header.Number, Check: DataHashCheck, Reason: "block data is missing"}
+	}
+	if dataHash := protoutil.BlockDataHash(block.Data); !bytes.Equal(header.DataHash, dataHash) {
+		return &VerificationError{BlockNumber: header.Number, Check: DataHashCheck,
+			Reason: fmt.Sprintf("data hash %x does not match the computed hash %x", header.DataHash, dataHash)}
+	}
+
+	// the genesis block is not signed by the orderers
+	if header.Number > 0 {
+		if err := v.config.verifySignatures(block); err != nil {
+			return &VerificationError{BlockNumber: header.Number, Check: SignatureCheck, Reason: err.Error()}
+		}
+	}
+
+	config, err := configFromBlock(block)
+	if err != nil {
+		return &VerificationError{BlockNumber: header.Number, Check: ConfigCheck, Reason: err.Error()}
+	}
+	if config != nil {
+		logger.Debugf("Updating the orderer config from config block %d", header.Number)
+		ordererConfig, err := newOrdererConfig(config, v.cs)
+		if err != nil {
+			return &VerificationError{BlockNumber: header.Number, Check: ConfigCheck, Reason: err.Error()}
+		}
+		v.config = ordererConfig
+	}
+
+	v.previous = header
+	return nil
+}
+
+// VerifyBlocks verifies consecutive blocks, stopping at the first block which fails verification
+//  Parameters:
+//  configBlock is the config block in effect for the first block, which is trusted
+//  blocks are the consecutive blocks to verify
+//  cs is the crypto suite used to verify the orderer signatures
+//
+//  Returns:
+//  a VerificationError for the first block which failed verification
+func VerifyBlocks(configBlock *common.Block, blocks []*common.Block, cs core.CryptoSuite) error {
+	v, err := New(configBlock, cs)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		if err := v.Verify(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ordererConfig holds the orderer group of a channel config and the MSPs of the orderer organizations
+type ordererConfig struct {
+	group        *common.ConfigGroup
+	deserializer msp.IdentityDeserializer
+}
+
+func newOrdererConfig(config *common.Config, cs core.CryptoSuite) (*ordererConfig, error) {
+	group := config.GetChannelGroup().GetGroups()[channelConfig.OrdererGroupKey]
+	if group == nil {
+		return nil, errors.New("channel config does not contain an orderer group")
+	}
+
+	var mspConfigs []*mb.MSPConfig
+	for org, orgGroup := range group.Groups {
+		value := orgGroup.Values[channelConfig.MSPKey]
+		if value == nil {
+			return nil, errors.Errorf("orderer organization %s does not define an MSP", org)
+		}
+		mspConfig := &mb.MSPConfig{}
+		if err := proto.Unmarshal(value.Value, mspConfig); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal the MSP config of orderer organization %s", org)
+		}
+		mspConfigs = append(mspConfigs, mspConfig)
+	}
+
+	mspManager, err := membership.NewMSPManagerFromConfigs(mspConfigs, cs)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create the MSP manager of the orderer organizations")
+	}
+
+	return &ordererConfig{group: group, deserializer: mspManager}, nil
+}
+
+// verifySignatures verifies the orderer signatures of the block and evaluates the BlockValidation policy
+// against the orderers whose signature is valid
+func (c *ordererConfig) verifySignatures(block *common.Block) error {
+	md, err := protoutil.GetMetadataFromBlock(block, common.BlockMetadataIndex_SIGNATURES)
+	if err != nil {
+		return err
+	}
+
+	headerBytes := protoutil.BlockHeaderBytes(block.Header)
+	var identities []msp.Identity
+	var invalid []string
+	seen := make(map[string]bool)
+	for i, signature := range md.Signatures {
+		identity, err := c.verifySignature(md.Value, signature, headerBytes)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("signature %d: %s", i, err))
+			continue
+		}
+
+		// an orderer signing several times counts once
+		id := identity.GetIdentifier()
+		key := id.Mspid + "/" + id.Id
+		if !seen[key] {
+			seen[key] = true
+			identities = append(identities, identity)
+		}
+	}
+
+	if err := policyeval.EvaluateConfigPolicy(c.group, blockValidationPolicy, identities); err != nil {
+		if len(invalid) > 0 {
+			return errors.Errorf("%s, invalid signatures: [%s]", err, strings.Join(invalid, "; "))
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *ordererConfig) verifySignature(value []byte, signature *common.MetadataSignature, headerBytes []byte) (msp.Identity, error) {
+	shdr, err := protoutil.UnmarshalSignatureHeader(signature.SignatureHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := c.deserializer.DeserializeIdentity(shdr.Creator)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to deserialize the orderer identity")
+	}
+	if err := identity.Validate(); err != nil {
+		return nil, errors.WithMessagef(err, "the identity of orderer %s is not valid", identity.GetMSPIdentifier())
+	}
+
+	data := append(append(append([]byte{}, value...), signature.SignatureHeader...), headerBytes...)
+	if err := identity.Verify(data, signature.Signature); err != nil {
+		return nil, errors.WithMessagef(err, "the signature of orderer %s is not valid", identity.GetMSPIdentifier())
+	}
+	return identity, nil
+}
+
+// configFromBlock returns the channel config of a config block, or nil if the block is not a config block
+func configFromBlock(block *common.Block) (*common.Config, error) {
+	if len(block.GetData().GetData()) != 1 {
+		return nil, nil
+	}
+
+	envelope, err := protoutil.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, nil
+	}
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil || payload.Header == nil {
+		return nil, nil
+	}
+	chdr, err := protoutil.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil || common.HeaderType(chdr.Type) != common.HeaderType_CONFIG {
+		return nil, nil
+	}
+
+	configEnvelope := &common.ConfigEnvelope{}
+	if err := proto.Unmarshal(payload.Data, configEnvelope); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal config envelope")
+	}
+	if configEnvelope.Config == nil {
+		return nil, errors.New("config envelope does not contain a config")
+	}
+	return configEnvelope.Config, nil
+}
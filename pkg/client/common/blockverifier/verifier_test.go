@@ -0,0 +1,97 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockverifier
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestVerifyBlocks(t *testing.T) {
+	cs, err := sw.GetSuiteWithDefaultEphemeral()
+	require.NoError(t, err)
+
+	org1 := mocks.NewMockOrdererOrg("Orderer1MSP")
+	org2 := mocks.NewMockOrdererOrg("Orderer2MSP")
+	unknown := mocks.NewMockOrdererOrg("Orderer1MSP")
+
+	genesis := mocks.NewMockOrdererConfigBlock("mychannel", nil, common.ImplicitMetaPolicy_ANY, org1)
+
+	newChain := func() []*common.Block {
+		block1 := mocks.NewMockChainedBlock(genesis.Header, []byte("tx1"))
+		mocks.SignMockBlock(block1, 0, org1)
+		// the config update requires the signatures of both orderer organizations from the next block on
+		block2 := mocks.NewMockOrdererConfigBlock("mychannel", block1.Header, common.ImplicitMetaPolicy_ALL, org1, org2)
+		mocks.SignMockBlock(block2, 2, org1)
+		block3 := mocks.NewMockChainedBlock(block2.Header, []byte("tx3"))
+		mocks.SignMockBlock(block3, 2, org1, org2)
+		return []*common.Block{block1, block2, block3}
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, VerifyBlocks(genesis, append([]*common.Block{genesis}, newChain()...), cs))
+		assert.NoError(t, VerifyBlocks(genesis, newChain(), cs), "expected verification to start after the config block")
+	})
+
+	t.Run("tampered data", func(t *testing.T) {
+		blocks := newChain()
+		blocks[2].Data.Data[0] = []byte("tampered")
+		assertVerificationError(t, VerifyBlocks(genesis, blocks, cs), 3, DataHashCheck)
+	})
+
+	t.Run("broken hash chain", func(t *testing.T) {
+		blocks := newChain()
+		blocks[1].Header.PreviousHash = []byte("other")
+		mocks.SignMockBlock(blocks[1], 2, org1)
+		assertVerificationError(t, VerifyBlocks(genesis, blocks, cs), 2, PreviousHashCheck)
+
+		blocks = newChain()
+		assertVerificationError(t, VerifyBlocks(genesis, []*common.Block{blocks[0], blocks[2]}, cs), 3, SequenceCheck)
+	})
+
+	t.Run("invalid signatures", func(t *testing.T) {
+		blocks := newChain()
+		mocks.SignMockBlock(blocks[0], 0, unknown)
+		err := VerifyBlocks(genesis, blocks, cs)
+		assertVerificationError(t, err, 1, SignatureCheck)
+		assert.Contains(t, err.Error(), "invalid signatures")
+
+		blocks = newChain()
+		blocks[0].Header.DataHash = protoutil.BlockDataHash(&common.BlockData{Data: [][]byte{[]byte("other")}})
+		blocks[0].Data.Data = [][]byte{[]byte("other")}
+		assertVerificationError(t, VerifyBlocks(genesis, blocks, cs), 1, SignatureCheck)
+	})
+
+	t.Run("policy of config in effect", func(t *testing.T) {
+		blocks := newChain()
+		mocks.SignMockBlock(blocks[2], 2, org1)
+		err := VerifyBlocks(genesis, blocks, cs)
+		assertVerificationError(t, err, 3, SignatureCheck)
+		assert.Contains(t, err.Error(), "requires ALL Writers, satisfied by 1 of 2")
+
+		mocks.SignMockBlock(blocks[2], 2, org2, org1, org1)
+		assert.NoError(t, VerifyBlocks(genesis, blocks, cs))
+	})
+
+	_, err = New(newChain()[0], cs)
+	assert.Error(t, err, "expected error for block which is not a config block")
+}
+
+func assertVerificationError(t *testing.T, err error, blockNumber uint64, check Check) {
+	require.Error(t, err)
+	verr, ok := err.(*VerificationError)
+	require.Truef(t, ok, "expected verification error but got %s", err)
+	assert.Equal(t, blockNumber, verr.BlockNumber)
+	assert.Equal(t, check, verr.Check)
+}
@@ -0,0 +1,40 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filter
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// NewMSPFilter creates a new filter that only accepts the peers of the given organizations.
+// If a base filter is provided, peers must also be accepted by the base filter.
+func NewMSPFilter(base fab.TargetFilter, mspIDs ...string) *MSPFilter {
+	ids := make(map[string]bool, len(mspIDs))
+	for _, mspID := range mspIDs {
+		ids[mspID] = true
+	}
+	return &MSPFilter{base: base, mspIDs: ids}
+}
+
+// MSPFilter filters peers based on the MSP ID of their organization
+type MSPFilter struct {
+	base   fab.TargetFilter
+	mspIDs map[string]bool
+}
+
+// Accept returns false if this peer is to be excluded from the target list
+func (f *MSPFilter) Accept(peer fab.Peer) bool {
+	if !f.mspIDs[peer.MSPID()] {
+		return false
+	}
+
+	if f.base != nil {
+		return f.base.Accept(peer)
+	}
+
+	return true
+}
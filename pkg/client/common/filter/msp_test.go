@@ -0,0 +1,66 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+package filter
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+type rejectFilter struct{}
+
+func (f *rejectFilter) Accept(peer fab.Peer) bool {
+	return false
+}
+
+func TestMSPFilter(t *testing.T) {
+
+	peer1 := mocks.NewMockPeer("Peer1", "peer1.org1.example.com")
+	peer1.MockMSP = "Org1MSP"
+	peer2 := mocks.NewMockPeer("Peer2", "peer1.org2.example.com")
+	peer2.MockMSP = "Org2MSP"
+	peer3 := mocks.NewMockPeer("Peer3", "peer1.org3.example.com")
+	peer3.MockMSP = "Org3MSP"
+
+	mf := NewMSPFilter(nil, "Org1MSP", "Org3MSP")
+
+	if !mf.Accept(peer1) {
+		t.Fatal("Should have accepted peer of Org1MSP")
+	}
+
+	if mf.Accept(peer2) {
+		t.Fatal("Should NOT have accepted peer of Org2MSP")
+	}
+
+	if !mf.Accept(peer3) {
+		t.Fatal("Should have accepted peer of Org3MSP")
+	}
+
+	if NewMSPFilter(nil).Accept(peer1) {
+		t.Fatal("Should NOT have accepted peer without organizations")
+	}
+}
+
+func TestMSPFilterWithBase(t *testing.T) {
+
+	peer := mocks.NewMockPeer("Peer1", "peer1.org1.example.com")
+	peer.MockMSP = "Org1MSP"
+
+	if NewMSPFilter(&rejectFilter{}, "Org1MSP").Accept(peer) {
+		t.Fatal("Should NOT have accepted peer rejected by base filter")
+	}
+
+	channel, err := mocks.NewMockChannel(channelID)
+	if err != nil {
+		t.Fatalf("Failed to create mock channel: %s", err)
+	}
+
+	if !NewMSPFilter(NewEndpointFilter(channel, EndorsingPeer), "Org1MSP").Accept(peer) {
+		t.Fatal("Should have accepted peer")
+	}
+}
@@ -26,7 +26,7 @@ type MockTransactor struct {
 
 // CreateTransactionHeader creates a Transaction Header based on the current context.
 func (t *MockTransactor) CreateTransactionHeader(opts ...fab.TxnHeaderOpt) (fab.TransactionHeader, error) {
-	txh, err := txn.NewHeader(t.Ctx, t.ChannelID)
+	txh, err := txn.NewHeader(t.Ctx, t.ChannelID, opts...)
 	if err != nil {
 		return nil, errors.WithMessage(err, "new transaction ID failed")
 	}
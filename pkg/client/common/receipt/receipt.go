@@ -0,0 +1,132 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package receipt assembles self-contained receipts of committed transactions, which prove to third parties
+// that a transaction was committed in a given block, and verifies them offline against the channel config.
+// The validation code of a transaction is set by the committing peers and is not signed, so a receipt proves
+// the content and position of the transaction but not whether the peers found it valid.
+package receipt
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/sdkinternal/pkg/txflags"
+	"github.com/pkg/errors"
+)
+
+// Receipt proves that a transaction was committed in a block. The block data is included in full since the
+// data hash of the block header, which is signed by the orderers, is computed over the data of all the
+// transactions of the block.
+type Receipt struct {
+	ChannelID string `json:"channel_id"`
+	TxID      string `json:"tx_id"`
+	// TxIndex is the position of the transaction in the block
+	TxIndex     int                 `json:"tx_index"`
+	BlockHeader *common.BlockHeader `json:"block_header"`
+	// BlockData are the envelopes of the transactions of the block. The envelope at TxIndex is the envelope
+	// of the transaction, which contains its endorsements.
+	BlockData [][]byte `json:"block_data"`
+	// ValidationCode is the validation code of the transaction from the block metadata. It is set by the
+	// committing peers and is not covered by the orderer signatures.
+	ValidationCode pb.TxValidationCode `json:"validation_code"`
+	// OrdererSignatures is the signatures metadata of the block, which contains the orderer signatures of the
+	// block header and the number of the config block in effect at the block
+	OrdererSignatures []byte `json:"orderer_signatures"`
+}
+
+// New assembles the receipt of a transaction from the committed block which contains it
+//  Parameters:
+//  block is the committed block
+//  txID is the ID of the transaction
+//
+//  Returns:
+//  the receipt of the transaction
+func New(block *common.Block, txID string) (*Receipt, error) {
+	if block.GetHeader() == nil {
+		return nil, errors.New("block header is missing")
+	}
+	metadata := block.GetMetadata().GetMetadata()
+	if len(metadata) <= int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		return nil, errors.Errorf("block %d does not contain the validation codes of its transactions", block.Header.Number)
+	}
+
+	for i := range block.GetData().GetData() {
+		chdr, err := channelHeader(block.Data.Data[i])
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to read transaction %d of block %d", i, block.Header.Number)
+		}
+		if chdr.TxId != txID {
+			continue
+		}
+
+		flags := txflags.ValidationFlags(metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+		if i >= len(flags) {
+			return nil, errors.Errorf("block %d does not contain the validation code of transaction %s", block.Header.Number, txID)
+		}
+
+		return &Receipt{
+			ChannelID:         chdr.ChannelId,
+			TxID:              txID,
+			TxIndex:           i,
+			BlockHeader:       block.Header,
+			BlockData:         block.Data.Data,
+			ValidationCode:    flags.Flag(i),
+			OrdererSignatures: metadata[common.BlockMetadataIndex_SIGNATURES],
+		}, nil
+	}
+
+	return nil, errors.Errorf("transaction %s is not in block %d", txID, block.Header.Number)
+}
+
+// Envelope returns the envelope of the transaction
+func (r *Receipt) Envelope() (*common.Envelope, error) {
+	if r.TxIndex < 0 || r.TxIndex >= len(r.BlockData) {
+		return nil, errors.Errorf("transaction index %d is out of range of the %d transactions of the block", r.TxIndex, len(r.BlockData))
+	}
+	return protoutil.GetEnvelopeFromBlock(r.BlockData[r.TxIndex])
+}
+
+// LastConfig returns the number of the config block in effect at the block of the transaction, which is the
+// config block required to verify the receipt
+func (r *Receipt) LastConfig() (uint64, error) {
+	md := &common.Metadata{}
+	if err := proto.Unmarshal(r.OrdererSignatures, md); err != nil {
+		return 0, errors.Wrap(err, "failed to unmarshal the signatures metadata")
+	}
+	obm := &common.OrdererBlockMetadata{}
+	if err := proto.Unmarshal(md.Value, obm); err != nil {
+		return 0, errors.Wrap(err, "failed to unmarshal the orderer block metadata")
+	}
+	return obm.GetLastConfig().GetIndex(), nil
+}
+
+// block returns the block of the transaction, with the metadata included in the receipt
+func (r *Receipt) block() *common.Block {
+	metadata := make([][]byte, len(common.BlockMetadataIndex_name))
+	metadata[common.BlockMetadataIndex_SIGNATURES] = r.OrdererSignatures
+	return &common.Block{
+		Header:   r.BlockHeader,
+		Data:     &common.BlockData{Data: r.BlockData},
+		Metadata: &common.BlockMetadata{Metadata: metadata},
+	}
+}
+
+func channelHeader(data []byte) (*common.ChannelHeader, error) {
+	envelope, err := protoutil.GetEnvelopeFromBlock(data)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if payload.Header == nil {
+		return nil, errors.New("payload header is missing")
+	}
+	return protoutil.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package receipt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/blockverifier"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+const channelID = "mychannel"
+
+func TestReceipt(t *testing.T) {
+	cs, err := sw.GetSuiteWithDefaultEphemeral()
+	require.NoError(t, err)
+
+	orderer := mocks.NewMockOrdererOrg("OrdererMSP")
+	org1 := mocks.NewMockOrdererOrg("Org1MSP")
+	org2 := mocks.NewMockOrdererOrg("Org2MSP")
+	unknown := mocks.NewMockOrdererOrg("Org2MSP")
+
+	genesis := mocks.NewMockChannelConfigBlock(channelID, nil, common.ImplicitMetaPolicy_ANY, []*mocks.MockOrdererOrg{orderer}, org1, org2)
+	verifier, err := NewVerifier(genesis, cs)
+	require.NoError(t, err)
+
+	newBlock := func(txs ...[]byte) *common.Block {
+		block := mocks.NewMockChainedBlock(genesis.Header, txs...)
+		flags := make([]byte, len(txs))
+		flags[len(txs)-1] = byte(pb.TxValidationCode_MVCC_READ_CONFLICT)
+		block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = flags
+		mocks.SignMockBlock(block, 0, orderer)
+		return block
+	}
+
+	tx1, txID1 := mocks.NewMockEndorserTransaction(channelID, org1, org1, org2)
+	tx2, txID2 := mocks.NewMockEndorserTransaction(channelID, org2, org2)
+	block := newBlock(tx1, tx2)
+
+	t.Run("valid", func(t *testing.T) {
+		r, err := New(block, txID1)
+		require.NoError(t, err)
+		assert.Equal(t, channelID, r.ChannelID)
+		assert.Equal(t, 0, r.TxIndex)
+		assert.Equal(t, pb.TxValidationCode_VALID, r.ValidationCode)
+
+		tx, err := verifier.Verify(r)
+		require.NoError(t, err)
+		assert.Equal(t, txID1, tx.TxID)
+		assert.Empty(t, tx.ValidationCode, "expected validation code not to be reported as verified")
+		assert.Equal(t, pb.TxValidationCode_VALID, tx.UnverifiedValidationCode)
+		assert.Equal(t, "Org1MSP", tx.Creator.MSPID)
+		require.Len(t, tx.Actions, 1)
+		assert.Len(t, tx.Actions[0].Endorsements, 2)
+		assert.Equal(t, []byte("result"), tx.Actions[0].Response.Payload)
+
+		r, err = New(block, txID2)
+		require.NoError(t, err)
+		receiptBytes, err := json.Marshal(r)
+		require.NoError(t, err)
+		unmarshalled := &Receipt{}
+		require.NoError(t, json.Unmarshal(receiptBytes, unmarshalled))
+		tx, err = verifier.Verify(unmarshalled)
+		require.NoError(t, err, "expected receipt to be verified after JSON round trip")
+		assert.Equal(t, 1, tx.Index)
+		assert.Equal(t, pb.TxValidationCode_MVCC_READ_CONFLICT, tx.UnverifiedValidationCode)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		r, err := New(block, txID1)
+		require.NoError(t, err)
+		r.BlockData = [][]byte{tx1}
+		_, err = verifier.Verify(r)
+		require.Error(t, err)
+		verr, ok := err.(*blockverifier.VerificationError)
+		require.Truef(t, ok, "expected verification error but got %s", err)
+		assert.Equal(t, blockverifier.DataHashCheck, verr.Check)
+
+		r, err = New(block, txID1)
+		require.NoError(t, err)
+		r.TxIndex = 1
+		_, err = verifier.Verify(r)
+		assert.Error(t, err, "expected error for index of another transaction")
+	})
+
+	t.Run("invalid signatures", func(t *testing.T) {
+		signed := newBlock(tx1)
+		mocks.SignMockBlock(signed, 0, unknown)
+		r, err := New(signed, txID1)
+		require.NoError(t, err)
+		_, err = verifier.Verify(r)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed orderer signature verification")
+
+		tx, txID := mocks.NewMockEndorserTransaction(channelID, org1, org1, unknown)
+		r, err = New(newBlock(tx), txID)
+		require.NoError(t, err)
+		_, err = verifier.Verify(r)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid endorsement 1 of action 0")
+	})
+
+	t.Run("config", func(t *testing.T) {
+		signed := newBlock(tx1)
+		mocks.SignMockBlock(signed, 5, orderer)
+		r, err := New(signed, txID1)
+		require.NoError(t, err)
+		lastConfig, err := r.LastConfig()
+		require.NoError(t, err)
+		assert.EqualValues(t, 5, lastConfig)
+		_, err = verifier.Verify(r)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires config block 5")
+
+		other, err := NewVerifier(mocks.NewMockChannelConfigBlock("other", nil, common.ImplicitMetaPolicy_ANY, []*mocks.MockOrdererOrg{orderer}, org1), cs)
+		require.NoError(t, err)
+		r, err = New(block, txID1)
+		require.NoError(t, err)
+		_, err = other.Verify(r)
+		assert.Error(t, err, "expected error for config of another channel")
+
+		_, err = NewVerifier(block, cs)
+		assert.Error(t, err, "expected error for block which is not a config block")
+	})
+
+	_, err = New(block, "unknown")
+	assert.Error(t, err, "expected error for transaction which is not in the block")
+}
@@ -0,0 +1,205 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package receipt
+
+import (
+	"bytes"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/blockdecoder"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/blockverifier"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/channel/membership"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/chconfig"
+	"github.com/pkg/errors"
+)
+
+// Verifier verifies receipts offline, given only the config block of the channel in effect at the blocks of the
+// receipts. A receipt is valid if:
+//  - the data hash of the block header is the hash of the block data
+//  - the orderer signatures of the block header satisfy the BlockValidation policy of the config
+//  - the transaction at the index of the receipt has the ID and channel of the receipt
+//  - the creator and endorsement signatures of an endorser transaction are valid signatures of members
+//    of the channel organizations
+// The endorsement policy of the chaincode is not part of the channel config and is not evaluated. The validation
+// code, which reports whether the committing peers found the transaction valid, is not covered by any signature
+// and is returned separately as unverified.
+type Verifier struct {
+	channelID   string
+	configBlock *common.Block
+	cs          core.CryptoSuite
+	mspManager  msp.MSPManager
+}
+
+// VerifiedTransaction is the outcome of the verification of a receipt
+type VerifiedTransaction struct {
+	// Transaction is the decoded transaction, whose content and index in the block are verified. Its
+	// ValidationCode is not set, since the validation code cannot be verified.
+	*blockdecoder.Transaction
+	// UnverifiedValidationCode is the validation code of the transaction as claimed by the receipt. The validation
+	// code is set by the committing peers and is not covered by the orderer signatures, so anyone may change it;
+	// it must only be relied upon if the peer which created the receipt is trusted.
+	UnverifiedValidationCode pb.TxValidationCode
+}
+
+// NewVerifier returns a verifier of the receipts of the blocks whose config is in effect at the given config block
+//  Parameters:
+//  configBlock is the config block in effect at the blocks of the receipts, which is trusted
+//  cs is the crypto suite used to verify signatures
+//
+//  Returns:
+//  the receipt verifier
+func NewVerifier(configBlock *common.Block, cs core.CryptoSuite) (*Verifier, error) {
+	// loading a block verifier makes sure that the block is a config block with a valid orderer config
+	if _, err := blockverifier.New(configBlock, cs); err != nil {
+		return nil, err
+	}
+
+	channelID, err := protoutil.GetChannelIDFromBlock(configBlock)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get the channel ID of the config block")
+	}
+
+	cfg, err := chconfig.FromBlock(channelID, configBlock)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to load the channel config")
+	}
+
+	mspManager, err := membership.NewMSPManager(cfg, cs)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create the MSP manager of the channel organizations")
+	}
+
+	return &Verifier{channelID: channelID, configBlock: configBlock, cs: cs, mspManager: mspManager}, nil
+}
+
+// Verify verifies a receipt
+//  Parameters:
+//  r is the receipt to verify
+//
+//  Returns:
+//  the decoded transaction of the receipt with its index, and the unverified validation code of the receipt
+func (v *Verifier) Verify(r *Receipt) (*VerifiedTransaction, error) {
+	if r.BlockHeader == nil {
+		return nil, errors.New("block header is missing")
+	}
+	if r.ChannelID != v.channelID {
+		return nil, errors.Errorf("receipt is for channel %s but the config is of channel %s", r.ChannelID, v.channelID)
+	}
+
+	if err := v.verifyBlock(r); err != nil {
+		return nil, err
+	}
+
+	envelope, err := r.Envelope()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := blockdecoder.DecodeEnvelope(envelope)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to decode the transaction")
+	}
+	if tx.TxID != r.TxID || tx.ChannelID != r.ChannelID {
+		return nil, errors.Errorf("transaction %d of block %d is transaction %s of channel %s", r.TxIndex, r.BlockHeader.Number, tx.TxID, tx.ChannelID)
+	}
+
+	if tx.Type == common.HeaderType_ENDORSER_TRANSACTION.String() {
+		if err := v.verifyTransaction(envelope); err != nil {
+			return nil, errors.WithMessagef(err, "transaction %s failed verification", r.TxID)
+		}
+	}
+
+	tx.Index = r.TxIndex
+	return &VerifiedTransaction{Transaction: tx, UnverifiedValidationCode: r.ValidationCode}, nil
+}
+
+// verifyBlock verifies the block header and data of the receipt
+func (v *Verifier) verifyBlock(r *Receipt) error {
+	block := r.block()
+
+	// a receipt of the transaction of the config block itself is verified against the trusted config block,
+	// since the block is signed according to the previous config
+	if r.BlockHeader.Number == v.configBlock.Header.Number {
+		if !bytes.Equal(protoutil.BlockHeaderHash(r.BlockHeader), protoutil.BlockHeaderHash(v.configBlock.Header)) {
+			return errors.Errorf("block %d is not the config block", r.BlockHeader.Number)
+		}
+		if !bytes.Equal(r.BlockHeader.DataHash, protoutil.BlockDataHash(block.Data)) {
+			return &blockverifier.VerificationError{BlockNumber: r.BlockHeader.Number, Check: blockverifier.DataHashCheck,
+				Reason: "data hash does not match the block data"}
+		}
+		return nil
+	}
+
+	lastConfig, err := r.LastConfig()
+	if err != nil {
+		return err
+	}
+	if lastConfig != v.configBlock.Header.Number {
+		return errors.Errorf("block %d requires config block %d, but config block %d was given", r.BlockHeader.Number, lastConfig, v.configBlock.Header.Number)
+	}
+
+	verifier, err := blockverifier.New(v.configBlock, v.cs)
+	if err != nil {
+		return err
+	}
+	return verifier.Verify(block)
+}
+
+// verifyTransaction verifies the creator signature and the endorsement signatures of an endorser transaction
+func (v *Verifier) verifyTransaction(envelope *common.Envelope) error {
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return err
+	}
+	shdr, err := protoutil.UnmarshalSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		return err
+	}
+	if err := v.verifySignature(shdr.Creator, envelope.Payload, envelope.Signature); err != nil {
+		return errors.WithMessage(err, "invalid creator signature")
+	}
+
+	transaction, err := protoutil.UnmarshalTransaction(payload.Data)
+	if err != nil {
+		return err
+	}
+	for i, action := range transaction.Actions {
+		ccActionPayload, err := protoutil.UnmarshalChaincodeActionPayload(action.Payload)
+		if err != nil {
+			return err
+		}
+		if ccActionPayload.Action == nil || len(ccActionPayload.Action.Endorsements) == 0 {
+			return errors.Errorf("action %d is not endorsed", i)
+		}
+
+		prp := ccActionPayload.Action.ProposalResponsePayload
+		for j, endorsement := range ccActionPayload.Action.Endorsements {
+			data := append(append([]byte{}, prp...), endorsement.Endorser...)
+			if err := v.verifySignature(endorsement.Endorser, data, endorsement.Signature); err != nil {
+				return errors.WithMessagef(err, "invalid endorsement %d of action %d", j, i)
+			}
+		}
+	}
+	return nil
+}
+
+func (v *Verifier) verifySignature(serializedIdentity, data, signature []byte) error {
+	identity, err := v.mspManager.DeserializeIdentity(serializedIdentity)
+	if err != nil {
+		return errors.WithMessage(err, "failed to deserialize the identity")
+	}
+	if err := identity.Validate(); err != nil {
+		return errors.WithMessagef(err, "the identity of %s is not valid", identity.GetMSPIdentifier())
+	}
+	if err := identity.Verify(data, signature); err != nil {
+		return errors.WithMessagef(err, "the signature of %s is not valid", identity.GetMSPIdentifier())
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package policyeval evaluates signature and implicit meta policies against a set of identities in the same
+// way as the peers. It is shared by the clients which check policies locally.
+package policyeval
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	mb "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
+)
+
+// EvaluateSignaturePolicy evaluates a signature policy against the identities, where each identity may be used
+// to satisfy a single principal of the policy.
+//  Returns:
+//  true if the policy is satisfied, otherwise false and a description of the missing principals
+func EvaluateSignaturePolicy(envelope *common.SignaturePolicyEnvelope, identities []msp.Identity) (bool, string) {
+	evaluator := &signaturePolicyEvaluator{principals: envelope.Identities, identities: identities}
+	return evaluator.evaluate(envelope.Rule, make([]bool, len(identities)))
+}
+
+// EvaluateConfigPolicy evaluates the policy of a config group against the identities. Implicit meta policies
+// are evaluated against the policies of the sub-groups.
+//  Returns:
+//  an error describing why the policy is not satisfied
+func EvaluateConfigPolicy(group *common.ConfigGroup, name string, identities []msp.Identity) error {
+	configPolicy := group.Policies[name]
+	if configPolicy == nil || configPolicy.Policy == nil {
+		return errors.Errorf("policy %s is not defined", name)
+	}
+
+	switch common.Policy_PolicyType(configPolicy.Policy.Type) {
+	case common.Policy_SIGNATURE:
+		envelope := &common.SignaturePolicyEnvelope{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, envelope); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal signature policy %s", name)
+		}
+		if ok, missing := EvaluateSignaturePolicy(envelope, identities); !ok {
+			return errors.Errorf("policy %s requires %s", name, missing)
+		}
+		return nil
+
+	case common.Policy_IMPLICIT_META:
+		implicitMeta := &common.ImplicitMetaPolicy{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, implicitMeta); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal implicit meta policy %s", name)
+		}
+		return evaluateImplicitMeta(group, name, implicitMeta, identities)
+
+	default:
+		return errors.Errorf("policy %s has unsupported type %s", name, common.Policy_PolicyType(configPolicy.Policy.Type))
+	}
+}
+
+func evaluateImplicitMeta(group *common.ConfigGroup, name string, policy *common.ImplicitMetaPolicy, identities []msp.Identity) error {
+	var subGroups []string
+	for subGroup := range group.Groups {
+		subGroups = append(subGroups, subGroup)
+	}
+	sort.Strings(subGroups)
+
+	var threshold int
+	switch policy.Rule {
+	case common.ImplicitMetaPolicy_ANY:
+		threshold = 1
+	case common.ImplicitMetaPolicy_ALL:
+		threshold = len(subGroups)
+	case common.ImplicitMetaPolicy_MAJORITY:
+		threshold = len(subGroups)/2 + 1
+	}
+	// as for the peers, no sub-policies satisfy any rule
+	if len(subGroups) == 0 {
+		threshold = 0
+	}
+
+	satisfied := 0
+	var failures []string
+	for _, subGroup := range subGroups {
+		if err := EvaluateConfigPolicy(group.Groups[subGroup], policy.SubPolicy, identities); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", subGroup, err))
+			continue
+		}
+		satisfied++
+	}
+
+	if satisfied < threshold {
+		return errors.Errorf("policy %s requires %s %s, satisfied by %d of %d: [%s]", name, policy.Rule, policy.SubPolicy,
+			satisfied, len(subGroups), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// signaturePolicyEvaluator evaluates a signature policy, where each identity may be used to satisfy a single
+// principal of the policy
+type signaturePolicyEvaluator struct {
+	principals []*mb.MSPPrincipal
+	identities []msp.Identity
+}
+
+// evaluate returns true if the policy is satisfied by the identities that are not yet used, marking the
+// identities that satisfy the policy as used. Otherwise it returns a description of the missing principals.
+func (e *signaturePolicyEvaluator) evaluate(policy *common.SignaturePolicy, used []bool) (bool, string) {
+	switch t := policy.GetType().(type) {
+	case *common.SignaturePolicy_SignedBy:
+		if t.SignedBy < 0 || int(t.SignedBy) >= len(e.principals) {
+			return false, fmt.Sprintf("invalid principal index %d", t.SignedBy)
+		}
+
+		principal := e.principals[t.SignedBy]
+		for i, identity := range e.identities {
+			if used[i] {
+				continue
+			}
+			if err := identity.SatisfiesPrincipal(principal); err == nil {
+				used[i] = true
+				return true, ""
+			}
+		}
+		return false, principalString(principal)
+
+	case *common.SignaturePolicy_NOutOf_:
+		verified := 0
+		var missing []string
+		ruleUsed := make([]bool, len(used))
+		for _, rule := range t.NOutOf.Rules {
+			copy(ruleUsed, used)
+			ok, m := e.evaluate(rule, ruleUsed)
+			if ok {
+				verified++
+				copy(used, ruleUsed)
+			} else {
+				missing = append(missing, m)
+			}
+		}
+
+		if verified >= int(t.NOutOf.N) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("%d more of [%s]", int(t.NOutOf.N)-verified, strings.Join(missing, ", "))
+
+	default:
+		return false, fmt.Sprintf("unsupported policy type %T", t)
+	}
+}
+
+// principalString returns a readable representation of a principal, e.g. 'Org1MSP.peer'
+func principalString(principal *mb.MSPPrincipal) string {
+	switch principal.PrincipalClassification {
+	case mb.MSPPrincipal_ROLE:
+		role := &mb.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err == nil {
+			return fmt.Sprintf("'%s.%s'", role.MspIdentifier, strings.ToLower(role.Role.String()))
+		}
+	case mb.MSPPrincipal_ORGANIZATION_UNIT:
+		ou := &mb.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err == nil {
+			return fmt.Sprintf("'%s.OU(%s)'", ou.MspIdentifier, ou.OrganizationalUnitIdentifier)
+		}
+	}
+	return fmt.Sprintf("'%s principal'", principal.PrincipalClassification)
+}
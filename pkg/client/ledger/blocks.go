@@ -0,0 +1,325 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	reqContext "context"
+	"math"
+	"strconv"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	ab "github.com/hyperledger/fabric-protos-go/orderer"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/api"
+	clientdisp "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/client/dispatcher"
+	deliverconn "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/connection"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+// blockBufferSize is the number of delivered blocks buffered ahead of the consumer of the iterator
+const blockBufferSize = 100
+
+// BlockContent is the content of the blocks delivered by Blocks
+type BlockContent int
+
+const (
+	// FullBlocks delivers complete blocks
+	FullBlocks BlockContent = iota
+	// FilteredBlocks delivers filtered blocks, which contain the IDs, types and validation codes of the transactions
+	FilteredBlocks
+	// HeaderOnlyBlocks delivers blocks with their header and metadata but without their transactions
+	HeaderOnlyBlocks
+)
+
+// DeliveredBlock is a block delivered by Blocks
+type DeliveredBlock struct {
+	// Number is the number of the block
+	Number uint64
+	// Block is set if FullBlocks or HeaderOnlyBlocks are delivered
+	Block *common.Block
+	// FilteredBlock is set if FilteredBlocks are delivered
+	FilteredBlock *pb.FilteredBlock
+	// SourceURL is the URL of the peer which delivered the block
+	SourceURL string
+}
+
+// deliverConnection is a connection to the deliver service of a peer
+type deliverConnection interface {
+	api.Connection
+	Send(seekInfo *ab.SeekInfo) error
+}
+
+// deliverProvider connects to the deliver service of a peer
+var deliverProvider = func(ctx context.Client, chConfig fab.ChannelCfg, peer fab.Peer, filtered bool) (deliverConnection, error) {
+	var opts []options.Opt
+	if eventEndpoint, ok := peer.(api.EventEndpoint); ok {
+		opts = eventEndpoint.Opts()
+	} else if peerConfig, ok := ctx.EndpointConfig().PeerConfig(peer.URL()); ok {
+		opts = comm.OptsFromPeerConfig(peerConfig)
+	}
+
+	streamProvider := deliverconn.Deliver
+	if filtered {
+		streamProvider = deliverconn.DeliverFiltered
+	}
+	return deliverconn.New(ctx, chConfig, streamProvider, peer.URL(), opts...)
+}
+
+// Blocks streams the blocks from fromBlock to toBlock, inclusive, from the deliver service of the peers.
+// The blocks are delivered in order, with the content requested using WithBlockContent. If a peer fails or
+// doesn't have a block of the range yet, the stream fails over to the next peer from the first block which
+// hasn't been delivered, so that no block is duplicated or skipped. The stream fails if none of the peers is
+// able to deliver the next block. All the peers selected by WithTargets or by the target filter are used for
+// failover, WithMinTargets and WithMaxTargets are ignored. Cancelling the context given with WithParentContext
+// stops the stream.
+//  Parameters:
+//  fromBlock is the number of the first block
+//  toBlock is the number of the last block
+//  options holds optional request options
+//
+//  Returns:
+//  an iterator over the blocks, which must be closed when it is no longer used
+func (c *Client) Blocks(fromBlock, toBlock uint64, options ...RequestOption) (*BlockIterator, error) {
+	if fromBlock > toBlock || toBlock == math.MaxUint64 {
+		return nil, errors.Errorf("invalid block range [%d, %d]", fromBlock, toBlock)
+	}
+
+	opts, err := c.prepareRequestOpts(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get opts")
+	}
+
+	opts.MinTargets = minTargets
+	opts.MaxTargets = math.MaxInt32
+	targets, err := c.calculateTargets(opts)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to determine target peers")
+	}
+
+	chConfig, err := c.ctx.ChannelService().ChannelConfig()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get channel config")
+	}
+
+	parent := opts.ParentContext
+	if parent == nil {
+		parent = reqContext.Background()
+	}
+	if tracer := c.ctx.Tracer(); tracer != nil {
+		parent = tracing.ContextWithTracer(parent, tracer)
+	}
+	ctx, cancel := reqContext.WithCancel(parent)
+
+	it := &BlockIterator{
+		client:   c.ctx,
+		chConfig: chConfig,
+		peers:    targets,
+		content:  opts.BlockContent,
+		next:     fromBlock,
+		to:       toBlock,
+		blocks:   make(chan *DeliveredBlock, blockBufferSize),
+		done:     make(chan struct{}),
+		cancel:   cancel,
+	}
+	go it.run(ctx, parent)
+
+	return it, nil
+}
+
+// BlockIterator iterates over the blocks streamed by Blocks
+type BlockIterator struct {
+	client   context.Client
+	chConfig fab.ChannelCfg
+	peers    []fab.Peer
+	content  BlockContent
+	next     uint64
+	to       uint64
+	blocks   chan *DeliveredBlock
+	done     chan struct{}
+	cancel   reqContext.CancelFunc
+	current  *DeliveredBlock
+	err      error
+}
+
+// Next waits for the next block. It returns false once all the blocks were delivered, or if the stream
+// failed or was stopped, in which case Err returns the reason.
+func (it *BlockIterator) Next() bool {
+	block, ok := <-it.blocks
+	if !ok {
+		return false
+	}
+	it.current = block
+	return true
+}
+
+// Block returns the block of the last successful call to Next
+func (it *BlockIterator) Block() *DeliveredBlock {
+	return it.current
+}
+
+// Err returns the error which stopped the stream, once Next returned false. It returns nil if all the blocks
+// were delivered or if the iterator was closed.
+func (it *BlockIterator) Err() error {
+	select {
+	case <-it.done:
+		return it.err
+	default:
+		return nil
+	}
+}
+
+// Close stops the stream and waits for the connection to the peer to be closed
+func (it *BlockIterator) Close() {
+	it.cancel()
+	<-it.done
+}
+
+func (it *BlockIterator) run(ctx, parent reqContext.Context) {
+	// done is closed before blocks, so that Err returns the error as soon as Next returns false
+	defer close(it.blocks)
+	defer close(it.done)
+
+	_, span := tracing.StartSpan(ctx, "ledger.Blocks",
+		tracing.String(tracing.ChannelKey, it.chConfig.ID()),
+		tracing.String("fabric.block.from", strconv.FormatUint(it.next, 10)),
+		tracing.String("fabric.block.to", strconv.FormatUint(it.to, 10)))
+
+	var err error
+	failures := 0
+	for i := 0; it.next <= it.to; i++ {
+		peer := it.peers[i%len(it.peers)]
+
+		var delivered int
+		delivered, err = it.deliverFrom(ctx, peer)
+		if ctx.Err() != nil {
+			// the stream was stopped by the parent context or by Close
+			err = parent.Err()
+			break
+		}
+		if err == nil {
+			break
+		}
+
+		if delivered > 0 {
+			failures = 0
+		}
+		failures++
+		if failures >= len(it.peers) {
+			err = errors.WithMessagef(err, "failed to deliver block %d from any of %d peers", it.next, len(it.peers))
+			break
+		}
+		logger.Warnf("Failed to deliver blocks from [%s], failing over from block %d: %s", peer.URL(), it.next, err)
+	}
+
+	it.err = err
+	tracing.EndSpan(span, err)
+}
+
+// deliverFrom streams the remaining blocks from the given peer. It returns the number of blocks delivered by the
+// peer, and an error unless all the remaining blocks were delivered.
+func (it *BlockIterator) deliverFrom(ctx reqContext.Context, peer fab.Peer) (int, error) {
+	conn, err := deliverProvider(it.client, it.chConfig, peer, it.content == FilteredBlocks)
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed to connect to the deliver service")
+	}
+
+	seekInfo := seek.InfoRange(it.next, it.to)
+	if it.content == HeaderOnlyBlocks {
+		seekInfo = seek.WithHeadersOnly(seekInfo)
+	}
+	if err := conn.Send(seekInfo); err != nil {
+		conn.Close()
+		return 0, errors.WithMessage(err, "failed to send the seek request")
+	}
+
+	events := make(chan interface{}, blockBufferSize)
+	go func() {
+		conn.Receive(events)
+		close(events)
+	}()
+	defer func() {
+		conn.Close()
+		// wait for the receiver to exit
+		for range events {
+		}
+	}()
+
+	delivered := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return delivered, ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				return delivered, errors.New("the deliver stream was closed")
+			}
+
+			block, err := it.toBlock(event)
+			if err != nil {
+				return delivered, err
+			}
+			if block.Number < it.next {
+				logger.Debugf("Ignoring block %d which was already delivered", block.Number)
+				continue
+			}
+			if block.Number > it.next {
+				return delivered, errors.Errorf("received block %d while expecting block %d", block.Number, it.next)
+			}
+
+			select {
+			case it.blocks <- block:
+			case <-ctx.Done():
+				return delivered, ctx.Err()
+			}
+			delivered++
+			it.next++
+			if it.next > it.to {
+				return delivered, nil
+			}
+		}
+	}
+}
+
+// toBlock returns the block carried by a deliver event, or an error for the other events
+func (it *BlockIterator) toBlock(event interface{}) (*DeliveredBlock, error) {
+	switch evt := event.(type) {
+	case *deliverconn.Event:
+		response, ok := evt.Event.(*pb.DeliverResponse)
+		if !ok {
+			return nil, errors.Errorf("unexpected deliver event: %T", evt.Event)
+		}
+
+		switch resp := response.Type.(type) {
+		case *pb.DeliverResponse_Block:
+			block := resp.Block
+			if it.content == HeaderOnlyBlocks {
+				// peers which don't support the header only content type send complete blocks
+				block = &common.Block{Header: block.Header, Metadata: block.Metadata}
+			}
+			return &DeliveredBlock{Number: block.GetHeader().GetNumber(), Block: block, SourceURL: evt.SourceURL}, nil
+		case *pb.DeliverResponse_FilteredBlock:
+			return &DeliveredBlock{Number: resp.FilteredBlock.Number, FilteredBlock: resp.FilteredBlock, SourceURL: evt.SourceURL}, nil
+		case *pb.DeliverResponse_Status:
+			return nil, errors.Errorf("the deliver service returned status %s before block %d", resp.Status, it.next)
+		default:
+			return nil, errors.Errorf("unexpected deliver response: %T", response.Type)
+		}
+	case *clientdisp.DisconnectedEvent:
+		return nil, errors.WithMessage(evt.Err, "disconnected from the deliver service")
+	default:
+		return nil, errors.Errorf("unexpected deliver event: %T", event)
+	}
+}
@@ -0,0 +1,284 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	reqContext "context"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	ab "github.com/hyperledger/fabric-protos-go/orderer"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	clientdisp "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/client/dispatcher"
+	deliverconn "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/connection"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestBlocks(t *testing.T) {
+	peer1 := mocks.NewMockPeer("Peer1", "http://peer1.com")
+	peer2 := mocks.NewMockPeer("Peer2", "http://peer2.com")
+	lc := setupLedgerClient([]fab.Peer{peer1, peer2}, t)
+
+	_, err := lc.Blocks(5, 4)
+	assert.Error(t, err, "expected error for invalid block range")
+
+	t.Run("single peer", func(t *testing.T) {
+		provider := newMockDeliverProvider(map[string]*mockDeliverPeer{peer1.URL(): {height: 10}})
+		defer provider.install()()
+
+		numbers, sources, err := collectBlocks(t, lc, 2, 7, WithTargets(peer1))
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{2, 3, 4, 5, 6, 7}, numbers)
+		assert.Equal(t, []string{peer1.URL()}, sources)
+	})
+
+	t.Run("failover", func(t *testing.T) {
+		// both peers disconnect after every third block, and re-deliver blocks which were already delivered
+		provider := newMockDeliverProvider(map[string]*mockDeliverPeer{
+			peer1.URL(): {height: 20, failAfter: 3, rewind: 2},
+			peer2.URL(): {height: 20, failAfter: 3},
+		})
+		defer provider.install()()
+
+		numbers, sources, err := collectBlocks(t, lc, 0, 9, WithTargets(peer1, peer2))
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, numbers, "expected no duplicated or skipped blocks")
+		assert.Len(t, sources, 2, "expected blocks from both peers")
+	})
+
+	t.Run("lagging peer", func(t *testing.T) {
+		provider := newMockDeliverProvider(map[string]*mockDeliverPeer{
+			peer1.URL(): {height: 5},
+			peer2.URL(): {height: 10},
+		})
+		defer provider.install()()
+
+		numbers, _, err := collectBlocks(t, lc, 0, 9, WithTargets(peer1, peer2))
+		require.NoError(t, err)
+		assert.Len(t, numbers, 10)
+
+		numbers, _, err = collectBlocks(t, lc, 0, 11, WithTargets(peer1, peer2))
+		require.Error(t, err, "expected error for blocks which no peer has")
+		assert.Contains(t, err.Error(), "failed to deliver block 10")
+		assert.Len(t, numbers, 10)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		provider := newMockDeliverProvider(map[string]*mockDeliverPeer{peer1.URL(): {height: 1000}})
+		defer provider.install()()
+
+		ctx, cancel := reqContext.WithCancel(reqContext.Background())
+		it, err := lc.Blocks(0, 999, WithTargets(peer1), WithParentContext(ctx))
+		require.NoError(t, err)
+		require.True(t, it.Next())
+		cancel()
+		for it.Next() {
+		}
+		assert.Equal(t, reqContext.Canceled, it.Err())
+		it.Close()
+		assert.True(t, provider.allClosed(), "expected deliver connection to be closed")
+
+		it, err = lc.Blocks(0, 999, WithTargets(peer1))
+		require.NoError(t, err)
+		require.True(t, it.Next())
+		it.Close()
+		assert.NoError(t, it.Err(), "expected no error once the iterator is closed")
+		assert.True(t, provider.allClosed(), "expected deliver connection to be closed")
+	})
+
+	t.Run("content", func(t *testing.T) {
+		provider := newMockDeliverProvider(map[string]*mockDeliverPeer{peer1.URL(): {height: 10}})
+		defer provider.install()()
+
+		it, err := lc.Blocks(1, 1, WithTargets(peer1), WithBlockContent(HeaderOnlyBlocks))
+		require.NoError(t, err)
+		require.True(t, it.Next())
+		assert.Nil(t, it.Block().Block.Data, "expected block data to be stripped")
+		assert.NotNil(t, it.Block().Block.Header)
+		assert.NotEmpty(t, provider.lastSeekInfo().XXX_unrecognized, "expected header only content type to be requested")
+		it.Close()
+
+		it, err = lc.Blocks(1, 1, WithTargets(peer1), WithBlockContent(FilteredBlocks))
+		require.NoError(t, err)
+		require.True(t, it.Next())
+		assert.NotNil(t, it.Block().FilteredBlock)
+		assert.Nil(t, it.Block().Block)
+		assert.False(t, it.Next())
+		assert.NoError(t, it.Err())
+
+		_, err = lc.Blocks(1, 1, WithBlockContent(BlockContent(5)))
+		assert.Error(t, err, "expected error for invalid block content")
+	})
+}
+
+func collectBlocks(t *testing.T, lc *Client, from, to uint64, options ...RequestOption) ([]uint64, []string, error) {
+	it, err := lc.Blocks(from, to, options...)
+	require.NoError(t, err)
+	defer it.Close()
+
+	var numbers []uint64
+	var sources []string
+	for it.Next() {
+		numbers = append(numbers, it.Block().Number)
+		sources = appendUnique(sources, it.Block().SourceURL)
+	}
+	return numbers, sources, it.Err()
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// mockDeliverPeer configures the blocks delivered by the mock deliver service of a peer
+type mockDeliverPeer struct {
+	// height is the number of blocks of the ledger of the peer
+	height uint64
+	// failAfter is the number of blocks after which the connection is lost, if set
+	failAfter int
+	// rewind is the number of blocks before the start of the seek request which are delivered
+	rewind uint64
+	// blocks are the blocks of the ledger of the peer, if set, otherwise simple mock blocks are delivered
+	blocks []*common.Block
+}
+
+type mockDeliverProvider struct {
+	mutex    sync.Mutex
+	peers    map[string]*mockDeliverPeer
+	conns    []*mockDeliverConnection
+	seekInfo *ab.SeekInfo
+}
+
+func newMockDeliverProvider(peers map[string]*mockDeliverPeer) *mockDeliverProvider {
+	return &mockDeliverProvider{peers: peers}
+}
+
+// install replaces the deliver provider and returns a function which restores it
+func (p *mockDeliverProvider) install() func() {
+	original := deliverProvider
+	deliverProvider = func(ctx context.Client, chConfig fab.ChannelCfg, peer fab.Peer, filtered bool) (deliverConnection, error) {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+
+		cfg, ok := p.peers[peer.URL()]
+		if !ok {
+			return nil, errors.Errorf("no deliver service for peer %s", peer.URL())
+		}
+		conn := &mockDeliverConnection{provider: p, url: peer.URL(), peer: cfg, filtered: filtered, closed: make(chan struct{})}
+		p.conns = append(p.conns, conn)
+		return conn, nil
+	}
+	return func() { deliverProvider = original }
+}
+
+func (p *mockDeliverProvider) lastSeekInfo() *ab.SeekInfo {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.seekInfo
+}
+
+func (p *mockDeliverProvider) allClosed() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, conn := range p.conns {
+		if !conn.Closed() {
+			return false
+		}
+	}
+	return true
+}
+
+type mockDeliverConnection struct {
+	provider  *mockDeliverProvider
+	url       string
+	peer      *mockDeliverPeer
+	filtered  bool
+	seekInfo  *ab.SeekInfo
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *mockDeliverConnection) Send(seekInfo *ab.SeekInfo) error {
+	c.provider.mutex.Lock()
+	c.provider.seekInfo = seekInfo
+	c.provider.mutex.Unlock()
+	c.seekInfo = seekInfo
+	return nil
+}
+
+func (c *mockDeliverConnection) Receive(eventch chan<- interface{}) {
+	start := c.seekInfo.Start.GetSpecified().Number
+	stop := c.seekInfo.Stop.GetSpecified().Number
+	if start >= c.peer.rewind {
+		start -= c.peer.rewind
+	}
+
+	sent := 0
+	for n := start; n <= stop; n++ {
+		var event interface{}
+		switch {
+		case c.peer.failAfter > 0 && sent == c.peer.failAfter:
+			event = clientdisp.NewDisconnectedEvent(errors.New("connection lost"))
+		case n >= c.peer.height:
+			event = c.newEvent(&pb.DeliverResponse{Type: &pb.DeliverResponse_Status{Status: common.Status_NOT_FOUND}})
+		case c.filtered:
+			event = c.newEvent(&pb.DeliverResponse{Type: &pb.DeliverResponse_FilteredBlock{FilteredBlock: &pb.FilteredBlock{Number: n}}})
+		case c.peer.blocks != nil:
+			event = c.newEvent(&pb.DeliverResponse{Type: &pb.DeliverResponse_Block{Block: c.peer.blocks[n]}})
+		default:
+			block := mocks.NewSimpleMockBlock()
+			block.Header.Number = n
+			event = c.newEvent(&pb.DeliverResponse{Type: &pb.DeliverResponse_Block{Block: block}})
+		}
+
+		select {
+		case eventch <- event:
+		case <-c.closed:
+			return
+		}
+		if _, ok := event.(*deliverconn.Event); !ok || n >= c.peer.height {
+			<-c.closed
+			return
+		}
+		sent++
+	}
+
+	select {
+	case eventch <- c.newEvent(&pb.DeliverResponse{Type: &pb.DeliverResponse_Status{Status: common.Status_SUCCESS}}):
+	case <-c.closed:
+		return
+	}
+	<-c.closed
+}
+
+func (c *mockDeliverConnection) newEvent(response *pb.DeliverResponse) *deliverconn.Event {
+	return deliverconn.NewEvent(response, c.url)
+}
+
+func (c *mockDeliverConnection) Close() {
+	c.closeOnce.Do(func() { close(c.closed) })
+}
+
+func (c *mockDeliverConnection) Closed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
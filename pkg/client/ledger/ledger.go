@@ -8,6 +8,8 @@ SPDX-License-Identifier: Apache-2.0
 // An application that requires ledger queries from multiple channels should create a separate
 // instance of the ledger client for each channel. Ledger client supports the following queries:
 // QueryInfo, QueryBlock, QueryBlockByHash,  QueryBlockByTxID, QueryTransaction and QueryConfig.
+// Ranges of blocks are streamed from the deliver service of the peers using Blocks, and verified using VerifyBlocks.
+// Receipts of committed transactions, which third parties verify offline, are assembled using TransactionReceipt.
 //
 //  Basic Flow:
 //  1) Prepare channel context
@@ -28,6 +30,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 
 	"github.com/hyperledger/fabric-protos-go/common"
 	pb "github.com/hyperledger/fabric-protos-go/peer"
@@ -130,7 +133,7 @@ func (c *Client) QueryInfo(options ...RequestOption) (*fab.BlockchainInfoRespons
 	if err != nil {
 		return nil, errors.WithMessage(err, "QueryInfo failed to prepare request parameters")
 	}
-	reqCtx, cancel := c.createRequestContext(opts)
+	reqCtx, cancel := c.createRequestContext(opts, "QueryInfo")
 	defer cancel()
 
 	responses, err := c.ledger.QueryInfo(reqCtx, peersToTxnProcessors(targets), c.verifier)
@@ -173,7 +176,7 @@ func (c *Client) QueryBlockByHash(blockHash []byte, options ...RequestOption) (*
 	if err != nil {
 		return nil, errors.WithMessage(err, "QueryBlockByHash failed to prepare request parameters")
 	}
-	reqCtx, cancel := c.createRequestContext(opts)
+	reqCtx, cancel := c.createRequestContext(opts, "QueryBlockByHash")
 	defer cancel()
 
 	responses, err := c.ledger.QueryBlockByHash(reqCtx, blockHash, peersToTxnProcessors(targets), c.verifier)
@@ -197,7 +200,7 @@ func (c *Client) QueryBlockByTxID(txID fab.TransactionID, options ...RequestOpti
 	if err != nil {
 		return nil, errors.WithMessage(err, "QueryBlockByTxID failed to prepare request parameters")
 	}
-	reqCtx, cancel := c.createRequestContext(opts)
+	reqCtx, cancel := c.createRequestContext(opts, "QueryBlockByTxID", tracing.String(tracing.TxIDKey, string(txID)))
 	defer cancel()
 
 	responses, err := c.ledger.QueryBlockByTxID(reqCtx, txID, peersToTxnProcessors(targets), c.verifier)
@@ -221,7 +224,7 @@ func (c *Client) QueryBlock(blockNumber uint64, options ...RequestOption) (*comm
 	if err != nil {
 		return nil, errors.WithMessage(err, "QueryBlock failed to prepare request parameters")
 	}
-	reqCtx, cancel := c.createRequestContext(opts)
+	reqCtx, cancel := c.createRequestContext(opts, "QueryBlock")
 	defer cancel()
 
 	responses, err := c.ledger.QueryBlock(reqCtx, blockNumber, peersToTxnProcessors(targets), c.verifier)
@@ -268,6 +271,7 @@ func matchBlockData(responses []*common.Block, minTargets int) (*common.Block, e
 }
 
 // QueryTransaction queries the ledger for processed transaction by transaction ID.
+// The read/write sets and chaincode event of the transaction may be decoded using channel.SimulationResultsFromTransaction.
 //  Parameters:
 //  txID is required transaction ID
 //  options hold optional request options
@@ -280,7 +284,7 @@ func (c *Client) QueryTransaction(transactionID fab.TransactionID, options ...Re
 	if err != nil {
 		return nil, errors.WithMessage(err, "QueryTransaction failed to prepare request parameters")
 	}
-	reqCtx, cancel := c.createRequestContext(opts)
+	reqCtx, cancel := c.createRequestContext(opts, "QueryTransaction", tracing.String(tracing.TxIDKey, string(transactionID)))
 	defer cancel()
 
 	responses, err := c.ledger.QueryTransaction(reqCtx, transactionID, peersToTxnProcessors(targets), c.verifier)
@@ -319,7 +323,7 @@ func (c *Client) QueryConfig(options ...RequestOption) (fab.ChannelCfg, error) {
 	if err != nil {
 		return nil, errors.WithMessage(err, "QueryConfig failed to prepare request parameters")
 	}
-	reqCtx, cancel := c.createRequestContext(opts)
+	reqCtx, cancel := c.createRequestContext(opts, "QueryConfig")
 	defer cancel()
 
 	channelConfig, err := chconfig.New(c.ctx.ChannelID(), chconfig.WithPeers(targets), chconfig.WithMinResponses(opts.MinTargets))
@@ -337,7 +341,7 @@ func (c *Client) QueryConfigBlock(options ...RequestOption) (*common.Block, erro
 		return nil, errors.WithMessage(err, "QueryConfigBlock failed to prepare request parameters")
 	}
 
-	reqCtx, cancel := c.createRequestContext(opts)
+	reqCtx, cancel := c.createRequestContext(opts, "QueryConfigBlock")
 	defer cancel()
 
 	return c.ledger.QueryConfigBlock(reqCtx, peersToTxnProcessors(targets), c.verifier)
@@ -417,8 +421,8 @@ func (c *Client) calculateTargets(opts requestOptions) ([]fab.Peer, error) {
 	return targets[:numOfTargets], nil
 }
 
-//createRequestContext creates request context for grpc
-func (c *Client) createRequestContext(opts *requestOptions) (reqContext.Context, reqContext.CancelFunc) {
+//createRequestContext creates request context for grpc, along with a span of the given operation
+func (c *Client) createRequestContext(opts *requestOptions, operation string, attrs ...tracing.Attribute) (reqContext.Context, reqContext.CancelFunc) {
 
 	if opts.Timeouts == nil {
 		opts.Timeouts = make(map[fab.TimeoutType]time.Duration)
@@ -428,7 +432,8 @@ func (c *Client) createRequestContext(opts *requestOptions) (reqContext.Context,
 		opts.Timeouts[fab.PeerResponse] = c.ctx.EndpointConfig().Timeout(fab.PeerResponse)
 	}
 
-	return contextImpl.NewRequest(c.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.PeerResponse]), contextImpl.WithParent(opts.ParentContext))
+	return contextImpl.NewRequest(c.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.PeerResponse]), contextImpl.WithParent(opts.ParentContext),
+		contextImpl.WithSpan("ledger."+operation, append(attrs, tracing.String(tracing.ChannelKey, c.ctx.ChannelID()))...))
 }
 
 // filterTargets is helper method to filter peers
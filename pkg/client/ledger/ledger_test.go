@@ -12,6 +12,7 @@ import (
 	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
@@ -258,6 +259,41 @@ func TestQueryTransaction(t *testing.T) {
 	}
 }
 
+func TestTracing(t *testing.T) {
+	peer := mocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockRoles: []string{}, MockCert: nil, Status: 200, MockMSP: "test"}
+	tracer := tracing.NewInMemoryTracer()
+
+	fabCtx := setupCustomTestContext(t, txnmocks.NewMockDiscoveryService(nil, &peer), nil)
+	clientCtx, err := fabCtx()
+	assert.NoError(t, err)
+	clientCtx.(*fcmocks.MockContext).SetTracer(tracer)
+
+	lc, err := New(createChannelContext(fabCtx, channelID))
+	if err != nil {
+		t.Fatalf("Failed to create new ledger client: %s", err)
+	}
+
+	_, err = lc.QueryTransaction("1234")
+	if err != nil {
+		t.Fatalf("Test ledger query transaction failed: %s", err)
+	}
+
+	queries := tracer.SpansByName("ledger.QueryTransaction")
+	if len(queries) != 1 {
+		t.Fatalf("Expected one ledger.QueryTransaction span but got %d", len(queries))
+	}
+	assert.Equal(t, channelID, queries[0].Attributes[tracing.ChannelKey])
+	assert.Equal(t, "1234", queries[0].Attributes[tracing.TxIDKey])
+	assert.True(t, queries[0].Ended)
+
+	endorsements := tracer.SpansByName("peer.ProcessProposal")
+	if len(endorsements) != 1 {
+		t.Fatalf("Expected one peer.ProcessProposal span but got %d", len(endorsements))
+	}
+	assert.Equal(t, queries[0].ID, endorsements[0].ParentID)
+	assert.Equal(t, peer.URL(), endorsements[0].Attributes[tracing.PeerURLKey])
+}
+
 func TestQueryConfig(t *testing.T) {
 	peer := mocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockRoles: []string{}, MockCert: nil, Status: 200, MockMSP: "test"}
 	lc := setupLedgerClient([]fab.Peer{&peer}, t)
@@ -43,6 +43,7 @@ type requestOptions struct {
 	MinTargets    int                               // min number of targets that have to respond with no error (or agree on result)
 	Timeouts      map[fab.TimeoutType]time.Duration //timeout options for ledger query operations
 	ParentContext reqContext.Context                //parent grpc context for ledger operations
+	BlockContent  BlockContent                      //content of the blocks delivered by Blocks
 }
 
 //WithTargets allows for overriding of the target peers per request.
@@ -132,3 +133,14 @@ func WithParentContext(parentContext reqContext.Context) RequestOption {
 		return nil
 	}
 }
+
+//WithBlockContent specifies the content of the blocks delivered by Blocks. Full blocks are delivered by default.
+func WithBlockContent(content BlockContent) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if content < FullBlocks || content > HeaderOnlyBlocks {
+			return errors.Errorf("invalid block content: %d", content)
+		}
+		o.BlockContent = content
+		return nil
+	}
+}
@@ -0,0 +1,36 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/receipt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+)
+
+// TransactionReceipt assembles the receipt of a committed transaction from the block which contains it, as returned
+// by QueryBlockByTxID. The receipt is self-contained and may be handed to third parties, who verify it offline using
+// receipt.Verifier and the config block in effect at the block of the transaction, whose number is returned by
+// Receipt.LastConfig.
+//  Parameters:
+//  txID is required transaction ID
+//  options hold optional request options
+//
+//  Returns:
+//  the receipt of the transaction
+func (c *Client) TransactionReceipt(txID fab.TransactionID, options ...RequestOption) (*receipt.Receipt, error) {
+	block, err := c.QueryBlockByTxID(txID, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := receipt.New(block, string(txID))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to assemble the transaction receipt")
+	}
+	return r, nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/receipt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestTransactionReceipt(t *testing.T) {
+	orderer := fcmocks.NewMockOrdererOrg("OrdererMSP")
+	org1 := fcmocks.NewMockOrdererOrg("Org1MSP")
+	genesis := fcmocks.NewMockChannelConfigBlock(channelID, nil, common.ImplicitMetaPolicy_ANY, []*fcmocks.MockOrdererOrg{orderer}, org1)
+
+	tx, txID := fcmocks.NewMockEndorserTransaction(channelID, org1, org1)
+	block := fcmocks.NewMockChainedBlock(genesis.Header, tx)
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = []byte{byte(pb.TxValidationCode_VALID)}
+	fcmocks.SignMockBlock(block, 0, orderer)
+
+	blockBytes, err := proto.Marshal(block)
+	require.NoError(t, err)
+	peer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	peer1.Payload = blockBytes
+
+	lc := setupVerifyingLedgerClient(t, peer1)
+
+	r, err := lc.TransactionReceipt(fab.TransactionID(txID), WithTargets(peer1))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, r.BlockHeader.Number)
+	assert.Equal(t, pb.TxValidationCode_VALID, r.ValidationCode)
+
+	cs, err := sw.GetSuiteWithDefaultEphemeral()
+	require.NoError(t, err)
+	verifier, err := receipt.NewVerifier(genesis, cs)
+	require.NoError(t, err)
+	_, err = verifier.Verify(r)
+	assert.NoError(t, err)
+
+	_, err = lc.TransactionReceipt("unknown", WithTargets(peer1))
+	assert.Error(t, err, "expected error for transaction which is not in the block")
+}
@@ -0,0 +1,96 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/blockverifier"
+	"github.com/pkg/errors"
+)
+
+// VerifyBlocks verifies the blocks from fromBlock to toBlock, inclusive, independently of the peers which
+// returned them. The header of each block must contain the hash of the header of the previous block and the
+// hash of its data, and the orderer signatures of each block must satisfy the BlockValidation policy of the
+// channel config in effect at the height of the block. The config block in effect at fromBlock is queried
+// from the peers like QueryBlock and is trusted; verify from block 0 to anchor the verification at the
+// genesis block of the channel. The blocks are streamed using Blocks.
+//  Parameters:
+//  fromBlock is the number of the first block
+//  toBlock is the number of the last block
+//  options holds optional request options
+//
+//  Returns:
+//  a blockverifier.VerificationError for the first block which failed verification
+func (c *Client) VerifyBlocks(fromBlock, toBlock uint64, options ...RequestOption) error {
+	configBlock, err := c.configBlockAt(fromBlock, options...)
+	if err != nil {
+		return err
+	}
+
+	verifier, err := blockverifier.New(configBlock, c.ctx.CryptoSuite())
+	if err != nil {
+		return errors.WithMessagef(err, "failed to load the config in effect at block %d", fromBlock)
+	}
+
+	it, err := c.Blocks(fromBlock, toBlock, append(options, WithBlockContent(FullBlocks))...)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	for it.Next() {
+		if err := verifier.Verify(it.Block().Block); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// configBlockAt returns the config block in effect at the given block, whose orderer signatures are verified
+// against it. A config block is signed according to the previous config, so the config in effect at a config
+// block is the config referenced by the previous block.
+func (c *Client) configBlockAt(blockNumber uint64, options ...RequestOption) (*common.Block, error) {
+	block, lastConfig, err := c.queryBlockWithLastConfig(blockNumber, options...)
+	if err != nil {
+		return nil, err
+	}
+	if blockNumber > 0 && lastConfig == blockNumber {
+		blockNumber--
+		block, lastConfig, err = c.queryBlockWithLastConfig(blockNumber, options...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if lastConfig == blockNumber {
+		return block, nil
+	}
+
+	configBlock, err := c.QueryBlock(lastConfig, options...)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to query config block %d", lastConfig)
+	}
+	return configBlock, nil
+}
+
+// queryBlockWithLastConfig queries a block and returns it with the index of the last config block referenced by
+// its metadata. The genesis block references itself.
+func (c *Client) queryBlockWithLastConfig(blockNumber uint64, options ...RequestOption) (*common.Block, uint64, error) {
+	block, err := c.QueryBlock(blockNumber, options...)
+	if err != nil {
+		return nil, 0, errors.WithMessagef(err, "failed to query block %d", blockNumber)
+	}
+	if blockNumber == 0 {
+		return block, 0, nil
+	}
+
+	lastConfig, err := protoutil.GetLastConfigIndexFromBlock(block)
+	if err != nil {
+		return nil, 0, errors.WithMessagef(err, "failed to get the last config index of block %d", blockNumber)
+	}
+	return block, lastConfig, nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	reqContext "context"
+	"strconv"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/blockverifier"
+	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestVerifyBlocks(t *testing.T) {
+	org := fcmocks.NewMockOrdererOrg("OrdererMSP")
+	genesis := fcmocks.NewMockOrdererConfigBlock(channelID, nil, common.ImplicitMetaPolicy_ANY, org)
+	block1 := fcmocks.NewMockChainedBlock(genesis.Header, []byte("tx1"))
+	fcmocks.SignMockBlock(block1, 0, org)
+	block2 := fcmocks.NewMockChainedBlock(block1.Header, []byte("tx2"))
+	fcmocks.SignMockBlock(block2, 0, org)
+
+	genesisBytes, err := proto.Marshal(genesis)
+	require.NoError(t, err)
+	peer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	peer1.Payload = genesisBytes
+
+	lc := setupVerifyingLedgerClient(t, peer1)
+
+	provider := newMockDeliverProvider(map[string]*mockDeliverPeer{
+		peer1.URL(): {height: 3, blocks: []*common.Block{genesis, block1, block2}},
+	})
+	defer provider.install()()
+
+	require.NoError(t, lc.VerifyBlocks(0, 2, WithTargets(peer1)))
+
+	tampered := proto.Clone(block2).(*common.Block)
+	tampered.Data.Data = [][]byte{[]byte("tampered")}
+	provider.peers[peer1.URL()].blocks[2] = tampered
+
+	err = lc.VerifyBlocks(0, 2, WithTargets(peer1))
+	require.Error(t, err)
+	verr, ok := errors.Cause(err).(*blockverifier.VerificationError)
+	require.Truef(t, ok, "expected verification error but got %s", err)
+	assert.EqualValues(t, 2, verr.BlockNumber)
+	assert.Equal(t, blockverifier.DataHashCheck, verr.Check)
+
+	// the config block in effect at block 1 is referenced by its metadata
+	peer1.Payload, err = proto.Marshal(block1)
+	require.NoError(t, err)
+	err = lc.VerifyBlocks(1, 2, WithTargets(peer1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load the config in effect at block 1", "expected error for queried block which is not a config block")
+}
+
+func TestVerifyBlocksFromConfigBlock(t *testing.T) {
+	org1 := fcmocks.NewMockOrdererOrg("Orderer1MSP")
+	org2 := fcmocks.NewMockOrdererOrg("Orderer2MSP")
+	genesis := fcmocks.NewMockOrdererConfigBlock(channelID, nil, common.ImplicitMetaPolicy_ANY, org1)
+	// the config update replaces the orderer organization, and is signed according to the previous config
+	block1 := fcmocks.NewMockOrdererConfigBlock(channelID, genesis.Header, common.ImplicitMetaPolicy_ALL, org2)
+	fcmocks.SignMockBlock(block1, 1, org1)
+	block2 := fcmocks.NewMockChainedBlock(block1.Header, []byte("tx2"))
+	fcmocks.SignMockBlock(block2, 1, org2)
+	blocks := []*common.Block{genesis, block1, block2}
+
+	peer1 := &blockQueryPeer{MockPeer: fcmocks.NewMockPeer("Peer1", "http://peer1.com"), blocks: blocks}
+	lc := setupVerifyingLedgerClient(t, peer1.MockPeer)
+
+	provider := newMockDeliverProvider(map[string]*mockDeliverPeer{
+		peer1.URL(): {height: 3, blocks: blocks},
+	})
+	defer provider.install()()
+
+	require.NoError(t, lc.VerifyBlocks(1, 2, WithTargets(peer1)))
+	require.NoError(t, lc.VerifyBlocks(2, 2, WithTargets(peer1)))
+}
+
+// blockQueryPeer responds to queries of blocks by number with the block of the given number
+type blockQueryPeer struct {
+	*fcmocks.MockPeer
+	blocks []*common.Block
+}
+
+func (p *blockQueryPeer) ProcessTransactionProposal(ctx reqContext.Context, request fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	proposal, err := protoutil.UnmarshalProposal(request.SignedProposal.ProposalBytes)
+	if err != nil {
+		return nil, err
+	}
+	cpp, err := protoutil.UnmarshalChaincodeProposalPayload(proposal.Payload)
+	if err != nil {
+		return nil, err
+	}
+	cis := &pb.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(cpp.Input, cis); err != nil {
+		return nil, err
+	}
+
+	args := cis.ChaincodeSpec.Input.Args
+	if len(args) == 3 && string(args[0]) == "GetBlockByNumber" {
+		number, err := strconv.ParseUint(string(args[2]), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if p.Payload, err = proto.Marshal(p.blocks[number]); err != nil {
+			return nil, err
+		}
+	}
+	return p.MockPeer.ProcessTransactionProposal(ctx, request)
+}
+
+func setupVerifyingLedgerClient(t *testing.T, peers ...*fcmocks.MockPeer) *Client {
+	var discovered []fab.Peer
+	for _, p := range peers {
+		discovered = append(discovered, p)
+	}
+	clientProvider := setupCustomTestContext(t, txnmocks.NewMockDiscoveryService(nil, discovered...), nil)
+
+	cs, err := sw.GetSuiteWithDefaultEphemeral()
+	require.NoError(t, err)
+	clientCtx, err := clientProvider()
+	require.NoError(t, err)
+	clientCtx.(*fcmocks.MockContext).SetCryptoSuite(cs)
+
+	lc, err := New(createChannelContext(clientProvider, channelID))
+	require.NoError(t, err)
+	lc.verifier = &TestVerifier{}
+	return lc
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 )
 
@@ -149,7 +150,8 @@ func (rc *Client) LifecycleInstallCC(req LifecycleInstallCCRequest, options ...R
 
 	rc.resolveTimeouts(&opts)
 
-	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.ResMgmt]), contextImpl.WithParent(opts.ParentContext))
+	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.ResMgmt]), contextImpl.WithParent(opts.ParentContext),
+		contextImpl.WithSpan("resmgmt.LifecycleInstallCC"))
 	parentReqCtx = reqContext.WithValue(parentReqCtx, contextImpl.ReqContextTimeoutOverrides, opts.Timeouts)
 	defer parentReqCancel()
 
@@ -207,7 +209,8 @@ func (rc *Client) LifecycleQueryInstalledCC(options ...RequestOption) ([]Lifecyc
 
 	rc.resolveTimeouts(&opts)
 
-	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.ResMgmt]), contextImpl.WithParent(opts.ParentContext))
+	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.ResMgmt]), contextImpl.WithParent(opts.ParentContext),
+		contextImpl.WithSpan("resmgmt.LifecycleQueryInstalledCC"))
 	parentReqCtx = reqContext.WithValue(parentReqCtx, contextImpl.ReqContextTimeoutOverrides, opts.Timeouts)
 	defer parentReqCancel()
 
@@ -243,7 +246,8 @@ func (rc *Client) LifecycleGetInstalledCCPackage(packageID string, options ...Re
 
 	rc.resolveTimeouts(&opts)
 
-	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.ResMgmt]), contextImpl.WithParent(opts.ParentContext))
+	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.ResMgmt]), contextImpl.WithParent(opts.ParentContext),
+		contextImpl.WithSpan("resmgmt.LifecycleGetInstalledCCPackage"))
 	parentReqCtx = reqContext.WithValue(parentReqCtx, contextImpl.ReqContextTimeoutOverrides, opts.Timeouts)
 	defer parentReqCancel()
 
@@ -272,7 +276,7 @@ func (rc *Client) LifecycleApproveCC(channelID string, req LifecycleApproveCCReq
 		return "", errors.WithMessage(err, "failed to get opts for ApproveCC")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt)
+	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt, "LifecycleApproveCC", tracing.String(tracing.ChannelKey, channelID))
 	defer cancel()
 
 	return rc.lifecycleProcessor.approve(reqCtx, channelID, req, opts)
@@ -291,7 +295,8 @@ func (rc *Client) LifecycleQueryApprovedCC(channelID string, req LifecycleQueryA
 
 	rc.resolveTimeouts(&opts)
 
-	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.ResMgmt]), contextImpl.WithParent(opts.ParentContext))
+	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.ResMgmt]), contextImpl.WithParent(opts.ParentContext),
+		contextImpl.WithSpan("resmgmt.LifecycleQueryApprovedCC", tracing.String(tracing.ChannelKey, channelID)))
 	parentReqCtx = reqContext.WithValue(parentReqCtx, contextImpl.ReqContextTimeoutOverrides, opts.Timeouts)
 	defer parentReqCancel()
 
@@ -308,7 +313,7 @@ func (rc *Client) LifecycleCheckCCCommitReadiness(channelID string, req Lifecycl
 		return LifecycleCheckCCCommitReadinessResponse{}, errors.WithMessage(err, "failed to get opts for CheckCCCommitReadiness")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt)
+	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt, "LifecycleCheckCCCommitReadiness", tracing.String(tracing.ChannelKey, channelID))
 	defer cancel()
 
 	return rc.lifecycleProcessor.checkCommitReadiness(reqCtx, channelID, req, opts)
@@ -321,7 +326,7 @@ func (rc *Client) LifecycleCommitCC(channelID string, req LifecycleCommitCCReque
 		return fab.EmptyTransactionID, errors.WithMessage(err, "failed to get opts for CommitCC")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt)
+	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt, "LifecycleCommitCC", tracing.String(tracing.ChannelKey, channelID))
 	defer cancel()
 
 	return rc.lifecycleProcessor.commit(reqCtx, channelID, req, opts)
@@ -334,7 +339,7 @@ func (rc *Client) LifecycleQueryCommittedCC(channelID string, req LifecycleQuery
 		return nil, errors.WithMessage(err, "failed to get opts for QueryCommittedCC")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt)
+	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt, "LifecycleQueryCommittedCC", tracing.String(tracing.ChannelKey, channelID))
 	defer cancel()
 
 	return rc.lifecycleProcessor.queryCommitted(reqCtx, channelID, req, opts)
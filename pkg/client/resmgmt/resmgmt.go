@@ -8,6 +8,7 @@ SPDX-License-Identifier: Apache-2.0
 // It allows administrators to create and/or update channnels, and for peers to join channels.
 // Administrators can also perform chaincode related operations on a peer, such as
 // installing, instantiating, and upgrading chaincode.
+// Ledger snapshots of channels may be requested from peers, and peers may join channels from snapshots.
 //
 //  Basic Flow:
 //  1) Prepare client context
@@ -41,6 +42,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/channel"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/chconfig"
@@ -218,7 +220,8 @@ func (rc *Client) JoinChannel(channelID string, options ...RequestOption) error
 	rc.resolveTimeouts(&opts)
 
 	//set parent request context for overall timeout
-	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.ResMgmt]), contextImpl.WithParent(opts.ParentContext))
+	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.ResMgmt]), contextImpl.WithParent(opts.ParentContext),
+		contextImpl.WithSpan("resmgmt.JoinChannel", tracing.String(tracing.ChannelKey, channelID)))
 	parentReqCtx = reqContext.WithValue(parentReqCtx, contextImpl.ReqContextTimeoutOverrides, opts.Timeouts)
 	defer parentReqCancel()
 
@@ -385,7 +388,8 @@ func (rc *Client) InstallCC(req InstallCCRequest, options ...RequestOption) ([]I
 	rc.resolveTimeouts(&opts)
 
 	//set parent request context for overall timeout
-	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.ResMgmt]), contextImpl.WithParent(opts.ParentContext))
+	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[fab.ResMgmt]), contextImpl.WithParent(opts.ParentContext),
+		contextImpl.WithSpan("resmgmt.InstallCC"))
 	parentReqCtx = reqContext.WithValue(parentReqCtx, contextImpl.ReqContextTimeoutOverrides, opts.Timeouts)
 	defer parentReqCancel()
 
@@ -499,7 +503,7 @@ func (rc *Client) InstantiateCC(channelID string, req InstantiateCCRequest, opti
 		return InstantiateCCResponse{}, errors.WithMessage(err, "failed to get opts for InstantiateCC")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt)
+	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt, "InstantiateCC", tracing.String(tracing.ChannelKey, channelID))
 	defer cancel()
 
 	txID, err := rc.sendCCProposal(reqCtx, InstantiateChaincode, channelID, req, opts)
@@ -522,7 +526,7 @@ func (rc *Client) UpgradeCC(channelID string, req UpgradeCCRequest, options ...R
 		return UpgradeCCResponse{}, errors.WithMessage(err, "failed to get opts for UpgradeCC")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt)
+	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt, "UpgradeCC", tracing.String(tracing.ChannelKey, channelID))
 	defer cancel()
 
 	txID, err := rc.sendCCProposal(reqCtx, UpgradeChaincode, channelID, InstantiateCCRequest(req), opts)
@@ -547,7 +551,7 @@ func (rc *Client) QueryInstalledChaincodes(options ...RequestOption) (*pb.Chainc
 		return nil, errors.New("only one target is supported")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, fab.PeerResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, fab.PeerResponse, "QueryInstalledChaincodes")
 	defer cancel()
 
 	return resource.QueryInstalledChaincodes(reqCtx, opts.Targets[0], resource.WithRetry(opts.Retry))
@@ -594,7 +598,7 @@ func (rc *Client) QueryInstantiatedChaincodes(channelID string, options ...Reque
 		return nil, err
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, fab.PeerResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, fab.PeerResponse, "QueryInstantiatedChaincodes", tracing.String(tracing.ChannelKey, channelID))
 	defer cancel()
 
 	// Channel service membership is required to verify signature
@@ -654,7 +658,7 @@ func (rc *Client) QueryCollectionsConfig(channelID string, chaincodeName string,
 		return nil, err
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, fab.PeerResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, fab.PeerResponse, "QueryCollectionsConfig", tracing.String(tracing.ChannelKey, channelID))
 	defer cancel()
 
 	// Channel service membership is required to verify signature
@@ -715,7 +719,7 @@ func (rc *Client) QueryChannels(options ...RequestOption) (*pb.ChannelQueryRespo
 		return nil, errors.New("only one target is supported")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, fab.PeerResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, fab.PeerResponse, "QueryChannels")
 	defer cancel()
 
 	return resource.QueryChannels(reqCtx, opts.Targets[0], resource.WithRetry(opts.Retry))
@@ -989,7 +993,7 @@ func (rc *Client) signAndSubmitChannelConfigTx(channelID string, signingIdentiti
 		Signatures: configSignatures,
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, fab.OrdererResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, fab.OrdererResponse, "SaveChannel", tracing.String(tracing.ChannelKey, channelID))
 	defer cancel()
 
 	txID, err := resource.CreateChannel(reqCtx, request, resource.WithRetry(opts.Retry))
@@ -1258,7 +1262,7 @@ func (rc *Client) QueryConfigBlockFromOrderer(channelID string, options ...Reque
 		return nil, errors.WithMessage(err, "QueryConfig failed")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, fab.OrdererResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, fab.OrdererResponse, "QueryConfigBlockFromOrderer", tracing.String(tracing.ChannelKey, channelID))
 	defer cancel()
 
 	return channelConfig.QueryBlock(reqCtx)
@@ -1284,7 +1288,7 @@ func (rc *Client) QueryConfigFromOrderer(channelID string, options ...RequestOpt
 		return nil, errors.WithMessage(err, "QueryConfig failed")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, fab.OrdererResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, fab.OrdererResponse, "QueryConfigFromOrderer", tracing.String(tracing.ChannelKey, channelID))
 	defer cancel()
 
 	return channelConfig.Query(reqCtx)
@@ -1343,8 +1347,8 @@ func (rc *Client) prepareRequestOpts(options ...RequestOption) (requestOptions,
 	return opts, nil
 }
 
-//createRequestContext creates request context for grpc
-func (rc *Client) createRequestContext(opts requestOptions, defaultTimeoutType fab.TimeoutType) (reqContext.Context, reqContext.CancelFunc) {
+//createRequestContext creates request context for grpc, along with a span of the given operation
+func (rc *Client) createRequestContext(opts requestOptions, defaultTimeoutType fab.TimeoutType, operation string, attrs ...tracing.Attribute) (reqContext.Context, reqContext.CancelFunc) {
 
 	rc.resolveTimeouts(&opts)
 
@@ -1352,7 +1356,8 @@ func (rc *Client) createRequestContext(opts requestOptions, defaultTimeoutType f
 		opts.Timeouts[defaultTimeoutType] = rc.ctx.EndpointConfig().Timeout(defaultTimeoutType)
 	}
 
-	return contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[defaultTimeoutType]), contextImpl.WithParent(opts.ParentContext))
+	return contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeouts[defaultTimeoutType]), contextImpl.WithParent(opts.ParentContext),
+		contextImpl.WithSpan("resmgmt."+operation, attrs...))
 }
 
 //resolveTimeouts sets default for timeouts from config if not provided through opts
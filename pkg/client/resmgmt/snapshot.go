@@ -0,0 +1,167 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+)
+
+// SubmitSnapshotRequest submits a request to the peers to generate a snapshot of the ledger of a channel. If peer(s) are not specified in options it will default to all peers that belong to client's MSP.
+//  Parameters:
+//  channelID is mandatory channel name
+//  blockNumber is the number of the block at which the snapshot is generated, or 0 for the last committed block
+//  options holds optional request options
+//
+//  Returns:
+//  an error if the request fails
+func (rc *Client) SubmitSnapshotRequest(channelID string, blockNumber uint64, options ...RequestOption) error {
+	if channelID == "" {
+		return errors.New("must provide channel ID")
+	}
+
+	opts, targets, err := rc.prepareSnapshotRequest("SubmitSnapshotRequest", options...)
+	if err != nil {
+		return err
+	}
+
+	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt, "SubmitSnapshotRequest", tracing.String(tracing.ChannelKey, channelID))
+	defer cancel()
+
+	if err := resource.SubmitSnapshotRequest(reqCtx, channelID, blockNumber, targets, resource.WithRetry(opts.Retry)); err != nil {
+		return errors.WithMessage(err, "submit snapshot request failed")
+	}
+	return nil
+}
+
+// CancelSnapshotRequest cancels a pending request to generate a snapshot of the ledger of a channel. If peer(s) are not specified in options it will default to all peers that belong to client's MSP.
+//  Parameters:
+//  channelID is mandatory channel name
+//  blockNumber is the block number of the pending request
+//  options holds optional request options
+//
+//  Returns:
+//  an error if the request fails
+func (rc *Client) CancelSnapshotRequest(channelID string, blockNumber uint64, options ...RequestOption) error {
+	if channelID == "" {
+		return errors.New("must provide channel ID")
+	}
+
+	opts, targets, err := rc.prepareSnapshotRequest("CancelSnapshotRequest", options...)
+	if err != nil {
+		return err
+	}
+
+	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt, "CancelSnapshotRequest", tracing.String(tracing.ChannelKey, channelID))
+	defer cancel()
+
+	if err := resource.CancelSnapshotRequest(reqCtx, channelID, blockNumber, targets, resource.WithRetry(opts.Retry)); err != nil {
+		return errors.WithMessage(err, "cancel snapshot request failed")
+	}
+	return nil
+}
+
+// QueryPendingSnapshots queries the block numbers of the pending snapshot requests of a channel on a peer.
+//  Parameters:
+//  channelID is mandatory channel name
+//  options hold optional request options
+//  Note: One target(peer) has to be specified using either WithTargetURLs or WithTargets request option
+//
+//  Returns:
+//  the block numbers of the pending snapshot requests
+func (rc *Client) QueryPendingSnapshots(channelID string, options ...RequestOption) ([]uint64, error) {
+	if channelID == "" {
+		return nil, errors.New("must provide channel ID")
+	}
+
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.Targets) != 1 {
+		return nil, errors.New("only one target is supported")
+	}
+
+	reqCtx, cancel := rc.createRequestContext(opts, fab.PeerResponse, "QueryPendingSnapshots", tracing.String(tracing.ChannelKey, channelID))
+	defer cancel()
+
+	return resource.QueryPendingSnapshots(reqCtx, channelID, opts.Targets[0], resource.WithRetry(opts.Retry))
+}
+
+// JoinChannelBySnapshot allows for peers to join the channel of a ledger snapshot, without replaying the blocks of the channel up to the snapshot. The peers join the channel in the background; use QueryJoinBySnapshotStatus to check whether a join is in progress. If peer(s) are not specified in options it will default to all peers that belong to client's MSP.
+//  Parameters:
+//  snapshotDir is the mandatory path of the snapshot directory on the file system of the peers
+//  options holds optional request options
+//
+//  Returns:
+//  an error if join fails
+func (rc *Client) JoinChannelBySnapshot(snapshotDir string, options ...RequestOption) error {
+	if snapshotDir == "" {
+		return errors.New("must provide snapshot directory")
+	}
+
+	opts, targets, err := rc.prepareSnapshotRequest("JoinChannelBySnapshot", options...)
+	if err != nil {
+		return err
+	}
+
+	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt, "JoinChannelBySnapshot")
+	defer cancel()
+
+	if err := resource.JoinChannelBySnapshot(reqCtx, snapshotDir, peersToTxnProcessors(targets), resource.WithRetry(opts.Retry)); err != nil {
+		return errors.WithMessage(err, "join channel by snapshot failed")
+	}
+	return nil
+}
+
+// QueryJoinBySnapshotStatus queries whether a peer is joining a channel from a snapshot.
+//  Parameters:
+//  options hold optional request options
+//  Note: One target(peer) has to be specified using either WithTargetURLs or WithTargets request option
+//
+//  Returns:
+//  the status of the join, including the snapshot directory if a join is in progress
+func (rc *Client) QueryJoinBySnapshotStatus(options ...RequestOption) (*pb.JoinBySnapshotStatus, error) {
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.Targets) != 1 {
+		return nil, errors.New("only one target is supported")
+	}
+
+	reqCtx, cancel := rc.createRequestContext(opts, fab.PeerResponse, "QueryJoinBySnapshotStatus")
+	defer cancel()
+
+	return resource.QueryJoinBySnapshotStatus(reqCtx, opts.Targets[0], resource.WithRetry(opts.Retry))
+}
+
+// prepareSnapshotRequest prepares the request options and calculates the target peers in the same way as JoinChannel
+func (rc *Client) prepareSnapshotRequest(operation string, options ...RequestOption) (requestOptions, []fab.Peer, error) {
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return opts, nil, errors.WithMessagef(err, "failed to get opts for %s", operation)
+	}
+
+	targets, err := rc.calculateTargets(opts.Targets, opts.TargetFilter)
+	if err != nil {
+		return opts, nil, errors.WithMessagef(err, "failed to determine target peers for %s", operation)
+	}
+
+	if len(targets) == 0 {
+		return opts, nil, errors.WithStack(status.New(status.ClientStatus, status.NoPeersFound.ToInt32(), "no targets available", nil))
+	}
+
+	return opts, targets, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
+)
+
+func TestSnapshotRequests(t *testing.T) {
+	srv := &fcmocks.MockSnapshotServer{}
+	addr := srv.Start(testAddress)
+	defer srv.Stop()
+
+	ctx := setupTestContext("test", "Org1MSP")
+	ctx.SetCustomInfraProvider(comm.NewMockInfraProvider())
+	rc := setupResMgmtClient(t, ctx)
+
+	peer1, err := peer.New(fcmocks.NewMockEndpointConfig(), peer.WithURL("grpc://"+addr))
+	require.NoError(t, err)
+
+	require.NoError(t, rc.SubmitSnapshotRequest("mychannel", 20, WithTargets(peer1)))
+	require.NoError(t, rc.SubmitSnapshotRequest("mychannel", 10, WithTargets(peer1)))
+	require.NoError(t, rc.SubmitSnapshotRequest("otherchannel", 30, WithTargets(peer1)))
+
+	err = rc.SubmitSnapshotRequest("mychannel", 10, WithTargets(peer1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate snapshot request for block number 10")
+
+	pending, err := rc.QueryPendingSnapshots("mychannel", WithTargets(peer1))
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{10, 20}, pending)
+
+	require.NoError(t, rc.CancelSnapshotRequest("mychannel", 10, WithTargets(peer1)))
+	pending, err = rc.QueryPendingSnapshots("mychannel", WithTargets(peer1))
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{20}, pending)
+
+	err = rc.CancelSnapshotRequest("mychannel", 10, WithTargets(peer1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no snapshot request exists for block number 10")
+
+	srv.Error = errors.New("Test Error")
+	err = rc.SubmitSnapshotRequest("mychannel", 30, WithTargets(peer1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Test Error")
+	_, err = rc.QueryPendingSnapshots("mychannel", WithTargets(peer1))
+	assert.Error(t, err)
+}
+
+func TestSnapshotRequestsRequiredParameters(t *testing.T) {
+	rc := setupResMgmtClient(t, setupTestContext("test", "Org1MSP"))
+
+	assert.Error(t, rc.SubmitSnapshotRequest("", 10), "expected error for empty channel name")
+	assert.Error(t, rc.CancelSnapshotRequest("", 10), "expected error for empty channel name")
+	_, err := rc.QueryPendingSnapshots("")
+	assert.Error(t, err, "expected error for empty channel name")
+
+	_, err = rc.QueryPendingSnapshots("mychannel")
+	assert.Error(t, err, "expected error for missing target")
+
+	// default targets cannot be calculated for a different msp
+	rc = setupResMgmtClient(t, setupTestContext("test", "otherMSP"))
+	err = rc.SubmitSnapshotRequest("mychannel", 10)
+	require.Error(t, err)
+	s, ok := status.FromError(err)
+	assert.True(t, ok, "status code should be available")
+	assert.Equal(t, status.NoPeersFound.ToInt32(), s.Code, "code should be no peers found")
+}
+
+func TestJoinChannelBySnapshot(t *testing.T) {
+	srv := &fcmocks.MockEndorserServer{}
+	addr := srv.Start(testAddress)
+	defer srv.Stop()
+
+	rc := setupResMgmtClient(t, setupTestContext("test", "Org1MSP"))
+
+	peer1, err := peer.New(fcmocks.NewMockEndpointConfig(), peer.WithURL("grpc://"+addr))
+	require.NoError(t, err)
+
+	assert.Error(t, rc.JoinChannelBySnapshot("", WithTargets(peer1)), "expected error for empty snapshot directory")
+	require.NoError(t, rc.JoinChannelBySnapshot("/var/hyperledger/snapshots/completed/mychannel/10", WithTargets(peer1)))
+
+	joinStatus, err := rc.QueryJoinBySnapshotStatus(WithTargets(peer1))
+	require.NoError(t, err)
+	assert.False(t, joinStatus.InProgress)
+
+	_, err = rc.QueryJoinBySnapshotStatus()
+	assert.Error(t, err, "expected error for missing target")
+
+	srv.ProposalError = errors.New("Test Error")
+	err = rc.JoinChannelBySnapshot("/var/hyperledger/snapshots/completed/mychannel/10", WithTargets(peer1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Test Error")
+}
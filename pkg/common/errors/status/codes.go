@@ -60,6 +60,12 @@ const (
 
 	// PvtDataDisseminationFailed indicates that Gossip failed to disseminate private data to the required number of peers
 	PvtDataDisseminationFailed Code = 24
+
+	// EndorsementPolicyNotSatisfied indicates that the endorsements of a transaction do not satisfy the endorsement policy
+	EndorsementPolicyNotSatisfied Code = 25
+
+	// AdmissionRejected indicates that a request was rejected by the client-side admission control
+	AdmissionRejected Code = 26
 )
 
 // CodeName maps the codes in this packages to human-readable strings
@@ -78,6 +84,8 @@ var CodeName = map[int32]string{
 	12: "GENERIC_TRANSIENT",
 	23: "CHAINCODE_NAME_NOT_FOUND",
 	24: "PRIVATE_DATA_DISSEMINATION_FAILED",
+	25: "ENDORSEMENT_POLICY_NOT_SATISFIED",
+	26: "ADMISSION_REJECTED",
 }
 
 // ToInt32 cast to int32
@@ -14,6 +14,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	commtls "github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm/tls"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/metrics"
 	"google.golang.org/grpc"
@@ -155,9 +156,17 @@ type Providers interface {
 	InfraProvider() InfraProvider
 	EndpointConfig() EndpointConfig
 	MetricsProvider
+	TracerProvider
 }
 
 // MetricsProvider represents a provider of metrics.
 type MetricsProvider interface {
 	GetMetrics() *metrics.ClientMetrics
 }
+
+// TracerProvider represents a provider of the tracer used to create spans of the transaction flow.
+// TracerProvider is part of Providers, so implementations of Providers outside of the SDK must also implement
+// Tracer; an implementation which doesn't support tracing may return tracing.NoopTracer().
+type TracerProvider interface {
+	Tracer() tracing.Tracer
+}
@@ -11,6 +11,7 @@ import (
 	core "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	fab "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	msp "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	tracing "github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	metrics "github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/metrics"
 )
 
@@ -164,6 +165,20 @@ func (mr *MockProvidersMockRecorder) SigningManager() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SigningManager", reflect.TypeOf((*MockProviders)(nil).SigningManager))
 }
 
+// Tracer mocks base method
+func (m *MockProviders) Tracer() tracing.Tracer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tracer")
+	ret0, _ := ret[0].(tracing.Tracer)
+	return ret0
+}
+
+// Tracer indicates an expected call of Tracer
+func (mr *MockProvidersMockRecorder) Tracer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tracer", reflect.TypeOf((*MockProviders)(nil).Tracer))
+}
+
 // UserStore mocks base method
 func (m *MockProviders) UserStore() msp.UserStore {
 	m.ctrl.T.Helper()
@@ -414,6 +429,20 @@ func (mr *MockClientMockRecorder) SigningManager() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SigningManager", reflect.TypeOf((*MockClient)(nil).SigningManager))
 }
 
+// Tracer mocks base method
+func (m *MockClient) Tracer() tracing.Tracer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tracer")
+	ret0, _ := ret[0].(tracing.Tracer)
+	return ret0
+}
+
+// Tracer indicates an expected call of Tracer
+func (mr *MockClientMockRecorder) Tracer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tracer", reflect.TypeOf((*MockClient)(nil).Tracer))
+}
+
 // UserStore mocks base method
 func (m *MockClient) UserStore() msp.UserStore {
 	m.ctrl.T.Helper()
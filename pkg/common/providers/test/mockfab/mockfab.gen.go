@@ -12,6 +12,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	fab "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	tracing "github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	tls0 "github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm/tls"
 	metrics "github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/metrics"
 )
@@ -355,3 +356,17 @@ func (mr *MockProvidersMockRecorder) LocalDiscoveryProvider() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalDiscoveryProvider", reflect.TypeOf((*MockProviders)(nil).LocalDiscoveryProvider))
 }
+
+// Tracer mocks base method
+func (m *MockProviders) Tracer() tracing.Tracer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tracer")
+	ret0, _ := ret[0].(tracing.Tracer)
+	return ret0
+}
+
+// Tracer indicates an expected call of Tracer
+func (mr *MockProvidersMockRecorder) Tracer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tracer", reflect.TypeOf((*MockProviders)(nil).Tracer))
+}
@@ -0,0 +1,134 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tracing
+
+import (
+	"context"
+	"sync"
+)
+
+// RecordedSpan is a span recorded by the InMemoryTracer
+type RecordedSpan struct {
+	ID   int
+	Name string
+	// ParentID is the ID of the parent span, or zero if the span is a root span
+	ParentID   int
+	Attributes map[string]string
+	Errors     []error
+	Ended      bool
+}
+
+// InMemoryTracer records spans in memory. It is intended for tests.
+type InMemoryTracer struct {
+	mutex sync.RWMutex
+	spans []*RecordedSpan
+}
+
+// NewInMemoryTracer returns a new InMemoryTracer
+func NewInMemoryTracer() *InMemoryTracer {
+	return &InMemoryTracer{}
+}
+
+type inMemorySpanKey struct{}
+
+// Start creates a span, as a child of the span of this tracer contained in ctx if there is one
+func (t *InMemoryTracer) Start(ctx context.Context, spanName string, attrs ...Attribute) (context.Context, Span) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	span := &RecordedSpan{
+		ID:         len(t.spans) + 1,
+		Name:       spanName,
+		Attributes: make(map[string]string),
+	}
+	if parent, ok := ctx.Value(inMemorySpanKey{}).(*inMemorySpan); ok && parent.tracer == t {
+		span.ParentID = parent.id
+	}
+	for _, attr := range attrs {
+		span.Attributes[attr.Key] = attr.Value
+	}
+	t.spans = append(t.spans, span)
+
+	s := &inMemorySpan{tracer: t, id: span.ID}
+	return context.WithValue(ctx, inMemorySpanKey{}, s), s
+}
+
+// Spans returns copies of the spans recorded so far, in the order in which they were started
+func (t *InMemoryTracer) Spans() []RecordedSpan {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	spans := make([]RecordedSpan, len(t.spans))
+	for i, span := range t.spans {
+		spans[i] = copySpan(span)
+	}
+	return spans
+}
+
+// SpansByName returns copies of the recorded spans with the given name
+func (t *InMemoryTracer) SpansByName(name string) []RecordedSpan {
+	var spans []RecordedSpan
+	for _, span := range t.Spans() {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// Reset discards the recorded spans
+func (t *InMemoryTracer) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.spans = nil
+}
+
+func (t *InMemoryTracer) update(id int, update func(span *RecordedSpan)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// IDs are only valid until the tracer is reset
+	if id <= len(t.spans) {
+		update(t.spans[id-1])
+	}
+}
+
+func copySpan(span *RecordedSpan) RecordedSpan {
+	c := *span
+	c.Attributes = make(map[string]string, len(span.Attributes))
+	for k, v := range span.Attributes {
+		c.Attributes[k] = v
+	}
+	c.Errors = append([]error(nil), span.Errors...)
+	return c
+}
+
+type inMemorySpan struct {
+	tracer *InMemoryTracer
+	id     int
+}
+
+func (s *inMemorySpan) SetAttributes(attrs ...Attribute) {
+	s.tracer.update(s.id, func(span *RecordedSpan) {
+		for _, attr := range attrs {
+			span.Attributes[attr.Key] = attr.Value
+		}
+	})
+}
+
+func (s *inMemorySpan) RecordError(err error) {
+	s.tracer.update(s.id, func(span *RecordedSpan) {
+		span.Errors = append(span.Errors, err)
+	})
+}
+
+func (s *inMemorySpan) End() {
+	s.tracer.update(s.id, func(span *RecordedSpan) {
+		span.Ended = true
+	})
+}
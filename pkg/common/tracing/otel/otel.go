@@ -0,0 +1,78 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package otel adapts an OpenTelemetry tracer to the tracing abstraction of the SDK, so the spans of the
+// transaction flow are exported by the OpenTelemetry pipeline of the application, e.g. using the global
+// tracer provider of go.opentelemetry.io/otel:
+//
+//  sdk, err := fabsdk.New(configProvider, fabsdk.WithTracer(otel.NewTracerFromProvider(otelapi.GetTracerProvider())))
+//
+// The adapter is a separate module, so applications which don't use OpenTelemetry don't depend on it.
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
+)
+
+// InstrumentationName is the name of the instrumentation library to use when obtaining the OpenTelemetry tracer
+const InstrumentationName = "github.com/hyperledger/fabric-sdk-go"
+
+// NewTracer returns a tracer which creates the spans of the SDK using the given OpenTelemetry tracer.
+// Spans are started from the OpenTelemetry span contained in the context, so they become children of the
+// caller's span.
+func NewTracer(tracer trace.Tracer) tracing.Tracer {
+	return &otelTracer{tracer: tracer}
+}
+
+// NewTracerFromProvider returns a tracer which creates the spans of the SDK using the OpenTelemetry tracer
+// obtained from the given provider for InstrumentationName.
+func NewTracerFromProvider(provider trace.TracerProvider) tracing.Tracer {
+	return NewTracer(provider.Tracer(InstrumentationName))
+}
+
+type otelTracer struct {
+	tracer trace.Tracer
+}
+
+// Start creates an OpenTelemetry span as a child of the span contained in ctx
+func (t *otelTracer) Start(ctx context.Context, spanName string, attrs ...tracing.Attribute) (context.Context, tracing.Span) {
+	ctx, span := t.tracer.Start(ctx, spanName, trace.WithAttributes(toKeyValues(attrs)...))
+	return ctx, &otelSpan{span: span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+// SetAttributes sets attributes on the span
+func (s *otelSpan) SetAttributes(attrs ...tracing.Attribute) {
+	s.span.SetAttributes(toKeyValues(attrs)...)
+}
+
+// RecordError records the error as an event of the span and sets the status of the span to error
+func (s *otelSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+// End completes the span
+func (s *otelSpan) End() {
+	s.span.End()
+}
+
+func toKeyValues(attrs []tracing.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, len(attrs))
+	for i, attr := range attrs {
+		kvs[i] = attribute.String(attr.Key, attr.Value)
+	}
+	return kvs
+}
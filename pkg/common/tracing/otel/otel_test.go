@@ -0,0 +1,58 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	// the caller's span is the parent of the spans of the SDK
+	callerCtx, caller := provider.Tracer("app").Start(context.Background(), "caller")
+
+	ctx := tracing.ContextWithTracer(callerCtx, NewTracerFromProvider(provider))
+	parentCtx, parent := tracing.StartSpan(ctx, "parent", tracing.String(tracing.ChannelKey, "mychannel"))
+	_, child := tracing.StartSpan(parentCtx, "child")
+	child.SetAttributes(tracing.String(tracing.TxIDKey, "txid"))
+	tracing.EndSpan(child, errors.New("child error"))
+	tracing.EndSpan(parent, nil)
+	caller.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	childSpan, parentSpan, callerSpan := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "parent", parentSpan.Name())
+	assert.Equal(t, InstrumentationName, parentSpan.InstrumentationLibrary().Name)
+	assert.Equal(t, callerSpan.SpanContext().SpanID(), parentSpan.Parent().SpanID())
+	assert.Equal(t, callerSpan.SpanContext().TraceID(), parentSpan.SpanContext().TraceID())
+	assert.Contains(t, parentSpan.Attributes(), attribute.String(tracing.ChannelKey, "mychannel"))
+	assert.Equal(t, codes.Unset, parentSpan.Status().Code)
+
+	assert.Equal(t, "child", childSpan.Name())
+	assert.Equal(t, parentSpan.SpanContext().SpanID(), childSpan.Parent().SpanID())
+	assert.Contains(t, childSpan.Attributes(), attribute.String(tracing.TxIDKey, "txid"))
+	assert.Equal(t, codes.Error, childSpan.Status().Code)
+	assert.Equal(t, "child error", childSpan.Status().Description)
+	require.Len(t, childSpan.Events(), 1)
+	assert.Equal(t, "exception", childSpan.Events()[0].Name)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package tracing provides the distributed tracing abstraction used by the SDK.
+//
+// The SDK creates spans for the steps of the transaction flow (endorser selection, endorsement, signature
+// validation, ordering and the wait for the commit event) using the Tracer configured with fabsdk.WithTracer.
+// The Tracer and Span interfaces are modelled on the OpenTelemetry tracing API. An OpenTelemetry tracer is
+// plugged in using the adapter of the github.com/hyperledger/fabric-sdk-go/pkg/common/tracing/otel module,
+// which is a separate module so that the SDK doesn't depend on OpenTelemetry.
+//
+// Spans are started from the request context, which is derived from the parent context passed to the clients
+// (e.g. channel.WithParentContext), so spans of the SDK become children of the caller's span.
+package tracing
+
+import (
+	"context"
+)
+
+// Attribute keys set on the spans of the SDK
+const (
+	ChannelKey          = "fabric.channel"
+	ChaincodeKey        = "fabric.chaincode"
+	FunctionKey         = "fabric.function"
+	TxIDKey             = "fabric.txid"
+	TxValidationCodeKey = "fabric.tx.validation_code"
+	PeerURLKey          = "fabric.peer.url"
+	OrdererURLKey       = "fabric.orderer.url"
+)
+
+// Attribute is a key/value pair describing a span
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// String creates an attribute
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer creates spans
+type Tracer interface {
+	// Start creates a span, as a child of the span contained in ctx if there is one, and returns
+	// a context containing the new span
+	Start(ctx context.Context, spanName string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a single operation within a trace
+type Span interface {
+	// SetAttributes sets attributes on the span
+	SetAttributes(attrs ...Attribute)
+	// RecordError records an error which occurred during the operation
+	RecordError(err error)
+	// End completes the span
+	End()
+}
+
+type tracerKey struct{}
+
+// ContextWithTracer returns a copy of ctx carrying the given tracer. Request contexts created by the SDK carry
+// the tracer of the SDK, which allows spans to be created at any level of the transaction flow.
+func ContextWithTracer(ctx context.Context, tracer Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, tracer)
+}
+
+// TracerFromContext returns the tracer carried by ctx, or a tracer which doesn't record any spans if
+// ctx doesn't carry one
+func TracerFromContext(ctx context.Context) Tracer {
+	if ctx == nil {
+		return noopTracer{}
+	}
+	if tracer, ok := ctx.Value(tracerKey{}).(Tracer); ok && tracer != nil {
+		return tracer
+	}
+	return noopTracer{}
+}
+
+// StartSpan starts a span using the tracer carried by ctx
+func StartSpan(ctx context.Context, spanName string, attrs ...Attribute) (context.Context, Span) {
+	return TracerFromContext(ctx).Start(ctx, spanName, attrs...)
+}
+
+// EndSpan records the error, if any, and ends the span
+func EndSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// NoopTracer returns a tracer which doesn't record any spans
+func NoopTracer() Tracer {
+	return noopTracer{}
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, spanName string, attrs ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+
+func (noopSpan) RecordError(err error) {}
+
+func (noopSpan) End() {}
@@ -0,0 +1,65 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartSpan(t *testing.T) {
+	// Without a tracer in the context spans are not recorded
+	ctx, span := StartSpan(context.Background(), "noop")
+	span.SetAttributes(String("key", "value"))
+	EndSpan(span, errors.New("error"))
+	assert.Equal(t, context.Background(), ctx)
+
+	tracer := NewInMemoryTracer()
+	ctx = ContextWithTracer(context.Background(), tracer)
+
+	parentCtx, parent := StartSpan(ctx, "parent", String(ChannelKey, "mychannel"))
+	_, child := StartSpan(parentCtx, "child")
+	child.SetAttributes(String(TxIDKey, "txid"))
+	EndSpan(child, errors.New("child error"))
+
+	_, sibling := StartSpan(ctx, "sibling")
+	EndSpan(parent, nil)
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 3)
+
+	assert.Equal(t, "parent", spans[0].Name)
+	assert.Zero(t, spans[0].ParentID)
+	assert.Equal(t, "mychannel", spans[0].Attributes[ChannelKey])
+	assert.True(t, spans[0].Ended)
+	assert.Empty(t, spans[0].Errors)
+
+	assert.Equal(t, spans[0].ID, spans[1].ParentID)
+	assert.Equal(t, "txid", spans[1].Attributes[TxIDKey])
+	assert.Len(t, spans[1].Errors, 1)
+	assert.True(t, spans[1].Ended)
+
+	assert.Zero(t, spans[2].ParentID)
+	assert.False(t, spans[2].Ended)
+	sibling.End()
+
+	assert.Len(t, tracer.SpansByName("child"), 1)
+	tracer.Reset()
+	assert.Empty(t, tracer.Spans())
+}
+
+func TestTracerFromContext(t *testing.T) {
+	assert.Equal(t, NoopTracer(), TracerFromContext(context.Background()))
+	assert.Equal(t, NoopTracer(), TracerFromContext(nil)) //nolint: staticcheck
+
+	tracer := NewInMemoryTracer()
+	assert.Equal(t, tracer, TracerFromContext(ContextWithTracer(context.Background(), tracer)))
+}
@@ -8,6 +8,7 @@ package context
 
 import (
 	reqContext "context"
+	"sync"
 
 	"github.com/pkg/errors"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/metrics"
 )
 
@@ -75,6 +77,7 @@ type Provider struct {
 	infraProvider          fab.InfraProvider
 	channelProvider        fab.ChannelProvider
 	clientMetrics          *metrics.ClientMetrics
+	tracer                 tracing.Tracer
 }
 
 // CryptoSuite returns the BCCSP provider of sdk.
@@ -127,6 +130,11 @@ func (c *Provider) GetMetrics() *metrics.ClientMetrics {
 	return c.clientMetrics
 }
 
+// Tracer returns the tracer of the SDK, or nil if tracing is not enabled
+func (c *Provider) Tracer() tracing.Tracer {
+	return c.tracer
+}
+
 //SDKContextParams parameter for creating FabContext
 type SDKContextParams func(opts *Provider)
 
@@ -207,6 +215,13 @@ func WithClientMetrics(cm *metrics.ClientMetrics) SDKContextParams {
 	}
 }
 
+//WithTracer sets tracer to Context Provider
+func WithTracer(tracer tracing.Tracer) SDKContextParams {
+	return func(ctx *Provider) {
+		ctx.tracer = tracer
+	}
+}
+
 //NewProvider creates new context client provider
 // Not be used by end developers, fabsdk package use only
 func NewProvider(params ...SDKContextParams) *Provider {
@@ -311,6 +326,15 @@ func WithParent(context reqContext.Context) ReqContextOptions {
 	}
 }
 
+//WithSpan starts a span with the given name and attributes, using the tracer of the client, which is
+//ended when the request context is cancelled
+func WithSpan(name string, attrs ...tracing.Attribute) ReqContextOptions {
+	return func(ctx *requestContextOpts) {
+		ctx.spanName = name
+		ctx.spanAttrs = attrs
+	}
+}
+
 //ReqContextOptions parameter for creating requestContext
 type ReqContextOptions func(opts *requestContextOpts)
 
@@ -318,6 +342,8 @@ type requestContextOpts struct {
 	timeoutType   fab.TimeoutType
 	timeout       time.Duration
 	parentContext reqContext.Context
+	spanName      string
+	spanAttrs     []tracing.Attribute
 }
 
 // NewRequest creates a request-scoped context.
@@ -346,9 +372,21 @@ func NewRequest(client context.Client, options ...ReqContextOptions) (reqContext
 
 	ctx := reqContext.WithValue(parentContext, reqContextCommManager, client.InfraProvider().CommManager())
 	ctx = reqContext.WithValue(ctx, reqContextClient, client)
+	if tracer := client.Tracer(); tracer != nil {
+		ctx = tracing.ContextWithTracer(ctx, tracer)
+	}
 	ctx, cancel := reqContext.WithTimeout(ctx, timeout)
 
-	return ctx, cancel
+	if reqCtxOpts.spanName == "" {
+		return ctx, cancel
+	}
+
+	ctx, span := tracing.StartSpan(ctx, reqCtxOpts.spanName, reqCtxOpts.spanAttrs...)
+	var once sync.Once
+	return ctx, func() {
+		once.Do(span.End)
+		cancel()
+	}
 }
 
 // RequestCommManager extracts the CommManager from the request-scoped context.
@@ -139,6 +139,28 @@ func createMSPManager(ctx Context, cfg fab.ChannelCfg) (msp.MSPManager, []string
 	return mspManager, mspNames, nil
 }
 
+// NewMSPManager creates an MSP manager from the MSP configuration of a channel, which may be used
+// to deserialize the identities of channel members and to check them against policy principals.
+func NewMSPManager(cfg fab.ChannelCfg, cs core.CryptoSuite) (msp.MSPManager, error) {
+	return NewMSPManagerFromConfigs(cfg.MSPs(), cs)
+}
+
+// NewMSPManagerFromConfigs creates an MSP manager from the given MSP configurations, e.g. the configurations
+// of the orderer organizations of a channel
+func NewMSPManagerFromConfigs(mspConfigs []*mb.MSPConfig, cs core.CryptoSuite) (msp.MSPManager, error) {
+	msps, err := loadMSPs(mspConfigs, cs)
+	if err != nil {
+		return nil, errors.WithMessage(err, "load MSPs from config failed")
+	}
+
+	mspManager := msp.NewMSPManager()
+	if err := mspManager.Setup(msps); err != nil {
+		return nil, errors.WithMessage(err, "MSPManager Setup failed")
+	}
+
+	return mspManager, nil
+}
+
 func loadMSPs(mspConfigs []*mb.MSPConfig, cs core.CryptoSuite) ([]msp.MSP, error) {
 	logger.Debugf("loadMSPs - start number of msps=%d", len(mspConfigs))
 
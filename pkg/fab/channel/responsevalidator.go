@@ -8,8 +8,8 @@ package channel
 
 import (
 	"github.com/golang/protobuf/proto"
-	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
 	"github.com/pkg/errors"
 )
 
@@ -54,7 +54,7 @@ func (tprv *TransactionProposalResponseVerifier) Match(transactionProposalRespon
 		}
 
 		if !proto.Equal(block.Data, b.Data) {
-			return errors.WithStack(status.New(status.EndorserClientStatus, status.EndorsementMismatch.ToInt32(), "payloads for config block do not match", nil))
+			return errors.WithStack(txn.NewEndorsementMismatchStatus("payloads for config block do not match", transactionProposalResponses))
 		}
 	}
 
@@ -138,6 +138,14 @@ func New(channelID string, options ...Option) (*ChannelConfig, error) {
 	return &ChannelConfig{channelID: channelID, opts: opts}, nil
 }
 
+// FromBlock extracts the channel configuration from a config block, without querying the network
+func FromBlock(channelID string, block *common.Block) (*ChannelCfg, error) {
+	if len(block.GetData().GetData()) == 0 {
+		return nil, errors.New("expected data in config block")
+	}
+	return extractConfig(channelID, block)
+}
+
 // QueryBlock returns channel configuration
 func (c *ChannelConfig) QueryBlock(reqCtx reqContext.Context) (*common.Block, error) {
 
@@ -48,6 +48,26 @@ func InfoFrom(fromBlock uint64) *ab.SeekInfo {
 	return newSeekInfo(seekFromPos(fromBlock), maxPos)
 }
 
+// InfoRange returns a SeekInfo struct that indicates to the deliver server
+// that we want the blocks from fromBlock to toBlock, inclusive. The deliver server
+// responds with a NOT_FOUND status if a block of the range has not been committed yet.
+func InfoRange(fromBlock, toBlock uint64) *ab.SeekInfo {
+	seekInfo := newSeekInfo(seekFromPos(fromBlock), seekFromPos(toBlock))
+	seekInfo.Behavior = ab.SeekInfo_FAIL_IF_NOT_READY
+	return seekInfo
+}
+
+// contentTypeHeaderWithSig is the content_type field (5) of SeekInfo set to HEADER_WITH_SIG (1). It is encoded
+// as an unrecognized field since the SeekInfo message of the protos used by the SDK predates the field.
+var contentTypeHeaderWithSig = []byte{5<<3 | 0, 1}
+
+// WithHeadersOnly requests the deliver server to send the blocks of the given SeekInfo with their header and
+// metadata only. Deliver servers which don't support the content type ignore it and send complete blocks.
+func WithHeadersOnly(seekInfo *ab.SeekInfo) *ab.SeekInfo {
+	seekInfo.XXX_unrecognized = append(seekInfo.XXX_unrecognized, contentTypeHeaderWithSig...)
+	return seekInfo
+}
+
 func seekFromPos(fromBlock uint64) *ab.SeekPosition {
 	return &ab.SeekPosition{
 		Type: &ab.SeekPosition_Specified{
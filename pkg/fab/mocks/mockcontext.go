@@ -15,6 +15,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/metrics"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
@@ -40,6 +41,7 @@ type MockProviderContext struct {
 	localDiscoveryProvider fab.LocalDiscoveryProvider
 	infraProvider          fab.InfraProvider
 	channelProvider        fab.ChannelProvider
+	tracer                 tracing.Tracer
 }
 
 // ProviderUsersOptions ...
@@ -120,6 +122,11 @@ func (pc *MockProviderContext) SetCryptoSuiteConfig(config core.CryptoSuiteConfi
 	pc.cryptoSuiteConfig = config
 }
 
+// SetCryptoSuite sets the mock crypto suite.
+func (pc *MockProviderContext) SetCryptoSuite(cryptoSuite core.CryptoSuite) {
+	pc.cryptoSuite = cryptoSuite
+}
+
 // SetEndpointConfig sets the mock endpoint configuration.
 func (pc *MockProviderContext) SetEndpointConfig(config fab.EndpointConfig) {
 	pc.endpointConfig = config
@@ -211,6 +218,16 @@ func (pc *MockProviderContext) GetMetrics() *metrics.ClientMetrics {
 	return &metrics.ClientMetrics{}
 }
 
+// Tracer returns the mock tracer, which is nil unless set using SetTracer
+func (pc *MockProviderContext) Tracer() tracing.Tracer {
+	return pc.tracer
+}
+
+//SetTracer sets the tracer for unit-test purposes
+func (pc *MockProviderContext) SetTracer(tracer tracing.Tracer) {
+	pc.tracer = tracer
+}
+
 // MockContext holds core providers and identity to enable mocking.
 type MockContext struct {
 	*MockProviderContext
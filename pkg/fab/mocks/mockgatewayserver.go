@@ -0,0 +1,253 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocks
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	gp "github.com/hyperledger/fabric-protos-go/gateway"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/test"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// MockGatewayServer is a mock implementation of the peer Gateway service
+type MockGatewayServer struct {
+	Creds             credentials.TransportCredentials
+	Payload           []byte
+	EvaluateError     error
+	EndorseError      error
+	SubmitError       error
+	CommitStatusError error
+	TxValidationCode  pb.TxValidationCode
+	BlockNumber       uint64
+	Events            []*gp.ChaincodeEventsResponse
+	wg                sync.WaitGroup
+	mutex             sync.RWMutex
+	lastEvaluate      *gp.EvaluateRequest
+	lastEndorse       *gp.EndorseRequest
+	lastSubmit        *gp.SubmitRequest
+	lastEventsRequest *gp.ChaincodeEventsRequest
+	srv               *grpc.Server
+}
+
+// Evaluate returns the configured payload, or EvaluateError if set
+func (m *MockGatewayServer) Evaluate(ctx context.Context, request *gp.EvaluateRequest) (*gp.EvaluateResponse, error) {
+	m.mutex.Lock()
+	m.lastEvaluate = request
+	m.mutex.Unlock()
+
+	if m.EvaluateError != nil {
+		return nil, m.EvaluateError
+	}
+
+	return &gp.EvaluateResponse{
+		Result: &pb.Response{Status: 200, Payload: m.Payload},
+	}, nil
+}
+
+// Endorse returns a prepared transaction, built from the proposal, carrying the configured payload
+func (m *MockGatewayServer) Endorse(ctx context.Context, request *gp.EndorseRequest) (*gp.EndorseResponse, error) {
+	m.mutex.Lock()
+	m.lastEndorse = request
+	m.mutex.Unlock()
+
+	if m.EndorseError != nil {
+		return nil, m.EndorseError
+	}
+
+	env, err := m.createPreparedTransaction(request.ProposedTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gp.EndorseResponse{PreparedTransaction: env}, nil
+}
+
+// Submit accepts the prepared transaction, or returns SubmitError if set
+func (m *MockGatewayServer) Submit(ctx context.Context, request *gp.SubmitRequest) (*gp.SubmitResponse, error) {
+	m.mutex.Lock()
+	m.lastSubmit = request
+	m.mutex.Unlock()
+
+	if m.SubmitError != nil {
+		return nil, m.SubmitError
+	}
+
+	if request.PreparedTransaction == nil || len(request.PreparedTransaction.Signature) == 0 {
+		return nil, errors.New("prepared transaction must be signed")
+	}
+
+	return &gp.SubmitResponse{}, nil
+}
+
+// CommitStatus returns the configured validation code and block number
+func (m *MockGatewayServer) CommitStatus(ctx context.Context, request *gp.SignedCommitStatusRequest) (*gp.CommitStatusResponse, error) {
+	if m.CommitStatusError != nil {
+		return nil, m.CommitStatusError
+	}
+
+	return &gp.CommitStatusResponse{
+		Result:      m.TxValidationCode,
+		BlockNumber: m.BlockNumber,
+	}, nil
+}
+
+// ChaincodeEvents sends the configured chaincode events and then keeps the stream open until the client disconnects
+func (m *MockGatewayServer) ChaincodeEvents(request *gp.SignedChaincodeEventsRequest, stream gp.Gateway_ChaincodeEventsServer) error {
+	eventsRequest := &gp.ChaincodeEventsRequest{}
+	if err := proto.Unmarshal(request.Request, eventsRequest); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	m.lastEventsRequest = eventsRequest
+	m.mutex.Unlock()
+
+	for _, resp := range m.Events {
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+
+	<-stream.Context().Done()
+	return nil
+}
+
+// LastEvaluateRequest returns the last Evaluate request received by the server
+func (m *MockGatewayServer) LastEvaluateRequest() *gp.EvaluateRequest {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.lastEvaluate
+}
+
+// LastEndorseRequest returns the last Endorse request received by the server
+func (m *MockGatewayServer) LastEndorseRequest() *gp.EndorseRequest {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.lastEndorse
+}
+
+// LastSubmitRequest returns the last Submit request received by the server
+func (m *MockGatewayServer) LastSubmitRequest() *gp.SubmitRequest {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.lastSubmit
+}
+
+// LastChaincodeEventsRequest returns the last ChaincodeEvents request received by the server
+func (m *MockGatewayServer) LastChaincodeEventsRequest() *gp.ChaincodeEventsRequest {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.lastEventsRequest
+}
+
+func (m *MockGatewayServer) createPreparedTransaction(signedProposal *pb.SignedProposal) (*common.Envelope, error) {
+	if signedProposal == nil {
+		return nil, errors.New("proposed transaction is required")
+	}
+
+	proposal, err := protoutil.UnmarshalProposal(signedProposal.ProposalBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	hdr, err := protoutil.UnmarshalHeader(proposal.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	shdr, err := protoutil.UnmarshalSignatureHeader(hdr.SignatureHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	prpBytes, err := protoutil.GetBytesProposalResponsePayload(nil, &pb.Response{Status: 200, Payload: m.Payload}, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ProposalResponse{
+		Response:    &pb.Response{Status: 200, Payload: m.Payload},
+		Payload:     prpBytes,
+		Endorsement: &pb.Endorsement{Endorser: []byte("endorser"), Signature: []byte("signature")},
+	}
+
+	env, err := protoutil.CreateSignedTx(proposal, &unsignedCreator{creator: shdr.Creator}, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	// The client is responsible for signing the prepared transaction
+	env.Signature = nil
+
+	return env, nil
+}
+
+// Start the mock gateway server
+func (m *MockGatewayServer) Start(address string) string {
+	if m.srv != nil {
+		panic("MockGatewayServer already started")
+	}
+
+	// pass in TLS creds if present
+	if m.Creds != nil {
+		m.srv = grpc.NewServer(grpc.Creds(m.Creds))
+	} else {
+		m.srv = grpc.NewServer()
+	}
+
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		panic(fmt.Sprintf("Error starting GatewayServer %s", err))
+	}
+	addr := lis.Addr().String()
+
+	test.Logf("Starting MockGatewayServer [%s]", addr)
+	gp.RegisterGatewayServer(m.srv, m)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		if err := m.srv.Serve(lis); err != nil {
+			test.Logf("StartMockGatewayServer failed [%s]", err)
+		}
+	}()
+
+	return addr
+}
+
+// Stop the mock gateway server and wait for completion.
+func (m *MockGatewayServer) Stop() {
+	if m.srv == nil {
+		panic("MockGatewayServer not started")
+	}
+
+	m.srv.Stop()
+	m.wg.Wait()
+	m.srv = nil
+}
+
+// unsignedCreator satisfies protoutil.Signer using the creator of the proposal, leaving the signature empty
+type unsignedCreator struct {
+	creator []byte
+}
+
+func (s *unsignedCreator) Sign(msg []byte) ([]byte, error) {
+	return nil, nil
+}
+
+func (s *unsignedCreator) Serialize() ([]byte, error) {
+	return s.creator, nil
+}
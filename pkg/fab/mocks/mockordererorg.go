@@ -0,0 +1,240 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	mb "github.com/hyperledger/fabric-protos-go/msp"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	channelConfig "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/policydsl"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protoutil"
+)
+
+// MockOrdererOrg is an orderer organization with a CA and an orderer identity issued by the CA,
+// which is used to sign mock blocks. It may also be used as an application organization, in which
+// case the identity signs mock transactions and endorsements.
+type MockOrdererOrg struct {
+	MSPID    string
+	RootCert []byte
+	Cert     []byte
+	key      *ecdsa.PrivateKey
+}
+
+// NewMockOrdererOrg creates an orderer organization with a new CA and orderer identity
+func NewMockOrdererOrg(mspID string) *MockOrdererOrg {
+	caKey := newECDSAKey()
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca." + mspID},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SubjectKeyId:          []byte{1, 2, 3, 4},
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		panic(err)
+	}
+
+	key := newECDSAKey()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "orderer." + mspID},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return &MockOrdererOrg{
+		MSPID:    mspID,
+		RootCert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		Cert:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:      key,
+	}
+}
+
+// MSPConfig returns the MSP config of the organization
+func (o *MockOrdererOrg) MSPConfig() *mb.MSPConfig {
+	return &mb.MSPConfig{
+		Config: marshalOrPanic(&mb.FabricMSPConfig{Name: o.MSPID, RootCerts: [][]byte{o.RootCert}, Admins: [][]byte{o.Cert}}),
+	}
+}
+
+// SerializedIdentity returns the serialized identity of the orderer
+func (o *MockOrdererOrg) SerializedIdentity() []byte {
+	return marshalOrPanic(&mb.SerializedIdentity{Mspid: o.MSPID, IdBytes: o.Cert})
+}
+
+// Sign signs the message with the key of the orderer. The signature is low-S, as required by the MSPs.
+func (o *MockOrdererOrg) Sign(msg []byte) []byte {
+	digest := sha256.Sum256(msg)
+	r, s, err := ecdsa.Sign(rand.Reader, o.key, digest[:])
+	if err != nil {
+		panic(err)
+	}
+
+	curveOrder := elliptic.P256().Params().N
+	if s.Cmp(new(big.Int).Rsh(curveOrder, 1)) > 0 {
+		s.Sub(curveOrder, s)
+	}
+	signature, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		panic(err)
+	}
+	return signature
+}
+
+// NewMockChainedBlock creates a block which follows the given block header, or block 0 if the header is nil
+func NewMockChainedBlock(previous *common.BlockHeader, data ...[]byte) *common.Block {
+	var number uint64
+	var previousHash []byte
+	if previous != nil {
+		number = previous.Number + 1
+		previousHash = protoutil.BlockHeaderHash(previous)
+	}
+
+	block := protoutil.NewBlock(number, previousHash)
+	block.Data.Data = data
+	block.Header.DataHash = protoutil.BlockDataHash(block.Data)
+	return block
+}
+
+// NewMockOrdererConfigBlock creates a config block which follows the given block header, or block 0 if the header
+// is nil. The config contains the given orderer organizations, each with a Writers policy satisfied by its members,
+// and a BlockValidation policy which requires the Writers of the organizations according to the given rule.
+func NewMockOrdererConfigBlock(channelID string, previous *common.BlockHeader, rule common.ImplicitMetaPolicy_Rule, orgs ...*MockOrdererOrg) *common.Block {
+	return NewMockChannelConfigBlock(channelID, previous, rule, orgs)
+}
+
+// NewMockChannelConfigBlock creates a config block like NewMockOrdererConfigBlock, which also contains the given
+// application organizations
+func NewMockChannelConfigBlock(channelID string, previous *common.BlockHeader, rule common.ImplicitMetaPolicy_Rule, orderers []*MockOrdererOrg, peerOrgs ...*MockOrdererOrg) *common.Block {
+	ordererGroup := &common.ConfigGroup{
+		Groups: make(map[string]*common.ConfigGroup),
+		Policies: map[string]*common.ConfigPolicy{
+			"BlockValidation": {Policy: &common.Policy{
+				Type:  int32(common.Policy_IMPLICIT_META),
+				Value: marshalOrPanic(&common.ImplicitMetaPolicy{SubPolicy: "Writers", Rule: rule}),
+			}},
+		},
+	}
+	for _, org := range orderers {
+		ordererGroup.Groups[org.MSPID] = newMockOrgGroup(org)
+	}
+
+	groups := map[string]*common.ConfigGroup{channelConfig.OrdererGroupKey: ordererGroup}
+	if len(peerOrgs) > 0 {
+		applicationGroup := &common.ConfigGroup{Groups: make(map[string]*common.ConfigGroup)}
+		for _, org := range peerOrgs {
+			applicationGroup.Groups[org.MSPID] = newMockOrgGroup(org)
+		}
+		groups[channelConfig.ApplicationGroupKey] = applicationGroup
+	}
+
+	config := &common.ConfigEnvelope{Config: &common.Config{ChannelGroup: &common.ConfigGroup{Groups: groups}}}
+	payload := &common.Payload{
+		Header: &common.Header{ChannelHeader: marshalOrPanic(&common.ChannelHeader{Type: int32(common.HeaderType_CONFIG), ChannelId: channelID})},
+		Data:   marshalOrPanic(config),
+	}
+	return NewMockChainedBlock(previous, marshalOrPanic(&common.Envelope{Payload: marshalOrPanic(payload)}))
+}
+
+func newMockOrgGroup(org *MockOrdererOrg) *common.ConfigGroup {
+	return &common.ConfigGroup{
+		Values: map[string]*common.ConfigValue{
+			channelConfig.MSPKey: {Value: marshalOrPanic(org.MSPConfig())},
+		},
+		Policies: map[string]*common.ConfigPolicy{
+			"Writers": {Policy: &common.Policy{
+				Type:  int32(common.Policy_SIGNATURE),
+				Value: marshalOrPanic(policydsl.SignedByMspMember(org.MSPID)),
+			}},
+		},
+	}
+}
+
+// NewMockEndorserTransaction creates an envelope of a transaction invoking the "example" chaincode, which is
+// signed by the member of the creator organization and endorsed by the members of the endorser organizations.
+// It returns the marshalled envelope and the transaction ID.
+func NewMockEndorserTransaction(channelID string, creator *MockOrdererOrg, endorsers ...*MockOrdererOrg) ([]byte, string) {
+	cis := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		ChaincodeId: &pb.ChaincodeID{Name: "example"},
+		Input:       &pb.ChaincodeInput{Args: [][]byte{[]byte("invoke"), []byte("a")}},
+	}}
+	proposal, txID, err := protoutil.CreateChaincodeProposal(common.HeaderType_ENDORSER_TRANSACTION, channelID, cis, creator.SerializedIdentity())
+	if err != nil {
+		panic(err)
+	}
+
+	var responses []*pb.ProposalResponse
+	for _, endorser := range endorsers {
+		response, err := protoutil.CreateProposalResponse(proposal.Header, proposal.Payload, &pb.Response{Status: 200, Payload: []byte("result")},
+			nil, nil, &pb.ChaincodeID{Name: "example", Version: "v1"}, &mockOrgSigner{org: endorser})
+		if err != nil {
+			panic(err)
+		}
+		responses = append(responses, response)
+	}
+
+	envelope, err := protoutil.CreateSignedTx(proposal, &mockOrgSigner{org: creator}, responses...)
+	if err != nil {
+		panic(err)
+	}
+	return marshalOrPanic(envelope), txID
+}
+
+// SignMockBlock replaces the orderer signatures of the block with signatures of the orderers of the given
+// organizations, and sets the index of the last config block in the signed metadata
+func SignMockBlock(block *common.Block, lastConfig uint64, orgs ...*MockOrdererOrg) {
+	value := marshalOrPanic(&common.OrdererBlockMetadata{LastConfig: &common.LastConfig{Index: lastConfig}})
+	md := &common.Metadata{Value: value}
+	for _, org := range orgs {
+		shdr := marshalOrPanic(&common.SignatureHeader{Creator: org.SerializedIdentity(), Nonce: []byte("nonce")})
+		data := append(append(append([]byte{}, value...), shdr...), protoutil.BlockHeaderBytes(block.Header)...)
+		md.Signatures = append(md.Signatures, &common.MetadataSignature{SignatureHeader: shdr, Signature: org.Sign(data)})
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_SIGNATURES] = marshalOrPanic(md)
+}
+
+// mockOrgSigner signs as the member of an organization
+type mockOrgSigner struct {
+	org *MockOrdererOrg
+}
+
+func (s *mockOrgSigner) Sign(msg []byte) ([]byte, error) {
+	return s.org.Sign(msg), nil
+}
+
+func (s *mockOrgSigner) Serialize() ([]byte, error) {
+	return s.org.SerializedIdentity(), nil
+}
+
+func newECDSAKey() *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
@@ -0,0 +1,174 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocks
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/test"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// MockSnapshotServer is a mock snapshot server which keeps track of the pending snapshot requests of channels
+type MockSnapshotServer struct {
+	Creds credentials.TransportCredentials
+	// Error is returned by all operations if set
+	Error   error
+	mutex   sync.RWMutex
+	pending map[string]map[uint64]bool
+	wg      sync.WaitGroup
+	srv     *grpc.Server
+}
+
+// Generate adds a pending snapshot request for the channel and block of the request
+func (m *MockSnapshotServer) Generate(ctx context.Context, signedRequest *pb.SignedSnapshotRequest) (*empty.Empty, error) {
+	request, err := m.unmarshalRequest(signedRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.pending == nil {
+		m.pending = make(map[string]map[uint64]bool)
+	}
+	if m.pending[request.ChannelId] == nil {
+		m.pending[request.ChannelId] = make(map[uint64]bool)
+	}
+	if m.pending[request.ChannelId][request.BlockNumber] {
+		return nil, errors.Errorf("duplicate snapshot request for block number %d", request.BlockNumber)
+	}
+	m.pending[request.ChannelId][request.BlockNumber] = true
+
+	return &empty.Empty{}, nil
+}
+
+// Cancel removes the pending snapshot request for the channel and block of the request
+func (m *MockSnapshotServer) Cancel(ctx context.Context, signedRequest *pb.SignedSnapshotRequest) (*empty.Empty, error) {
+	request, err := m.unmarshalRequest(signedRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.pending[request.ChannelId][request.BlockNumber] {
+		return nil, errors.Errorf("no snapshot request exists for block number %d", request.BlockNumber)
+	}
+	delete(m.pending[request.ChannelId], request.BlockNumber)
+
+	return &empty.Empty{}, nil
+}
+
+// QueryPendings returns the block numbers of the pending snapshot requests for the channel of the query
+func (m *MockSnapshotServer) QueryPendings(ctx context.Context, signedRequest *pb.SignedSnapshotRequest) (*pb.QueryPendingSnapshotsResponse, error) {
+	if m.Error != nil {
+		return nil, m.Error
+	}
+
+	query := &pb.SnapshotQuery{}
+	if err := proto.Unmarshal(signedRequest.Request, query); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal snapshot query")
+	}
+	if err := checkSignedSnapshotRequest(query.SignatureHeader.GetCreator(), query.ChannelId, signedRequest); err != nil {
+		return nil, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	blockNumbers := []uint64{}
+	for blockNumber := range m.pending[query.ChannelId] {
+		blockNumbers = append(blockNumbers, blockNumber)
+	}
+	sort.Slice(blockNumbers, func(i, j int) bool { return blockNumbers[i] < blockNumbers[j] })
+
+	return &pb.QueryPendingSnapshotsResponse{BlockNumbers: blockNumbers}, nil
+}
+
+func (m *MockSnapshotServer) unmarshalRequest(signedRequest *pb.SignedSnapshotRequest) (*pb.SnapshotRequest, error) {
+	if m.Error != nil {
+		return nil, m.Error
+	}
+
+	request := &pb.SnapshotRequest{}
+	if err := proto.Unmarshal(signedRequest.Request, request); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal snapshot request")
+	}
+	if err := checkSignedSnapshotRequest(request.SignatureHeader.GetCreator(), request.ChannelId, signedRequest); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// checkSignedSnapshotRequest checks that the request is complete, since the mock server doesn't verify signatures
+func checkSignedSnapshotRequest(creator []byte, channelID string, signedRequest *pb.SignedSnapshotRequest) error {
+	if len(creator) == 0 {
+		return errors.New("creator is missing")
+	}
+	if channelID == "" {
+		return errors.New("missing channel ID")
+	}
+	if len(signedRequest.Signature) == 0 {
+		return errors.New("signature is missing")
+	}
+	return nil
+}
+
+// Start the mock snapshot server
+func (m *MockSnapshotServer) Start(address string) string {
+	if m.srv != nil {
+		panic("MockSnapshotServer already started")
+	}
+
+	// pass in TLS creds if present
+	if m.Creds != nil {
+		m.srv = grpc.NewServer(grpc.Creds(m.Creds))
+	} else {
+		m.srv = grpc.NewServer()
+	}
+
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		panic(fmt.Sprintf("Error starting SnapshotServer %s", err))
+	}
+	addr := lis.Addr().String()
+
+	test.Logf("Starting MockSnapshotServer [%s]", addr)
+	pb.RegisterSnapshotServer(m.srv, m)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		if err := m.srv.Serve(lis); err != nil {
+			test.Logf("StartMockSnapshotServer failed [%s]", err)
+		}
+	}()
+
+	return addr
+}
+
+// Stop the mock snapshot server and wait for completion.
+func (m *MockSnapshotServer) Stop() {
+	if m.srv == nil {
+		panic("MockSnapshotServer not started")
+	}
+
+	m.srv.Stop()
+	m.wg.Wait()
+	m.srv = nil
+}
@@ -17,6 +17,9 @@ const (
 	cscc            = "cscc"
 	csccJoinChannel = "JoinChain"
 	csccChannels    = "GetChannels"
+
+	csccJoinChannelBySnapshot = "JoinChainBySnapshot"
+	csccJoinBySnapshotStatus  = "JoinBySnapshotStatus"
 )
 
 func createJoinChannelInvokeRequest(genesisBlock *common.Block) (fab.ChaincodeInvokeRequest, error) { //nolint
@@ -46,3 +49,20 @@ func createChannelsInvokeRequest() fab.ChaincodeInvokeRequest {
 	}
 	return cir
 }
+
+func createJoinChannelBySnapshotInvokeRequest(snapshotDir string) fab.ChaincodeInvokeRequest {
+	cir := fab.ChaincodeInvokeRequest{
+		ChaincodeID: cscc,
+		Fcn:         csccJoinChannelBySnapshot,
+		Args:        [][]byte{[]byte(snapshotDir)},
+	}
+	return cir
+}
+
+func createJoinBySnapshotStatusInvokeRequest() fab.ChaincodeInvokeRequest {
+	cir := fab.ChaincodeInvokeRequest{
+		ChaincodeID: cscc,
+		Fcn:         csccJoinBySnapshotStatus,
+	}
+	return cir
+}
@@ -188,27 +188,46 @@ func JoinChannel(reqCtx reqContext.Context, request JoinChannelRequest, targets
 		return errors.WithMessage(err, "creation of join channel invoke request failed")
 	}
 
-	var errors1 multi.Errors
-	var mutex sync.Mutex
-	var wg sync.WaitGroup
+	return queryChaincodeWithTargets(reqCtx, cir, targets, optionsValue)
+}
 
-	wg.Add(len(targets))
+// JoinChannelBySnapshot sends a proposal to the target peers to join the channel of a ledger snapshot. The snapshot
+// directory is a path on the file system of the peers. The peers join the channel in the background; use
+// QueryJoinBySnapshotStatus to check whether a join is in progress.
+func JoinChannelBySnapshot(reqCtx reqContext.Context, snapshotDir string, targets []fab.ProposalProcessor, opts ...Opt) error {
 
-	for _, t := range targets {
-		target := t
-		go func() {
-			defer wg.Done()
-			if _, err := queryChaincodeWithTarget(reqCtx, cir, target, optionsValue); err != nil {
-				mutex.Lock()
-				errors1 = append(errors1, err)
-				mutex.Unlock()
-			}
-		}()
+	if snapshotDir == "" {
+		return errors.New("missing snapshot directory")
 	}
 
-	wg.Wait()
+	optionsValue := getOpts(opts...)
 
-	return errors1.ToError()
+	cir := createJoinChannelBySnapshotInvokeRequest(snapshotDir)
+
+	return queryChaincodeWithTargets(reqCtx, cir, targets, optionsValue)
+}
+
+// QueryJoinBySnapshotStatus queries whether a peer is joining a channel from a snapshot.
+func QueryJoinBySnapshotStatus(reqCtx reqContext.Context, peer fab.ProposalProcessor, opts ...Opt) (*pb.JoinBySnapshotStatus, error) {
+
+	if peer == nil {
+		return nil, errors.New("peer required")
+	}
+
+	optionsValue := getOpts(opts...)
+
+	cir := createJoinBySnapshotStatusInvokeRequest()
+	payload, err := queryChaincodeWithTarget(reqCtx, cir, peer, optionsValue)
+	if err != nil {
+		return nil, errors.WithMessage(err, "cscc.JoinBySnapshotStatus failed")
+	}
+
+	response := new(pb.JoinBySnapshotStatus)
+	err = proto.Unmarshal(payload, response)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal JoinBySnapshotStatus failed")
+	}
+	return response, nil
 }
 
 func extractSignedEnvelope(reqEnvelope []byte) (*fab.SignedEnvelope, error) {
@@ -370,6 +389,31 @@ func InstallChaincode(reqCtx reqContext.Context, req InstallChaincodeRequest, ta
 	return resp.([]*fab.TransactionProposalResponse), prop.TxnID, nil
 }
 
+// queryChaincodeWithTargets sends the request to the targets concurrently
+func queryChaincodeWithTargets(reqCtx reqContext.Context, request fab.ChaincodeInvokeRequest, targets []fab.ProposalProcessor, opts options) error {
+	var errors1 multi.Errors
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	wg.Add(len(targets))
+
+	for _, t := range targets {
+		target := t
+		go func() {
+			defer wg.Done()
+			if _, err := queryChaincodeWithTarget(reqCtx, request, target, opts); err != nil {
+				mutex.Lock()
+				errors1 = append(errors1, err)
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errors1.ToError()
+}
+
 func queryChaincodeWithTarget(reqCtx reqContext.Context, request fab.ChaincodeInvokeRequest, target fab.ProposalProcessor, opts options) ([]byte, error) {
 
 	targets := []fab.ProposalProcessor{target}
@@ -0,0 +1,169 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resource
+
+import (
+	reqContext "context"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
+)
+
+// snapshotCall invokes an operation of the snapshot service of a peer
+type snapshotCall func(reqCtx reqContext.Context, client pb.SnapshotClient, request *pb.SignedSnapshotRequest) (interface{}, error)
+
+// SubmitSnapshotRequest submits a request to the target peers to generate a snapshot of the ledger of a channel
+// when the given block is committed. If the block number is 0, the snapshot is generated at the last committed block.
+func SubmitSnapshotRequest(reqCtx reqContext.Context, channelID string, blockNumber uint64, targets []fab.Peer, opts ...Opt) error {
+	return sendSnapshotRequest(reqCtx, channelID, blockNumber, targets, getOpts(opts...),
+		func(reqCtx reqContext.Context, client pb.SnapshotClient, request *pb.SignedSnapshotRequest) (interface{}, error) {
+			return client.Generate(reqCtx, request)
+		},
+	)
+}
+
+// CancelSnapshotRequest cancels a pending request to generate a snapshot of the ledger of a channel at the given
+// block on the target peers.
+func CancelSnapshotRequest(reqCtx reqContext.Context, channelID string, blockNumber uint64, targets []fab.Peer, opts ...Opt) error {
+	return sendSnapshotRequest(reqCtx, channelID, blockNumber, targets, getOpts(opts...),
+		func(reqCtx reqContext.Context, client pb.SnapshotClient, request *pb.SignedSnapshotRequest) (interface{}, error) {
+			return client.Cancel(reqCtx, request)
+		},
+	)
+}
+
+// QueryPendingSnapshots queries the block numbers of the pending snapshot requests of a channel on a peer.
+func QueryPendingSnapshots(reqCtx reqContext.Context, channelID string, peer fab.Peer, opts ...Opt) ([]uint64, error) {
+	if peer == nil {
+		return nil, errors.New("peer required")
+	}
+
+	ctx, ok := contextImpl.RequestClientContext(reqCtx)
+	if !ok {
+		return nil, errors.New("failed get client context from reqContext for snapshot request")
+	}
+
+	shdr, err := newSignatureHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	request, err := signSnapshotRequest(ctx, &pb.SnapshotQuery{SignatureHeader: shdr, ChannelId: channelID})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := callSnapshotService(reqCtx, ctx, peer, request, getOpts(opts...),
+		func(reqCtx reqContext.Context, client pb.SnapshotClient, request *pb.SignedSnapshotRequest) (interface{}, error) {
+			return client.QueryPendings(reqCtx, request)
+		},
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "snapshot.QueryPendings failed")
+	}
+
+	return resp.(*pb.QueryPendingSnapshotsResponse).BlockNumbers, nil
+}
+
+func sendSnapshotRequest(reqCtx reqContext.Context, channelID string, blockNumber uint64, targets []fab.Peer, opts options, call snapshotCall) error {
+	ctx, ok := contextImpl.RequestClientContext(reqCtx)
+	if !ok {
+		return errors.New("failed get client context from reqContext for snapshot request")
+	}
+
+	shdr, err := newSignatureHeader(ctx)
+	if err != nil {
+		return err
+	}
+	request, err := signSnapshotRequest(ctx, &pb.SnapshotRequest{SignatureHeader: shdr, ChannelId: channelID, BlockNumber: blockNumber})
+	if err != nil {
+		return err
+	}
+
+	var errs multi.Errors
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	wg.Add(len(targets))
+
+	for _, t := range targets {
+		target := t
+		go func() {
+			defer wg.Done()
+			if _, err := callSnapshotService(reqCtx, ctx, target, request, opts, call); err != nil {
+				mutex.Lock()
+				errs = append(errs, errors.WithMessage(err, "From target: "+target.URL()))
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errs.ToError()
+}
+
+func callSnapshotService(reqCtx reqContext.Context, ctx context.Client, target fab.Peer, request *pb.SignedSnapshotRequest, opts options, call snapshotCall) (interface{}, error) {
+	peerCfg, ok := ctx.EndpointConfig().PeerConfig(target.URL())
+	if !ok {
+		peerCfg = &fab.PeerConfig{URL: target.URL()}
+	}
+
+	connOpts := comm.OptsFromPeerConfig(peerCfg)
+	connOpts = append(connOpts, comm.WithConnectTimeout(ctx.EndpointConfig().Timeout(fab.PeerConnection)))
+	connOpts = append(connOpts, comm.WithParentContext(reqCtx))
+
+	return retry.NewInvoker(retry.New(opts.retry)).Invoke(
+		func() (interface{}, error) {
+			conn, err := comm.NewConnection(ctx, target.URL(), connOpts...)
+			if err != nil {
+				return nil, err
+			}
+			defer conn.Close()
+
+			return call(reqCtx, pb.NewSnapshotClient(conn.ClientConn()), request)
+		},
+	)
+}
+
+func newSignatureHeader(ctx context.Client) (*common.SignatureHeader, error) {
+	creator, err := ctx.Serialize()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get user context's identity")
+	}
+
+	nonce, err := crypto.GetRandomNonce()
+	if err != nil {
+		return nil, errors.WithMessage(err, "nonce creation failed")
+	}
+
+	return &common.SignatureHeader{Creator: creator, Nonce: nonce}, nil
+}
+
+func signSnapshotRequest(ctx context.Client, request proto.Message) (*pb.SignedSnapshotRequest, error) {
+	requestBytes, err := proto.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal snapshot request failed")
+	}
+
+	signature, err := ctx.SigningManager().Sign(requestBytes, ctx.PrivateKey())
+	if err != nil {
+		return nil, errors.WithMessage(err, "signing of snapshot request failed")
+	}
+
+	return &pb.SignedSnapshotRequest{Request: requestBytes, Signature: signature}, nil
+}
@@ -0,0 +1,324 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txn
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/blockdecoder"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+)
+
+// DifferenceType identifies the part of an endorsement that differs between endorsers
+type DifferenceType string
+
+const (
+	// StatusDifference indicates that the chaincode response status differs
+	StatusDifference DifferenceType = "status"
+	// PayloadDifference indicates that the chaincode response payload differs
+	PayloadDifference DifferenceType = "response payload"
+	// EventDifference indicates that the chaincode event differs
+	EventDifference DifferenceType = "chaincode event"
+	// ReadVersionDifference indicates that a key was read at a different version, or was only read by one of the endorsers
+	ReadVersionDifference DifferenceType = "read version"
+	// WriteValueDifference indicates that a different value was written to a key, or the key was only written by one of the endorsers
+	WriteValueDifference DifferenceType = "write value"
+	// ProposalResponseDifference indicates that the proposal response payloads differ in a way that is not
+	// explained by any of the other differences, e.g. because a response could not be decoded
+	ProposalResponseDifference DifferenceType = "proposal response"
+)
+
+const (
+	notPresent = "<none>"
+	deleted    = "<deleted>"
+)
+
+// Endorsement is the decoded content of the proposal response of a single endorser
+type Endorsement struct {
+	// Endorser is the URL of the endorsing peer
+	Endorser string
+	// Status is the status of the chaincode response
+	Status int32
+	// Payload is the payload of the chaincode response
+	Payload []byte
+	// RWSets are the read/write sets of the chaincodes invoked by the transaction
+	RWSets []*blockdecoder.NamespaceRWSet
+	// Event is the chaincode event, if any
+	Event *blockdecoder.ChaincodeEvent
+	// DecodeError is set if the proposal response payload could not be decoded
+	DecodeError error
+}
+
+// EndorsementDifference describes a difference between the endorsement of a peer and the endorsement
+// of the first peer in the set of responses, which is used as the reference.
+// For private data collections, Key, Expected and Actual hold the hex encoded hashes of the key and values.
+type EndorsementDifference struct {
+	Endorser   string
+	Type       DifferenceType
+	Namespace  string
+	Collection string
+	Key        string
+	Expected   string
+	Actual     string
+}
+
+func (d *EndorsementDifference) String() string {
+	var location []string
+	for _, s := range []string{d.Namespace, d.Collection, d.Key} {
+		if s != "" {
+			location = append(location, s)
+		}
+	}
+
+	if len(location) == 0 {
+		return fmt.Sprintf("[%s] %s: expected %q, got %q", d.Endorser, d.Type, d.Expected, d.Actual)
+	}
+	return fmt.Sprintf("[%s] %s of [%s]: expected %q, got %q", d.Endorser, d.Type, strings.Join(location, "/"), d.Expected, d.Actual)
+}
+
+// EndorsementMismatchError provides a per-peer breakdown of proposal responses that do not match.
+// It is returned in the Details of the EndorsementMismatch status, and may be obtained from an error
+// using EndorsementMismatchFromError.
+type EndorsementMismatchError struct {
+	// Endorsements are the decoded proposal responses, in the order in which they were received
+	Endorsements []*Endorsement
+	// Differences lists the differences between each endorsement and the first endorsement
+	Differences []*EndorsementDifference
+}
+
+func (e *EndorsementMismatchError) Error() string {
+	diffs := make([]string, len(e.Differences))
+	for i, d := range e.Differences {
+		diffs[i] = d.String()
+	}
+	return fmt.Sprintf("endorsements do not match: %s", strings.Join(diffs, "; "))
+}
+
+// NewEndorsementMismatchError decodes the given proposal responses and computes the differences between them
+func NewEndorsementMismatchError(responses []*fab.TransactionProposalResponse) *EndorsementMismatchError {
+	e := &EndorsementMismatchError{}
+	for _, r := range responses {
+		e.Endorsements = append(e.Endorsements, decodeEndorsement(r))
+	}
+
+	if len(responses) == 0 {
+		return e
+	}
+
+	ref := responses[0]
+	for i, r := range responses[1:] {
+		if bytes.Equal(ref.GetPayload(), r.GetPayload()) &&
+			bytes.Equal(ref.GetResponse().GetPayload(), r.GetResponse().GetPayload()) {
+			continue
+		}
+
+		diffs := diffEndorsements(e.Endorsements[0], e.Endorsements[i+1])
+		if len(diffs) == 0 {
+			diffs = []*EndorsementDifference{{
+				Endorser: r.Endorser,
+				Type:     ProposalResponseDifference,
+				Expected: hex.EncodeToString(ref.GetPayload()),
+				Actual:   hex.EncodeToString(r.GetPayload()),
+			}}
+		}
+		e.Differences = append(e.Differences, diffs...)
+	}
+
+	return e
+}
+
+// NewEndorsementMismatchStatus returns an EndorsementMismatch status with the given message, which carries
+// the diagnostics of the given proposal responses as its details.
+func NewEndorsementMismatchStatus(msg string, responses []*fab.TransactionProposalResponse) *status.Status {
+	return status.New(status.EndorserClientStatus, status.EndorsementMismatch.ToInt32(), msg,
+		[]interface{}{NewEndorsementMismatchError(responses)})
+}
+
+// EndorsementMismatchFromError returns the endorsement diagnostics carried by an EndorsementMismatch status
+func EndorsementMismatchFromError(err error) (*EndorsementMismatchError, bool) {
+	if e, ok := errors.Cause(err).(*EndorsementMismatchError); ok {
+		return e, true
+	}
+
+	s, ok := status.FromError(err)
+	if !ok || s.Group != status.EndorserClientStatus || s.Code != status.EndorsementMismatch.ToInt32() {
+		return nil, false
+	}
+
+	for _, detail := range s.Details {
+		if e, ok := detail.(*EndorsementMismatchError); ok {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+func decodeEndorsement(r *fab.TransactionProposalResponse) *Endorsement {
+	e := &Endorsement{
+		Endorser: r.Endorser,
+		Status:   r.GetResponse().GetStatus(),
+		Payload:  r.GetResponse().GetPayload(),
+	}
+
+	if r.ProposalResponse == nil {
+		return e
+	}
+
+	action, err := blockdecoder.DecodeProposalResponsePayload(r.Payload)
+	if err != nil {
+		e.DecodeError = err
+		return e
+	}
+	e.RWSets = action.RWSets
+	e.Event = action.Event
+
+	return e
+}
+
+func diffEndorsements(ref, other *Endorsement) []*EndorsementDifference {
+	var diffs []*EndorsementDifference
+	add := func(t DifferenceType, k stateKey, expected, actual string) {
+		diffs = append(diffs, &EndorsementDifference{
+			Endorser:   other.Endorser,
+			Type:       t,
+			Namespace:  k.namespace,
+			Collection: k.collection,
+			Key:        k.key,
+			Expected:   expected,
+			Actual:     actual,
+		})
+	}
+
+	if ref.Status != other.Status {
+		add(StatusDifference, stateKey{}, fmt.Sprint(ref.Status), fmt.Sprint(other.Status))
+	}
+	if !bytes.Equal(ref.Payload, other.Payload) {
+		add(PayloadDifference, stateKey{}, string(ref.Payload), string(other.Payload))
+	}
+
+	refEvent, refPayload := eventOf(ref)
+	otherEvent, otherPayload := eventOf(other)
+	if refEvent != otherEvent {
+		add(EventDifference, stateKey{}, refEvent, otherEvent)
+	} else if !bytes.Equal(refPayload, otherPayload) {
+		add(EventDifference, stateKey{key: refEvent}, string(refPayload), string(otherPayload))
+	}
+
+	refState, otherState := newStateAccess(ref.RWSets), newStateAccess(other.RWSets)
+	for _, k := range diffKeys(refState.reads, otherState.reads) {
+		add(ReadVersionDifference, k, valueOf(refState.reads, k), valueOf(otherState.reads, k))
+	}
+	for _, k := range diffKeys(refState.writes, otherState.writes) {
+		add(WriteValueDifference, k, valueOf(refState.writes, k), valueOf(otherState.writes, k))
+	}
+
+	return diffs
+}
+
+type stateKey struct {
+	namespace  string
+	collection string
+	key        string
+}
+
+// stateAccess holds the versions of the keys read, and the values of the keys written, by a transaction
+type stateAccess struct {
+	reads  map[stateKey]string
+	writes map[stateKey]string
+}
+
+// eventOf returns the name and payload of the chaincode event of an endorsement
+func eventOf(e *Endorsement) (string, []byte) {
+	if e.Event == nil {
+		return "", nil
+	}
+	return e.Event.EventName, e.Event.Payload
+}
+
+func newStateAccess(rwSets []*blockdecoder.NamespaceRWSet) *stateAccess {
+	s := &stateAccess{
+		reads:  make(map[stateKey]string),
+		writes: make(map[stateKey]string),
+	}
+
+	for _, nsRWSet := range rwSets {
+		for _, read := range nsRWSet.Reads {
+			s.reads[stateKey{namespace: nsRWSet.Namespace, key: read.Key}] = versionString(read.Version)
+		}
+		for _, write := range nsRWSet.Writes {
+			s.writes[stateKey{namespace: nsRWSet.Namespace, key: write.Key}] = writeString(write.IsDelete, string(write.Value))
+		}
+
+		for _, collRWSet := range nsRWSet.Collections {
+			for _, read := range collRWSet.HashedReads {
+				k := stateKey{namespace: nsRWSet.Namespace, collection: collRWSet.Collection, key: read.KeyHash}
+				s.reads[k] = versionString(read.Version)
+			}
+			for _, write := range collRWSet.HashedWrites {
+				k := stateKey{namespace: nsRWSet.Namespace, collection: collRWSet.Collection, key: write.KeyHash}
+				s.writes[k] = writeString(write.IsDelete, write.ValueHash)
+			}
+		}
+	}
+
+	return s
+}
+
+// versionString formats a version as blockNum:txNum. A key that did not exist when it was read has no version.
+func versionString(version *blockdecoder.Version) string {
+	if version == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%d:%d", version.BlockNum, version.TxNum)
+}
+
+func writeString(isDelete bool, value string) string {
+	if isDelete {
+		return deleted
+	}
+	return value
+}
+
+func valueOf(values map[stateKey]string, k stateKey) string {
+	if v, ok := values[k]; ok {
+		return v
+	}
+	return notPresent
+}
+
+// diffKeys returns the keys whose values differ between the two maps, or which are only present in one of them,
+// sorted by namespace, collection and key
+func diffKeys(ref, other map[stateKey]string) []stateKey {
+	var keys []stateKey
+	for k, v := range ref {
+		if ov, ok := other[k]; !ok || ov != v {
+			keys = append(keys, k)
+		}
+	}
+	for k := range other {
+		if _, ok := ref[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].namespace != keys[j].namespace {
+			return keys[i].namespace < keys[j].namespace
+		}
+		if keys[i].collection != keys[j].collection {
+			return keys[i].collection < keys[j].collection
+		}
+		return keys[i].key < keys[j].key
+	})
+	return keys
+}
@@ -0,0 +1,147 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txn
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+func TestEndorsementMismatchError(t *testing.T) {
+	r1 := newEndorsement(t, "peer1", []byte("result"), &pb.ChaincodeEvent{EventName: "created", Payload: []byte("a")},
+		&kvrwset.KVRWSet{
+			Reads:  []*kvrwset.KVRead{{Key: "k1", Version: &kvrwset.Version{BlockNum: 5, TxNum: 1}}, {Key: "k2"}},
+			Writes: []*kvrwset.KVWrite{{Key: "k1", Value: []byte("v1")}, {Key: "k3", IsDelete: true}},
+		})
+	r2 := newEndorsement(t, "peer2", []byte("result"), &pb.ChaincodeEvent{EventName: "created", Payload: []byte("a")},
+		&kvrwset.KVRWSet{
+			Reads:  []*kvrwset.KVRead{{Key: "k1", Version: &kvrwset.Version{BlockNum: 4, TxNum: 0}}, {Key: "k2"}},
+			Writes: []*kvrwset.KVWrite{{Key: "k1", Value: []byte("v2")}, {Key: "k4", Value: []byte("v4")}},
+		})
+	r3 := newEndorsement(t, "peer3", []byte("other"), &pb.ChaincodeEvent{EventName: "created", Payload: []byte("b")},
+		&kvrwset.KVRWSet{
+			Reads:  []*kvrwset.KVRead{{Key: "k1", Version: &kvrwset.Version{BlockNum: 5, TxNum: 1}}, {Key: "k2"}},
+			Writes: []*kvrwset.KVWrite{{Key: "k1", Value: []byte("v1")}, {Key: "k3", IsDelete: true}},
+		})
+
+	e := NewEndorsementMismatchError([]*fab.TransactionProposalResponse{r1, r2, r3})
+
+	require.Len(t, e.Endorsements, 3)
+	assert.Equal(t, "peer2", e.Endorsements[1].Endorser)
+	assert.Equal(t, []byte("other"), e.Endorsements[2].Payload)
+	assert.Equal(t, "created", e.Endorsements[0].Event.EventName)
+	require.Len(t, e.Endorsements[0].RWSets, 1)
+	assert.Equal(t, "cc", e.Endorsements[0].RWSets[0].Namespace)
+	assert.NoError(t, e.Endorsements[0].DecodeError)
+
+	expected := []*EndorsementDifference{
+		{Endorser: "peer2", Type: ReadVersionDifference, Namespace: "cc", Key: "k1", Expected: "5:1", Actual: "4:0"},
+		{Endorser: "peer2", Type: WriteValueDifference, Namespace: "cc", Key: "k1", Expected: "v1", Actual: "v2"},
+		{Endorser: "peer2", Type: WriteValueDifference, Namespace: "cc", Key: "k3", Expected: "<deleted>", Actual: "<none>"},
+		{Endorser: "peer2", Type: WriteValueDifference, Namespace: "cc", Key: "k4", Expected: "<none>", Actual: "v4"},
+		{Endorser: "peer3", Type: PayloadDifference, Expected: "result", Actual: "other"},
+		{Endorser: "peer3", Type: EventDifference, Key: "created", Expected: "a", Actual: "b"},
+	}
+	assert.Equal(t, expected, e.Differences)
+	assert.Contains(t, e.Error(), `[peer2] write value of [cc/k1]: expected "v1", got "v2"`)
+}
+
+func TestEndorsementMismatchCollections(t *testing.T) {
+	newResponse := func(endorser string, valueHash []byte) *fab.TransactionProposalResponse {
+		txRWSet := &rwsetutil.TxRwSet{NsRwSets: []*rwsetutil.NsRwSet{{
+			NameSpace: "cc",
+			KvRwSet:   &kvrwset.KVRWSet{},
+			CollHashedRwSets: []*rwsetutil.CollHashedRwSet{{
+				CollectionName: "coll",
+				HashedRwSet:    &kvrwset.HashedRWSet{HashedWrites: []*kvrwset.KVWriteHash{{KeyHash: []byte{0x01}, ValueHash: valueHash}}},
+			}},
+		}}}
+		return newProposalResponse(t, endorser, nil, nil, txRWSet)
+	}
+
+	e := NewEndorsementMismatchError([]*fab.TransactionProposalResponse{newResponse("peer1", []byte{0xaa}), newResponse("peer2", []byte{0xbb})})
+
+	expected := []*EndorsementDifference{
+		{Endorser: "peer2", Type: WriteValueDifference, Namespace: "cc", Collection: "coll", Key: "01", Expected: "aa", Actual: "bb"},
+	}
+	assert.Equal(t, expected, e.Differences)
+}
+
+func TestEndorsementMismatchUndecodable(t *testing.T) {
+	r1 := &fab.TransactionProposalResponse{Endorser: "peer1", ProposalResponse: &pb.ProposalResponse{
+		Response: &pb.Response{Payload: []byte("result")}, Payload: []byte("invalid1")}}
+	r2 := &fab.TransactionProposalResponse{Endorser: "peer2", ProposalResponse: &pb.ProposalResponse{
+		Response: &pb.Response{Payload: []byte("result")}, Payload: []byte("invalid2")}}
+
+	e := NewEndorsementMismatchError([]*fab.TransactionProposalResponse{r1, r2})
+
+	assert.Error(t, e.Endorsements[0].DecodeError)
+	require.Len(t, e.Differences, 1)
+	assert.Equal(t, ProposalResponseDifference, e.Differences[0].Type)
+	assert.Equal(t, "peer2", e.Differences[0].Endorser)
+}
+
+func TestEndorsementMismatchFromError(t *testing.T) {
+	r1 := newEndorsement(t, "peer1", []byte("a"), nil, &kvrwset.KVRWSet{})
+	r2 := newEndorsement(t, "peer2", []byte("b"), nil, &kvrwset.KVRWSet{})
+
+	err := errors.WithMessage(NewEndorsementMismatchStatus("payloads do not match", []*fab.TransactionProposalResponse{r1, r2}), "endorsement validation failed")
+
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.EqualValues(t, status.EndorsementMismatch, s.Code)
+	assert.Equal(t, "payloads do not match", s.Message)
+
+	e, ok := EndorsementMismatchFromError(err)
+	require.True(t, ok)
+	require.Len(t, e.Differences, 1)
+	assert.Equal(t, PayloadDifference, e.Differences[0].Type)
+
+	_, ok = EndorsementMismatchFromError(errors.New("other"))
+	assert.False(t, ok)
+	_, ok = EndorsementMismatchFromError(status.New(status.EndorserClientStatus, status.EndorsementMismatch.ToInt32(), "no details", nil))
+	assert.False(t, ok)
+}
+
+func newEndorsement(t *testing.T, endorser string, payload []byte, event *pb.ChaincodeEvent, kvRWSet *kvrwset.KVRWSet) *fab.TransactionProposalResponse {
+	txRWSet := &rwsetutil.TxRwSet{NsRwSets: []*rwsetutil.NsRwSet{{NameSpace: "cc", KvRwSet: kvRWSet}}}
+	return newProposalResponse(t, endorser, payload, event, txRWSet)
+}
+
+func newProposalResponse(t *testing.T, endorser string, payload []byte, event *pb.ChaincodeEvent, txRWSet *rwsetutil.TxRwSet) *fab.TransactionProposalResponse {
+	results, err := txRWSet.ToProtoBytes()
+	require.NoError(t, err)
+
+	var eventBytes []byte
+	if event != nil {
+		eventBytes, err = proto.Marshal(event)
+		require.NoError(t, err)
+	}
+
+	response := &pb.Response{Status: 200, Payload: payload}
+	extension, err := proto.Marshal(&pb.ChaincodeAction{Results: results, Events: eventBytes, Response: response})
+	require.NoError(t, err)
+
+	prpBytes, err := proto.Marshal(&pb.ProposalResponsePayload{Extension: extension})
+	require.NoError(t, err)
+
+	return &fab.TransactionProposalResponse{
+		Endorser:         endorser,
+		Status:           200,
+		ProposalResponse: &pb.ProposalResponse{Response: response, Payload: prpBytes},
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
 	contextApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
 )
 
@@ -106,6 +107,7 @@ func SendProposal(reqCtx reqContext.Context, proposal *fab.TransactionProposal,
 	}
 
 	request := fab.ProcessProposalRequest{SignedProposal: signedProposal}
+	attrs := proposalAttributes(proposal)
 
 	var responseMtx sync.Mutex
 	var transactionProposalResponses []*fab.TransactionProposalResponse
@@ -119,7 +121,10 @@ func SendProposal(reqCtx reqContext.Context, proposal *fab.TransactionProposal,
 
 			// TODO: The RPC should be timed-out.
 			//resp, err := processor.ProcessTransactionProposal(context.NewRequestOLD(ctx), request)
-			resp, err := processor.ProcessTransactionProposal(reqCtx, request)
+			spanCtx, span := tracing.StartSpan(reqCtx, "peer.ProcessProposal", attrs...)
+			span.SetAttributes(processorAttributes(processor)...)
+			resp, err := processor.ProcessTransactionProposal(spanCtx, request)
+			tracing.EndSpan(span, err)
 			if err != nil {
 				logger.Debugf("Received error response from txn proposal processing: %s", err)
 				responseMtx.Lock()
@@ -138,6 +143,32 @@ func SendProposal(reqCtx reqContext.Context, proposal *fab.TransactionProposal,
 	return transactionProposalResponses, errs.ToError()
 }
 
+// proposalAttributes returns the tracing attributes identifying the transaction of a proposal
+func proposalAttributes(proposal *fab.TransactionProposal) []tracing.Attribute {
+	attrs := []tracing.Attribute{tracing.String(tracing.TxIDKey, string(proposal.TxnID))}
+	if proposal.Proposal == nil {
+		return attrs
+	}
+
+	hdr, err := protoutil.UnmarshalHeader(proposal.Proposal.Header)
+	if err != nil {
+		return attrs
+	}
+	chdr, err := protoutil.UnmarshalChannelHeader(hdr.ChannelHeader)
+	if err != nil {
+		return attrs
+	}
+	return append(attrs, tracing.String(tracing.ChannelKey, chdr.ChannelId))
+}
+
+// processorAttributes returns the tracing attributes identifying a proposal processor
+func processorAttributes(processor fab.ProposalProcessor) []tracing.Attribute {
+	if peer, ok := processor.(fab.Peer); ok {
+		return []tracing.Attribute{tracing.String(tracing.PeerURLKey, peer.URL())}
+	}
+	return nil
+}
+
 // getTargetsWithoutDuplicates returns a list of targets without duplicates
 func getTargetsWithoutDuplicates(targets []fab.ProposalProcessor) []fab.ProposalProcessor {
 	peerUrlsToTargets := map[string]fab.ProposalProcessor{}
@@ -22,6 +22,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	mock_context "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/test/mockfab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
@@ -302,3 +303,43 @@ func setupMassiveTestPeers(numberOfPeers int) []fab.ProposalProcessor {
 
 	return peers
 }
+
+func TestSendProposalTracing(t *testing.T) {
+	user := mspmocks.NewMockSigningIdentity("test", "1234")
+	ctx := mocks.NewMockContext(user)
+	tracer := tracing.NewInMemoryTracer()
+	ctx.SetTracer(tracer)
+
+	txh, err := NewHeader(ctx, testChannel)
+	require.NoError(t, err)
+	proposal, err := CreateChaincodeInvokeProposal(txh, fab.ChaincodeInvokeRequest{ChaincodeID: "cc", Fcn: "invoke"})
+	require.NoError(t, err)
+
+	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(10*time.Second), context.WithSpan("root"))
+	peer1 := mocks.NewMockPeer("p1", "grpc://p1:7051")
+	peer1.Error = fmt.Errorf("endorsement failed")
+	peer2 := mocks.NewMockPeer("p2", "grpc://p2:7051")
+
+	_, err = SendProposal(reqCtx, proposal, []fab.ProposalProcessor{peer1, peer2})
+	require.Error(t, err)
+	cancel()
+
+	roots := tracer.SpansByName("root")
+	require.Len(t, roots, 1)
+	assert.True(t, roots[0].Ended, "expected span to be ended when the request context is cancelled")
+
+	spans := tracer.SpansByName("peer.ProcessProposal")
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		assert.Equal(t, roots[0].ID, span.ParentID)
+		assert.Equal(t, string(txh.TransactionID()), span.Attributes[tracing.TxIDKey])
+		assert.Equal(t, testChannel, span.Attributes[tracing.ChannelKey])
+		assert.True(t, span.Ended)
+		if span.Attributes[tracing.PeerURLKey] == peer1.URL() {
+			assert.Len(t, span.Errors, 1)
+		} else {
+			assert.Equal(t, peer2.URL(), span.Attributes[tracing.PeerURLKey])
+			assert.Empty(t, span.Errors)
+		}
+	}
+}
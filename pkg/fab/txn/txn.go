@@ -20,6 +20,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	ctxprovider "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
 )
 
@@ -137,12 +138,15 @@ func Send(reqCtx reqContext.Context, tx *fab.Transaction, orderers []fab.Orderer
 
 // BroadcastPayload will send the given payload to some orderer, picking random endpoints
 // until all are exhausted
-func BroadcastPayload(reqCtx reqContext.Context, payload *common.Payload, orderers []fab.Orderer) (*fab.TransactionResponse, error) {
+func BroadcastPayload(reqCtx reqContext.Context, payload *common.Payload, orderers []fab.Orderer) (resp *fab.TransactionResponse, err error) {
 	// Check if orderers are defined
 	if len(orderers) == 0 {
 		return nil, errors.New("orderers not set")
 	}
 
+	reqCtx, span := tracing.StartSpan(reqCtx, "orderer.Broadcast", payloadAttributes(payload)...)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	ctx, ok := context.RequestClientContext(reqCtx)
 	if !ok {
 		return nil, errors.New("failed get client context from reqContext for signPayload")
@@ -193,9 +197,13 @@ func sendBroadcast(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orde
 	childCtx, cancel := context.NewRequest(client, context.WithTimeoutType(fab.OrdererResponse), context.WithParent(reqCtx))
 	defer cancel()
 
+	childCtx, span := tracing.StartSpan(childCtx, "orderer.SendBroadcast", tracing.String(tracing.OrdererURLKey, orderer.URL()))
+	defer span.End()
+
 	// Send request
 	if _, err := orderer.SendBroadcast(childCtx, envelope); err != nil {
 		logger.Debugf("Receive Error Response from orderer: %s\n", err)
+		span.RecordError(err)
 		return nil, errors.Wrapf(err, "calling orderer '%s' failed", orderer.URL())
 	}
 
@@ -203,6 +211,22 @@ func sendBroadcast(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orde
 	return &fab.TransactionResponse{Orderer: orderer.URL()}, nil
 }
 
+// payloadAttributes returns the tracing attributes identifying the transaction of a payload
+func payloadAttributes(payload *common.Payload) []tracing.Attribute {
+	if payload.Header == nil {
+		return nil
+	}
+
+	chdr, err := protoutil.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil
+	}
+	return []tracing.Attribute{
+		tracing.String(tracing.ChannelKey, chdr.ChannelId),
+		tracing.String(tracing.TxIDKey, chdr.TxId),
+	}
+}
+
 // SendPayload sends the given payload to each orderer and returns a block response
 func SendPayload(reqCtx reqContext.Context, payload *common.Payload, orderers []fab.Orderer) (*common.Block, error) {
 	if len(orderers) == 0 {
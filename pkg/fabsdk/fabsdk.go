@@ -17,6 +17,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/tracing"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/lookup"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
@@ -57,6 +58,7 @@ type options struct {
 	ConfigBackend     []core.ConfigBackend
 	ProviderOpts      []coptions.Opt // Provider options are passed along to the various providers
 	metricsConfig     metricsCfg.MetricsConfig
+	tracer            tracing.Tracer
 }
 
 // Option configures the SDK.
@@ -205,6 +207,17 @@ func WithMetricsConfig(metricsConfigs ...interface{}) Option {
 	}
 }
 
+// WithTracer enables distributed tracing of the transaction flow using the given tracer.
+// Spans are created for endorser selection, endorsements, signature validation, ordering and the wait for the
+// commit event of the channel, ledger and resource management clients. An OpenTelemetry tracer is plugged in
+// using the adapter of the github.com/hyperledger/fabric-sdk-go/pkg/common/tracing/otel module.
+func WithTracer(tracer tracing.Tracer) Option {
+	return func(opts *options) error {
+		opts.tracer = tracer
+		return nil
+	}
+}
+
 // WithProviderOpts adds options which are propagated to the various providers.
 func WithProviderOpts(sopts ...coptions.Opt) Option {
 	return func(opts *options) error {
@@ -298,6 +311,7 @@ func initSDK(sdk *FabricSDK, configProvider core.ConfigProvider, opts []Option)
 		context.WithInfraProvider(infraProvider),
 		context.WithChannelProvider(channelProvider),
 		context.WithClientMetrics(sdk.clientMetrics),
+		context.WithTracer(sdk.opts.tracer),
 	)
 
 	//initialize
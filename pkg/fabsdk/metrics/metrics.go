@@ -66,6 +66,20 @@ var (
 		LabelNames:   []string{"chaincode", "Fcn"},
 		StatsdFormat: "%{#fqname}.%{type}.%{channel}.%{execution}",
 	}
+	admissionWaitDuration = metrics.HistogramOpts{
+		Namespace:    "channel",
+		Name:         "admission_wait_duration",
+		Help:         "The time channel client requests waited for admission.",
+		LabelNames:   []string{"chaincode", "Fcn"},
+		StatsdFormat: "%{#fqname}.%{type}.%{channel}.%{admission}",
+	}
+	admissionRejections = metrics.CounterOpts{
+		Namespace:    "channel",
+		Name:         "admission_rejections",
+		Help:         "The number of channel client requests rejected by admission control.",
+		LabelNames:   []string{"chaincode", "Fcn", "reason"},
+		StatsdFormat: "%{#fqname}.%{type}.%{channel}.%{admission}.%{reason}",
+	}
 )
 
 // ClientMetrics contains the metrics used in the (channel) client
@@ -78,6 +92,9 @@ type ClientMetrics struct {
 	ExecutionsFailed   metrics.Counter
 	ExecutionDuration  metrics.Histogram
 	ExecutionTimeouts  metrics.Counter
+
+	AdmissionWaitDuration metrics.Histogram
+	AdmissionRejections   metrics.Counter
 }
 
 // NewClientMetrics builds a new instance of ClientMetrics
@@ -91,5 +108,8 @@ func NewClientMetrics(p metrics.Provider) *ClientMetrics {
 		ExecutionsFailed:   p.NewCounter(executionsFailed),
 		ExecutionDuration:  p.NewHistogram(executionDuration),
 		ExecutionTimeouts:  p.NewCounter(executionTimeouts),
+
+		AdmissionWaitDuration: p.NewHistogram(admissionWaitDuration),
+		AdmissionRejections:   p.NewCounter(admissionRejections),
 	}
 }
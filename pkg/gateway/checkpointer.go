@@ -0,0 +1,205 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// EventOption functional arguments can be supplied when registering for events.
+type EventOption = func(*eventOptions) error
+
+type eventOptions struct {
+	checkpointer Checkpointer
+}
+
+// WithCheckpointer is an optional argument to the event registration methods. Events are received
+// starting from the block recorded by the checkpointer, and events that the checkpointer records as
+// already processed are not delivered again. The application is responsible for updating the
+// checkpointer as it processes each event.
+// Chaincode events and the transactions of filtered block events are skipped individually. Block events
+// are delivered whole, so a block whose transactions were only partly processed is delivered again in
+// full, and the application must skip the transactions recorded by the checkpointer itself.
+// If the checkpointer holds no checkpoint, events are received from the default start position.
+//  Parameters:
+//  checkpointer records the progress of the event listener
+//
+//  Returns:
+//  An EventOption which can be passed as an argument to the event registration methods.
+func WithCheckpointer(checkpointer Checkpointer) EventOption {
+	return func(o *eventOptions) error {
+		o.checkpointer = checkpointer
+		return nil
+	}
+}
+
+func newEventOptions(opts []EventOption) (*eventOptions, error) {
+	options := &eventOptions{}
+	for _, opt := range opts {
+		if err := opt(options); err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+// checkpoint is a snapshot of the checkpointer state taken at registration time
+type checkpoint struct {
+	blockNumber uint64
+	txIDs       []string
+}
+
+// newCheckpoint returns the checkpoint held by the checkpointer, or nil if nothing has been checkpointed yet
+func newCheckpoint(checkpointer Checkpointer) *checkpoint {
+	cp := &checkpoint{
+		blockNumber: checkpointer.BlockNumber(),
+		txIDs:       checkpointer.TransactionIDs(),
+	}
+	if cp.blockNumber == 0 && len(cp.txIDs) == 0 {
+		return nil
+	}
+	return cp
+}
+
+func (cp *checkpoint) skipBlock(blockNumber uint64) bool {
+	return cp != nil && blockNumber < cp.blockNumber
+}
+
+// pendingTransactions returns the filtered block event without the transactions that have already been processed
+func (cp *checkpoint) pendingTransactions(event *fab.FilteredBlockEvent) *fab.FilteredBlockEvent {
+	block := event.FilteredBlock
+	if cp == nil || block.GetNumber() != cp.blockNumber || len(cp.txIDs) == 0 {
+		return event
+	}
+
+	var pending []*peer.FilteredTransaction
+	for _, tx := range block.FilteredTransactions {
+		if !containsString(cp.txIDs, tx.Txid) {
+			pending = append(pending, tx)
+		}
+	}
+
+	return &fab.FilteredBlockEvent{
+		FilteredBlock: &peer.FilteredBlock{
+			ChannelId:            block.ChannelId,
+			Number:               block.Number,
+			FilteredTransactions: pending,
+		},
+		SourceURL: event.SourceURL,
+	}
+}
+
+func (cp *checkpoint) skipTransaction(blockNumber uint64, txID string) bool {
+	if cp == nil {
+		return false
+	}
+	return blockNumber < cp.blockNumber || (blockNumber == cp.blockNumber && containsString(cp.txIDs, txID))
+}
+
+// checkpointRegistration wraps an event registration whose events are filtered against a checkpoint
+type checkpointRegistration struct {
+	registration fab.Registration
+	unregister   func()
+	done         chan struct{}
+	once         sync.Once
+}
+
+func newCheckpointRegistration(registration fab.Registration, unregister func()) *checkpointRegistration {
+	return &checkpointRegistration{
+		registration: registration,
+		unregister:   unregister,
+		done:         make(chan struct{}),
+	}
+}
+
+func (r *checkpointRegistration) close() {
+	r.once.Do(func() {
+		close(r.done)
+		r.unregister()
+	})
+}
+
+func (r *checkpointRegistration) forwardBlockEvents(cp *checkpoint, in <-chan *fab.BlockEvent) <-chan *fab.BlockEvent {
+	out := make(chan *fab.BlockEvent)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case event, ok := <-in:
+				if !ok {
+					return
+				}
+				if cp.skipBlock(event.Block.GetHeader().GetNumber()) {
+					continue
+				}
+				select {
+				case out <- event:
+				case <-r.done:
+					return
+				}
+			case <-r.done:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (r *checkpointRegistration) forwardFilteredBlockEvents(cp *checkpoint, in <-chan *fab.FilteredBlockEvent) <-chan *fab.FilteredBlockEvent {
+	out := make(chan *fab.FilteredBlockEvent)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case event, ok := <-in:
+				if !ok {
+					return
+				}
+				if cp.skipBlock(event.FilteredBlock.GetNumber()) {
+					continue
+				}
+				select {
+				case out <- cp.pendingTransactions(event):
+				case <-r.done:
+					return
+				}
+			case <-r.done:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (r *checkpointRegistration) forwardChaincodeEvents(cp *checkpoint, in <-chan *fab.CCEvent) <-chan *fab.CCEvent {
+	out := make(chan *fab.CCEvent)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case event, ok := <-in:
+				if !ok {
+					return
+				}
+				if cp.skipTransaction(event.BlockNumber, event.TxID) {
+					continue
+				}
+				select {
+				case out <- event:
+				case <-r.done:
+					return
+				}
+			case <-r.done:
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,353 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/service/dispatcher"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+type checkpointerGenerator = func(t *testing.T) Checkpointer
+
+func testCheckpointerSuite(t *testing.T, gen checkpointerGenerator) {
+	tests := []struct {
+		title string
+		run   func(t *testing.T, checkpointer Checkpointer)
+	}{
+		{"testInitialCheckpoint", testInitialCheckpoint},
+		{"testCheckpointBlock", testCheckpointBlock},
+		{"testCheckpointTransaction", testCheckpointTransaction},
+		{"testCheckpointTransactionNewBlock", testCheckpointTransactionNewBlock},
+		{"testCheckpointDuplicateTransaction", testCheckpointDuplicateTransaction},
+	}
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.run(t, gen(t))
+		})
+	}
+}
+
+func testInitialCheckpoint(t *testing.T, checkpointer Checkpointer) {
+	if checkpointer.BlockNumber() != 0 || len(checkpointer.TransactionIDs()) != 0 {
+		t.Fatalf("Unexpected initial checkpoint: %d %v", checkpointer.BlockNumber(), checkpointer.TransactionIDs())
+	}
+}
+
+func testCheckpointBlock(t *testing.T, checkpointer Checkpointer) {
+	if err := checkpointer.CheckpointTransaction(5, "tx1"); err != nil {
+		t.Fatalf("Failed to checkpoint transaction: %s", err)
+	}
+	if err := checkpointer.CheckpointBlock(5); err != nil {
+		t.Fatalf("Failed to checkpoint block: %s", err)
+	}
+
+	if checkpointer.BlockNumber() != 6 {
+		t.Fatalf("Incorrect block number: %d", checkpointer.BlockNumber())
+	}
+	if len(checkpointer.TransactionIDs()) != 0 {
+		t.Fatalf("Transaction IDs should be cleared: %v", checkpointer.TransactionIDs())
+	}
+}
+
+func testCheckpointTransaction(t *testing.T, checkpointer Checkpointer) {
+	if err := checkpointer.CheckpointTransaction(3, "tx1"); err != nil {
+		t.Fatalf("Failed to checkpoint transaction: %s", err)
+	}
+	if err := checkpointer.CheckpointTransaction(3, "tx2"); err != nil {
+		t.Fatalf("Failed to checkpoint transaction: %s", err)
+	}
+
+	txIDs := checkpointer.TransactionIDs()
+	if checkpointer.BlockNumber() != 3 || len(txIDs) != 2 || txIDs[0] != "tx1" || txIDs[1] != "tx2" {
+		t.Fatalf("Unexpected checkpoint: %d %v", checkpointer.BlockNumber(), txIDs)
+	}
+}
+
+func testCheckpointTransactionNewBlock(t *testing.T, checkpointer Checkpointer) {
+	if err := checkpointer.CheckpointTransaction(3, "tx1"); err != nil {
+		t.Fatalf("Failed to checkpoint transaction: %s", err)
+	}
+	if err := checkpointer.CheckpointTransaction(4, "tx2"); err != nil {
+		t.Fatalf("Failed to checkpoint transaction: %s", err)
+	}
+
+	txIDs := checkpointer.TransactionIDs()
+	if checkpointer.BlockNumber() != 4 || len(txIDs) != 1 || txIDs[0] != "tx2" {
+		t.Fatalf("Unexpected checkpoint: %d %v", checkpointer.BlockNumber(), txIDs)
+	}
+}
+
+func testCheckpointDuplicateTransaction(t *testing.T, checkpointer Checkpointer) {
+	for i := 0; i < 2; i++ {
+		if err := checkpointer.CheckpointTransaction(3, "tx1"); err != nil {
+			t.Fatalf("Failed to checkpoint transaction: %s", err)
+		}
+	}
+
+	if txIDs := checkpointer.TransactionIDs(); len(txIDs) != 1 {
+		t.Fatalf("Duplicate transaction ID recorded: %v", txIDs)
+	}
+}
+
+func TestBlockEventWithCheckpointer(t *testing.T) {
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: defaultTimeout,
+		},
+	}
+
+	nw, err := newNetwork(gw, mockChannelProvider("mychannel"))
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	checkpointer := NewInMemoryCheckpointer()
+	checkpointer.CheckpointBlock(4)
+
+	reg, notifier, err := nw.RegisterBlockEvent(WithCheckpointer(checkpointer))
+	if err != nil {
+		t.Fatalf("Failed to register block event: %s", err)
+	}
+
+	eventch := reg.(*checkpointRegistration).registration.(*dispatcher.BlockReg).Eventch
+	go func() {
+		for _, blockNum := range []uint64{4, 5} {
+			eventch <- &fab.BlockEvent{Block: &common.Block{Header: &common.BlockHeader{Number: blockNum}}}
+		}
+	}()
+
+	select {
+	case event := <-notifier:
+		if event.Block.Header.Number != 5 {
+			t.Fatalf("Received already processed block: %d", event.Block.Header.Number)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Did NOT receive block event")
+	}
+
+	nw.Unregister(reg)
+
+	if _, ok := <-notifier; ok {
+		t.Fatal("Event channel should be closed after Unregister")
+	}
+}
+
+func TestFilteredBlockEventWithCheckpointer(t *testing.T) {
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: defaultTimeout,
+		},
+	}
+
+	nw, err := newNetwork(gw, mockChannelProvider("mychannel"))
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	checkpointer := NewInMemoryCheckpointer()
+	checkpointer.CheckpointBlock(4)
+
+	reg, notifier, err := nw.RegisterFilteredBlockEvent(WithCheckpointer(checkpointer))
+	if err != nil {
+		t.Fatalf("Failed to register filtered block event: %s", err)
+	}
+
+	eventch := reg.(*checkpointRegistration).registration.(*dispatcher.FilteredBlockReg).Eventch
+	go func() {
+		for _, blockNum := range []uint64{4, 5} {
+			eventch <- &fab.FilteredBlockEvent{FilteredBlock: &peer.FilteredBlock{Number: blockNum}}
+		}
+	}()
+
+	select {
+	case event := <-notifier:
+		if event.FilteredBlock.Number != 5 {
+			t.Fatalf("Received already processed block: %d", event.FilteredBlock.Number)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Did NOT receive filtered block event")
+	}
+
+	nw.Unregister(reg)
+}
+
+func TestFilteredBlockEventWithCheckpointedTransactions(t *testing.T) {
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: defaultTimeout,
+		},
+	}
+
+	nw, err := newNetwork(gw, mockChannelProvider("mychannel"))
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	checkpointer := NewInMemoryCheckpointer()
+	checkpointer.CheckpointTransaction(5, "tx1")
+
+	reg, notifier, err := nw.RegisterFilteredBlockEvent(WithCheckpointer(checkpointer))
+	if err != nil {
+		t.Fatalf("Failed to register filtered block event: %s", err)
+	}
+	defer nw.Unregister(reg)
+
+	eventch := reg.(*checkpointRegistration).registration.(*dispatcher.FilteredBlockReg).Eventch
+	go func() {
+		eventch <- &fab.FilteredBlockEvent{FilteredBlock: &peer.FilteredBlock{
+			Number:               5,
+			FilteredTransactions: []*peer.FilteredTransaction{{Txid: "tx1"}, {Txid: "tx2"}},
+		}}
+	}()
+
+	select {
+	case event := <-notifier:
+		txs := event.FilteredBlock.FilteredTransactions
+		if event.FilteredBlock.Number != 5 || len(txs) != 1 || txs[0].Txid != "tx2" {
+			t.Fatalf("Unexpected filtered block event: %v", event.FilteredBlock)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Did NOT receive filtered block event")
+	}
+}
+
+func TestCheckpointEventServiceOptions(t *testing.T) {
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: defaultTimeout,
+		},
+	}
+
+	params := &eventServiceParams{}
+	nw, err := newNetwork(gw, func() (context.Channel, error) {
+		ch, err := mocks.NewMockChannel("mychannel")
+		if err != nil {
+			return nil, err
+		}
+		return &eventParamsChannel{Channel: ch, params: params}, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	checkpointer := NewInMemoryCheckpointer()
+	checkpointer.CheckpointBlock(4)
+
+	*params = eventServiceParams{}
+	reg, _, err := nw.RegisterFilteredBlockEvent(WithCheckpointer(checkpointer))
+	if err != nil {
+		t.Fatalf("Failed to register filtered block event: %s", err)
+	}
+	nw.Unregister(reg)
+
+	if params.permitBlockEvents || params.seekType != seek.FromBlock || params.fromBlock != 5 {
+		t.Fatalf("Unexpected event service options for filtered block events: %+v", *params)
+	}
+
+	*params = eventServiceParams{}
+	reg, _, err = nw.RegisterBlockEvent(WithCheckpointer(checkpointer))
+	if err != nil {
+		t.Fatalf("Failed to register block event: %s", err)
+	}
+	nw.Unregister(reg)
+
+	if !params.permitBlockEvents || params.seekType != seek.FromBlock || params.fromBlock != 5 {
+		t.Fatalf("Unexpected event service options for block events: %+v", *params)
+	}
+}
+
+// eventParamsChannel records the options of the event services obtained from its channel service
+type eventParamsChannel struct {
+	*mocks.Channel
+	params *eventServiceParams
+}
+
+func (c *eventParamsChannel) ChannelService() fab.ChannelService {
+	return &eventParamsChannelService{ChannelService: c.Channel.ChannelService(), params: c.params}
+}
+
+type eventParamsChannelService struct {
+	fab.ChannelService
+	params *eventServiceParams
+}
+
+func (s *eventParamsChannelService) EventService(opts ...options.Opt) (fab.EventService, error) {
+	options.Apply(s.params, opts)
+	return s.ChannelService.EventService(opts...)
+}
+
+type eventServiceParams struct {
+	permitBlockEvents bool
+	seekType          seek.Type
+	fromBlock         uint64
+}
+
+func (p *eventServiceParams) PermitBlockEvents() {
+	p.permitBlockEvents = true
+}
+
+func (p *eventServiceParams) SetSeekType(value seek.Type) {
+	p.seekType = value
+}
+
+func (p *eventServiceParams) SetFromBlock(value uint64) {
+	p.fromBlock = value
+}
+
+func TestContractEventWithCheckpointer(t *testing.T) {
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: defaultTimeout,
+		},
+	}
+
+	nw, err := newNetwork(gw, mockChannelProvider("mychannel"))
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	contr := nw.GetContract("contract1")
+
+	checkpointer := NewInMemoryCheckpointer()
+	checkpointer.CheckpointTransaction(5, "tx1")
+
+	reg, notifier, err := contr.RegisterEvent("test([a-zA-Z]+)", WithCheckpointer(checkpointer))
+	if err != nil {
+		t.Fatalf("Failed to register contract event: %s", err)
+	}
+
+	eventch := reg.(*checkpointRegistration).registration.(*dispatcher.ChaincodeReg).Eventch
+	go func() {
+		eventch <- &fab.CCEvent{TxID: "tx0", BlockNumber: 4}
+		eventch <- &fab.CCEvent{TxID: "tx1", BlockNumber: 5}
+		eventch <- &fab.CCEvent{TxID: "tx2", BlockNumber: 5}
+	}()
+
+	select {
+	case event := <-notifier:
+		if event.TxID != "tx2" {
+			t.Fatalf("Received already processed event: %s", event.TxID)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Did NOT receive chaincode event")
+	}
+
+	contr.Unregister(reg)
+
+	if _, ok := <-notifier; ok {
+		t.Fatal("Event channel should be closed after Unregister")
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// Codec encodes the arguments passed to transaction functions, and decodes their results.
+// To use a custom encoding, implement this interface and pass an instance to the WithCodec option.
+type Codec interface {
+	// Marshal encodes a value as a transaction function argument
+	Marshal(value interface{}) ([]byte, error)
+	// Unmarshal decodes the result of a transaction function into the value pointed to by target
+	Unmarshal(data []byte, target interface{}) error
+}
+
+// NewJSONCodec creates a codec that encodes values as JSON. This is the default codec of a TypedContract.
+// Strings and byte slices are passed as is rather than as JSON strings, which matches the way the
+// contract APIs of Hyperledger Fabric receive string arguments and return string results.
+func NewJSONCodec() Codec {
+	return &jsonCodec{}
+}
+
+// NewProtobufCodec creates a codec that encodes values using protocol buffers. All arguments and
+// results must be protobuf messages.
+func NewProtobufCodec() Codec {
+	return &protobufCodec{}
+}
+
+type jsonCodec struct{}
+
+func (c *jsonCodec) Marshal(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSON marshal failed")
+	}
+	return data, nil
+}
+
+func (c *jsonCodec) Unmarshal(data []byte, target interface{}) error {
+	switch t := target.(type) {
+	case *string:
+		*t = string(data)
+		return nil
+	case *[]byte:
+		*t = append([]byte(nil), data...)
+		return nil
+	}
+
+	if err := json.Unmarshal(data, target); err != nil {
+		return errors.Wrap(err, "JSON unmarshal failed")
+	}
+	return nil
+}
+
+type protobufCodec struct{}
+
+func (c *protobufCodec) Marshal(value interface{}) ([]byte, error) {
+	message, ok := value.(proto.Message)
+	if !ok {
+		return nil, errors.Errorf("value of type %T is not a protobuf message", value)
+	}
+
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return nil, errors.Wrap(err, "protobuf marshal failed")
+	}
+	return data, nil
+}
+
+func (c *protobufCodec) Unmarshal(data []byte, target interface{}) error {
+	message, ok := target.(proto.Message)
+	if !ok {
+		return errors.Errorf("target of type %T is not a protobuf message", target)
+	}
+
+	if err := proto.Unmarshal(data, message); err != nil {
+		return errors.Wrap(err, "protobuf unmarshal failed")
+	}
+	return nil
+}
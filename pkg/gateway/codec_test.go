@@ -0,0 +1,100 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"testing"
+
+	gp "github.com/hyperledger/fabric-protos-go/gateway"
+)
+
+type testAsset struct {
+	ID    string `json:"id"`
+	Owner string `json:"owner"`
+	Value int    `json:"value"`
+}
+
+func TestJSONCodec(t *testing.T) {
+	codec := NewJSONCodec()
+
+	data, err := codec.Marshal(&testAsset{ID: "asset1", Owner: "Tom", Value: 100})
+	if err != nil {
+		t.Fatalf("Failed to marshal: %s", err)
+	}
+
+	if string(data) != `{"id":"asset1","owner":"Tom","value":100}` {
+		t.Fatalf("Unexpected encoding: %s", data)
+	}
+
+	asset := &testAsset{}
+	if err := codec.Unmarshal(data, asset); err != nil {
+		t.Fatalf("Failed to unmarshal: %s", err)
+	}
+
+	if asset.ID != "asset1" || asset.Owner != "Tom" || asset.Value != 100 {
+		t.Fatalf("Unexpected value: %#v", asset)
+	}
+
+	if err := codec.Unmarshal([]byte("abc"), asset); err == nil {
+		t.Fatal("Expected unmarshal error")
+	}
+}
+
+func TestJSONCodecStrings(t *testing.T) {
+	codec := NewJSONCodec()
+
+	data, err := codec.Marshal("asset1")
+	if err != nil || string(data) != "asset1" {
+		t.Fatalf("Unexpected string encoding: %s, %v", data, err)
+	}
+
+	data, err = codec.Marshal([]byte{0x00, 0xff})
+	if err != nil || len(data) != 2 || data[1] != 0xff {
+		t.Fatalf("Unexpected bytes encoding: %v, %v", data, err)
+	}
+
+	data, err = codec.Marshal(42)
+	if err != nil || string(data) != "42" {
+		t.Fatalf("Unexpected int encoding: %s, %v", data, err)
+	}
+
+	var s string
+	if err := codec.Unmarshal([]byte("abc"), &s); err != nil || s != "abc" {
+		t.Fatalf("Unexpected string decoding: %s, %v", s, err)
+	}
+
+	var b []byte
+	if err := codec.Unmarshal([]byte("abc"), &b); err != nil || string(b) != "abc" {
+		t.Fatalf("Unexpected bytes decoding: %s, %v", b, err)
+	}
+}
+
+func TestProtobufCodec(t *testing.T) {
+	codec := NewProtobufCodec()
+
+	data, err := codec.Marshal(&gp.ErrorDetail{Address: "peer1:7051", MspId: "Org1MSP", Message: "msg"})
+	if err != nil {
+		t.Fatalf("Failed to marshal: %s", err)
+	}
+
+	detail := &gp.ErrorDetail{}
+	if err := codec.Unmarshal(data, detail); err != nil {
+		t.Fatalf("Failed to unmarshal: %s", err)
+	}
+
+	if detail.Address != "peer1:7051" || detail.MspId != "Org1MSP" || detail.Message != "msg" {
+		t.Fatalf("Unexpected value: %#v", detail)
+	}
+
+	if _, err := codec.Marshal(&testAsset{}); err == nil {
+		t.Fatal("Expected error marshalling a value which is not a protobuf message")
+	}
+
+	if err := codec.Unmarshal(data, &testAsset{}); err == nil {
+		t.Fatal("Expected error unmarshalling into a value which is not a protobuf message")
+	}
+}
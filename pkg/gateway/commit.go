@@ -0,0 +1,95 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	reqContext "context"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// commitWaitFunc waits for a submitted transaction to be committed and returns its commit event.
+// A nil event is returned, without error, if the commit is not awaited.
+type commitWaitFunc = func(ctx reqContext.Context) (*fab.TxStatusEvent, error)
+
+// Commit represents a transaction that has been submitted to the orderer using SubmitAsync, and
+// provides access to its commit status.
+type Commit struct {
+	transactionID string
+	result        []byte
+	done          chan struct{}
+	txStatus      *fab.TxStatusEvent
+	err           error
+}
+
+func newCommit(transactionID string, result []byte) *Commit {
+	return &Commit{
+		transactionID: transactionID,
+		result:        result,
+		done:          make(chan struct{}),
+	}
+}
+
+// TransactionID returns the ID of the submitted transaction
+func (c *Commit) TransactionID() string {
+	return c.transactionID
+}
+
+// Result returns the return value of the transaction function, as endorsed by the peers
+func (c *Commit) Result() []byte {
+	return c.result
+}
+
+// Done returns a channel that is closed when the commit status of the transaction is known, either
+// because the transaction was committed or because waiting for the commit failed or timed out.
+func (c *Commit) Done() <-chan struct{} {
+	return c.done
+}
+
+// Status waits for the transaction to be committed and returns the outcome of the transaction.
+//  Parameters:
+//  ctx bounds the wait. If ctx is done before the commit status is known, ctx.Err() is returned.
+//
+//  Returns:
+//  The outcome of the transaction, or an error if the transaction was not committed successfully.
+//  If the transaction was committed as invalid, the outcome, including the validation code, is returned with the error.
+func (c *Commit) Status(ctx reqContext.Context) (*SubmitResult, error) {
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	result := &SubmitResult{
+		Payload:       c.result,
+		TransactionID: c.transactionID,
+	}
+	if c.txStatus != nil {
+		result.BlockNumber = c.txStatus.BlockNumber
+		result.TxValidationCode = c.txStatus.TxValidationCode
+	} else if c.err != nil {
+		return nil, c.err
+	}
+
+	return result, c.err
+}
+
+// complete waits in the background for the commit of the transaction and records the outcome
+func (c *Commit) complete(wait commitWaitFunc, timeout time.Duration, notify func(*fab.TxStatusEvent)) {
+	go func() {
+		defer close(c.done)
+
+		ctx, cancel := reqContext.WithTimeout(reqContext.Background(), timeout)
+		defer cancel()
+
+		c.txStatus, c.err = wait(ctx)
+		if c.err == nil && notify != nil {
+			notify(c.txStatus)
+		}
+	}()
+}
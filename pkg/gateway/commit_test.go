@@ -0,0 +1,197 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	reqContext "context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestSubmitAsync(t *testing.T) {
+	c := mockChannelProvider("mychannel")
+
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: defaultTimeout,
+		},
+	}
+
+	nw, err := newNetwork(gw, c)
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	contr := nw.GetContract("contract1")
+	txn, err := contr.CreateTransaction("txn1")
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+	notifier := txn.RegisterCommitEvent()
+
+	commit, err := txn.SubmitAsync("arg1", "arg2")
+	if err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	if string(commit.Result()) != "abc" {
+		t.Fatalf("Incorrect transaction result: %s", commit.Result())
+	}
+
+	select {
+	case <-commit.Done():
+	case <-time.After(time.Second * 5):
+		t.Fatal("Commit not done")
+	}
+
+	result, err := commit.Status(reqContext.Background())
+	if err != nil {
+		t.Fatalf("Failed to get commit status: %s", err)
+	}
+
+	if string(result.Payload) != "abc" || result.TransactionID != commit.TransactionID() {
+		t.Fatalf("Unexpected commit status: %#v", result)
+	}
+
+	if result.TxValidationCode != peer.TxValidationCode_VALID {
+		t.Fatalf("Unexpected validation code: %s", result.TxValidationCode)
+	}
+
+	select {
+	case cEvent := <-notifier:
+		if cEvent.TxID != commit.TransactionID() {
+			t.Fatalf("Unexpected commit event: %#v", cEvent)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Did NOT receive commit event")
+	}
+}
+
+func TestSubmitHandlerAsyncCommitError(t *testing.T) {
+	request := invoke.Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+
+	requestContext := prepareRequestContext(request, invoke.Opts{}, t)
+
+	mockPeer := &mocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockRoles: []string{}, MockCert: nil, MockMSP: "Org1MSP",
+		Status: 200, Payload: []byte("value")}
+
+	clientContext := setupChannelClientContext(nil, nil, []fab.Peer{mockPeer}, t)
+
+	addProposalResponse(requestContext)
+	clientContext.EventService.(*mocks.MockEventService).TxValidationCode = peer.TxValidationCode_MVCC_READ_CONFLICT
+
+	commitHandler := &commitTxHandler{async: true, timeout: testTimeOut}
+	commitHandler.Handle(requestContext, clientContext)
+	if requestContext.Error != nil {
+		t.Fatalf("Unexpected error: %s", requestContext.Error)
+	}
+
+	result, err := commitHandler.commit.Status(reqContext.Background())
+	if err == nil || !strings.Contains(err.Error(), txError) {
+		t.Fatalf("Expected error: %s, Received error: %v", txError, err)
+	}
+
+	if result == nil || result.TxValidationCode != peer.TxValidationCode_MVCC_READ_CONFLICT {
+		t.Fatalf("Unexpected commit status: %#v", result)
+	}
+}
+
+func TestCommitStatusCancelled(t *testing.T) {
+	commit := newCommit("txn1", []byte("abc"))
+
+	ctx, cancel := reqContext.WithCancel(reqContext.Background())
+	cancel()
+
+	_, err := commit.Status(ctx)
+	if err != reqContext.Canceled {
+		t.Fatalf("Expected error: %s, Received error: %v", reqContext.Canceled, err)
+	}
+
+	select {
+	case <-commit.Done():
+		t.Fatal("Commit should not be done")
+	default:
+	}
+}
+
+func TestCommitTimeout(t *testing.T) {
+	commit := newCommit("txn1", []byte("abc"))
+
+	commit.complete(func(ctx reqContext.Context) (*fab.TxStatusEvent, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, time.Millisecond, nil)
+
+	_, err := commit.Status(reqContext.Background())
+	if err != reqContext.DeadlineExceeded {
+		t.Fatalf("Expected error: %s, Received error: %v", reqContext.DeadlineExceeded, err)
+	}
+}
+
+func TestPeerGatewaySubmitAsync(t *testing.T) {
+	server := &mocks.MockGatewayServer{Payload: []byte("abc"), BlockNumber: 7}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	txn, err := contr.CreateTransaction("txn1")
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+
+	commit, err := txn.SubmitAsync("arg1", "arg2")
+	if err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	if string(commit.Result()) != "abc" {
+		t.Fatalf("Incorrect transaction result: %s", commit.Result())
+	}
+
+	result, err := commit.Status(reqContext.Background())
+	if err != nil {
+		t.Fatalf("Failed to get commit status: %s", err)
+	}
+
+	if result.BlockNumber != 7 || result.TransactionID != server.LastSubmitRequest().TransactionId {
+		t.Fatalf("Unexpected commit status: %#v", result)
+	}
+}
+
+func TestPeerGatewaySubmitAsyncCommitError(t *testing.T) {
+	server := &mocks.MockGatewayServer{TxValidationCode: peer.TxValidationCode_MVCC_READ_CONFLICT}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	txn, err := contr.CreateTransaction("txn1")
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+
+	commit, err := txn.SubmitAsync("arg1", "arg2")
+	if err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	result, err := commit.Status(reqContext.Background())
+	if err == nil || !strings.Contains(err.Error(), txError) {
+		t.Fatalf("Expected error: %s, Received error: %v", txError, err)
+	}
+
+	if result == nil || result.TxValidationCode != peer.TxValidationCode_MVCC_READ_CONFLICT {
+		t.Fatalf("Unexpected commit status: %#v", result)
+	}
+}
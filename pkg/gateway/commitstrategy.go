@@ -0,0 +1,315 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	reqContext "context"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/provider/chpvdr"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/concurrent/lazycache"
+	"github.com/pkg/errors"
+)
+
+// CommitStrategy determines the peers from which commit events are received for a submitted transaction,
+// and when the transaction is considered to be committed.
+// To create a custom strategy, implement both methods of this interface and pass an instance to the
+// WithCommitStrategy option.
+type CommitStrategy interface {
+	// Peers returns the peers from which commit events are to be received.
+	//  channelPeers are the peers of the channel
+	//  mspID is the MSP ID of the organization of the client identity
+	// If no peers are returned, Done is called immediately after the transaction has been sent to the orderer.
+	Peers(channelPeers []fab.Peer, mspID string) []fab.Peer
+	// Done is called each time a peer reports that the transaction has been committed, or the commit event
+	// of a peer cannot be obtained. It returns true when submit no longer needs to wait, or an error if the
+	// transaction cannot be considered committed.
+	//  peers is the number of peers from which commit events are received
+	//  committed is the number of peers that have reported that the transaction is committed
+	//  failed is the number of peers for which the commit event could not be obtained
+	Done(peers, committed, failed int) (bool, error)
+}
+
+var (
+	// MSPIDScopeAnyForTx waits for the transaction to be committed by any peer in the organization of the client.
+	MSPIDScopeAnyForTx CommitStrategy = &scopedCommitStrategy{orgScope: true, all: false}
+	// MSPIDScopeAllForTx waits for the transaction to be committed by all the reachable peers in the
+	// organization of the client.
+	MSPIDScopeAllForTx CommitStrategy = &scopedCommitStrategy{orgScope: true, all: true}
+	// NetworkScopeAnyForTx waits for the transaction to be committed by any peer in the channel.
+	NetworkScopeAnyForTx CommitStrategy = &scopedCommitStrategy{orgScope: false, all: false}
+	// NetworkScopeAllForTx waits for the transaction to be committed by all the reachable peers in the channel.
+	NetworkScopeAllForTx CommitStrategy = &scopedCommitStrategy{orgScope: false, all: true}
+	// NoCommitWait returns as soon as the transaction has been sent to the orderer, without waiting for it to be committed.
+	NoCommitWait CommitStrategy = &noCommitWaitStrategy{}
+)
+
+// WithCommitStrategy is an optional argument to the Connect method which selects the strategy used by
+// Submit to wait for transactions to be committed. If this option is not specified, Submit waits for the
+// commit event from the peer to which the channel event service is connected.
+// Commit strategies are not applicable when transactions are submitted using WithPeerGateway, in
+// which case the commit status is obtained from the gateway peer.
+//
+//   Parameters:
+//   strategy is one of the predefined strategies, e.g. MSPIDScopeAllForTx, or a custom strategy
+//
+//   Returns:
+//   An Option which can be passed as the third parameter to the Connect() function
+func WithCommitStrategy(strategy CommitStrategy) Option {
+	return func(gw *Gateway) error {
+		if strategy == nil {
+			return errors.New("commit strategy must not be nil")
+		}
+		gw.options.CommitStrategy = strategy
+		return nil
+	}
+}
+
+type scopedCommitStrategy struct {
+	orgScope bool
+	all      bool
+}
+
+func (s *scopedCommitStrategy) Peers(channelPeers []fab.Peer, mspID string) []fab.Peer {
+	if !s.orgScope {
+		return channelPeers
+	}
+
+	var peers []fab.Peer
+	for _, p := range channelPeers {
+		if p.MSPID() == mspID {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+func (s *scopedCommitStrategy) Done(peers, committed, failed int) (bool, error) {
+	if peers == 0 {
+		return false, errors.New("no peers available to receive commit events")
+	}
+
+	if !s.all && committed > 0 {
+		return true, nil
+	}
+
+	if committed+failed < peers {
+		return false, nil
+	}
+
+	if committed == 0 {
+		return false, errors.New("commit events could not be obtained from any peer")
+	}
+
+	return true, nil
+}
+
+type noCommitWaitStrategy struct{}
+
+func (s *noCommitWaitStrategy) Peers(channelPeers []fab.Peer, mspID string) []fab.Peer {
+	return nil
+}
+
+func (s *noCommitWaitStrategy) Done(peers, committed, failed int) (bool, error) {
+	return true, nil
+}
+
+type peerEventServiceProvider = func(ctx context.Channel, peer fab.Peer) (fab.EventService, error)
+
+// peerEventServices caches an event service for each peer, so that the connection to a peer is shared by all
+// the transactions submitted to a network. As for the event services of the SDK, the connection to a peer is
+// closed when it has been idle for the event service idle timeout, and is opened again on next use.
+type peerEventServices struct {
+	cache *lazycache.Cache
+}
+
+func newPeerEventServices() *peerEventServices {
+	cache := lazycache.NewWithData(
+		"Peer_Event_Service_Cache",
+		func(key lazycache.Key, data interface{}) (interface{}, error) {
+			params := data.(*peerEventServiceParams)
+			idleTimeout := params.ctx.EndpointConfig().Timeout(fab.EventServiceIdle)
+			return chpvdr.NewEventClientRef(idleTimeout, func() (fab.EventClient, error) {
+				return newPeerEventClient(params.ctx, params.peer)
+			}), nil
+		},
+	)
+
+	return &peerEventServices{cache: cache}
+}
+
+// get returns the event service of the given peer
+func (s *peerEventServices) get(ctx context.Channel, peer fab.Peer) (fab.EventService, error) {
+	ref, err := s.cache.Get(lazycache.NewStringKey(peer.URL()), &peerEventServiceParams{ctx: ctx, peer: peer})
+	if err != nil {
+		return nil, err
+	}
+	return ref.(fab.EventService), nil
+}
+
+// close closes the event services of all the peers
+func (s *peerEventServices) close() {
+	s.cache.Close()
+}
+
+type peerEventServiceParams struct {
+	ctx  context.Channel
+	peer fab.Peer
+}
+
+// newPeerEventClient connects a deliver client to the given peer only
+func newPeerEventClient(ctx context.Channel, peer fab.Peer) (fab.EventClient, error) {
+	chConfig, err := ctx.ChannelService().ChannelConfig()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get channel config")
+	}
+
+	return deliverclient.New(ctx, chConfig, &peerDiscovery{peer: peer})
+}
+
+// peerDiscovery is a discovery service which only returns a single peer
+type peerDiscovery struct {
+	peer fab.Peer
+}
+
+func (d *peerDiscovery) GetPeers() ([]fab.Peer, error) {
+	return []fab.Peer{d.peer}, nil
+}
+
+// strategyCommitHandler waits for the commit events of a transaction according to a commit strategy
+type strategyCommitHandler struct {
+	commitHandler *commitTxHandler
+	strategy      CommitStrategy
+	ctx           context.Channel
+	eventService  peerEventServiceProvider
+}
+
+// Handle sends the transaction to the orderer and waits for commit events from the peers selected by the strategy
+func (c *strategyCommitHandler) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	txnID := string(requestContext.Response.TransactionID)
+
+	channelPeers, err := clientContext.Discovery.GetPeers()
+	if err != nil {
+		requestContext.Error = errors.WithMessage(err, "failed to get channel peers")
+		return
+	}
+
+	mspID := c.ctx.Identifier().MSPID
+	if requestContext.Opts.Identity != nil {
+		mspID = requestContext.Opts.Identity.Identifier().MSPID
+	}
+
+	peers := c.strategy.Peers(channelPeers, mspID)
+
+	// register for the commit events before sending the transaction so that no events are missed
+	failed := 0
+	var notifiers []<-chan *fab.TxStatusEvent
+	var closeFns []func()
+	closeAll := func() {
+		for _, closeFn := range closeFns {
+			closeFn()
+		}
+	}
+	for _, p := range peers {
+		notifier, closeFn, err := c.registerTxStatusEvent(p, txnID)
+		if err != nil {
+			logger.Warnf("unable to receive commit events from peer [%s]: %s", p.URL(), err)
+			failed++
+			continue
+		}
+		closeFns = append(closeFns, closeFn)
+		notifiers = append(notifiers, notifier)
+	}
+
+	_, err = createAndSendTransaction(clientContext.Transactor, requestContext.Response.Proposal, requestContext.Response.Responses)
+	if err != nil {
+		closeAll()
+		requestContext.Error = errors.Wrap(err, "CreateAndSendTransaction failed")
+		return
+	}
+
+	c.commitHandler.complete(requestContext, func(ctx reqContext.Context) (*fab.TxStatusEvent, error) {
+		defer closeAll()
+		return c.wait(ctx, len(peers), failed, notifiers)
+	})
+}
+
+// wait collects the commit events of the peers until the strategy is satisfied, returning the first commit event received
+func (c *strategyCommitHandler) wait(ctx reqContext.Context, peers int, failed int, notifiers []<-chan *fab.TxStatusEvent) (*fab.TxStatusEvent, error) {
+	done, err := c.strategy.Done(peers, 0, failed)
+	if err != nil {
+		return nil, errors.WithMessage(err, "commit strategy failed")
+	}
+	if done {
+		return nil, nil
+	}
+
+	results := make(chan *fab.TxStatusEvent, len(notifiers))
+	stop := make(chan struct{})
+	defer close(stop)
+	for _, notifier := range notifiers {
+		go func(notifier <-chan *fab.TxStatusEvent) {
+			select {
+			case txStatus := <-notifier:
+				results <- txStatus
+			case <-stop:
+			}
+		}(notifier)
+	}
+
+	var first *fab.TxStatusEvent
+	committed := 0
+	for {
+		select {
+		case txStatus := <-results:
+			if txStatus == nil {
+				failed++
+			} else {
+				if txStatus.TxValidationCode != peer.TxValidationCode_VALID {
+					return txStatus, status.New(status.EventServerStatus, int32(txStatus.TxValidationCode),
+						"received invalid transaction", nil)
+				}
+				committed++
+				if first == nil {
+					first = txStatus
+				}
+			}
+
+			done, err := c.strategy.Done(peers, committed, failed)
+			if err != nil {
+				return first, errors.WithMessage(err, "commit strategy failed")
+			}
+			if done {
+				return first, nil
+			}
+		case <-ctx.Done():
+			return first, status.New(status.ClientStatus, status.Timeout.ToInt32(),
+				"Execute didn't receive block event", nil)
+		}
+	}
+}
+
+func (c *strategyCommitHandler) registerTxStatusEvent(p fab.Peer, txnID string) (<-chan *fab.TxStatusEvent, func(), error) {
+	eventService, err := c.eventService(c.ctx, p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reg, notifier, err := eventService.RegisterTxStatusEvent(txnID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error registering for TxStatus event")
+	}
+
+	return notifier, func() {
+		eventService.Unregister(reg)
+	}, nil
+}
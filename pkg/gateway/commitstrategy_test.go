@@ -0,0 +1,269 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/provider/chpvdr"
+	"github.com/pkg/errors"
+)
+
+func TestCommitStrategyPeers(t *testing.T) {
+	org1Peer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "peer1.org1.com", MockMSP: "Org1MSP"}
+	org2Peer := &fcmocks.MockPeer{MockName: "Peer2", MockURL: "peer1.org2.com", MockMSP: "Org2MSP"}
+	channelPeers := []fab.Peer{org1Peer, org2Peer}
+
+	tests := []struct {
+		strategy CommitStrategy
+		expected int
+	}{
+		{MSPIDScopeAnyForTx, 1},
+		{MSPIDScopeAllForTx, 1},
+		{NetworkScopeAnyForTx, 2},
+		{NetworkScopeAllForTx, 2},
+		{NoCommitWait, 0},
+	}
+
+	for _, test := range tests {
+		peers := test.strategy.Peers(channelPeers, "Org1MSP")
+		if len(peers) != test.expected {
+			t.Fatalf("Expected %d peers, got %d", test.expected, len(peers))
+		}
+		for _, p := range peers {
+			if test.strategy == MSPIDScopeAnyForTx && p.MSPID() != "Org1MSP" {
+				t.Fatalf("Unexpected peer selected: %s", p.URL())
+			}
+		}
+	}
+}
+
+func TestCommitStrategyDone(t *testing.T) {
+	tests := []struct {
+		strategy  CommitStrategy
+		peers     int
+		committed int
+		failed    int
+		done      bool
+		err       bool
+	}{
+		{MSPIDScopeAnyForTx, 2, 0, 0, false, false},
+		{MSPIDScopeAnyForTx, 2, 1, 0, true, false},
+		{MSPIDScopeAnyForTx, 2, 0, 2, false, true},
+		{MSPIDScopeAnyForTx, 0, 0, 0, false, true},
+		{MSPIDScopeAllForTx, 2, 1, 0, false, false},
+		{MSPIDScopeAllForTx, 2, 2, 0, true, false},
+		{MSPIDScopeAllForTx, 2, 1, 1, true, false},
+		{NetworkScopeAllForTx, 3, 0, 3, false, true},
+		{NoCommitWait, 0, 0, 0, true, false},
+	}
+
+	for i, test := range tests {
+		done, err := test.strategy.Done(test.peers, test.committed, test.failed)
+		if done != test.done || (err != nil) != test.err {
+			t.Fatalf("Test %d: unexpected result: done=%t err=%v", i, done, err)
+		}
+	}
+}
+
+func TestWithCommitStrategy(t *testing.T) {
+	gw := &Gateway{options: &gatewayOptions{}}
+
+	if err := WithCommitStrategy(MSPIDScopeAllForTx)(gw); err != nil {
+		t.Fatalf("Failed to apply commit strategy option: %s", err)
+	}
+	if gw.options.CommitStrategy != MSPIDScopeAllForTx {
+		t.Fatal("Commit strategy not set")
+	}
+
+	if err := WithCommitStrategy(nil)(gw); err == nil {
+		t.Fatal("Expected error for nil commit strategy")
+	}
+}
+
+func TestStrategyCommitHandlerAllInOrg(t *testing.T) {
+	handler, requestContext, clientContext, registered := setupStrategyCommitHandler(t, MSPIDScopeAllForTx, nil)
+	notifier := make(chan *fab.TxStatusEvent, 1)
+	handler.commitHandler.eventch = notifier
+
+	handler.Handle(requestContext, clientContext)
+	if requestContext.Error != nil {
+		t.Fatalf("Failed to commit transaction: %s", requestContext.Error)
+	}
+
+	if len(*registered) != 2 || !containsString(*registered, "peer1.org1.com") || !containsString(*registered, "peer2.org1.com") {
+		t.Fatalf("Unexpected peers listened to: %v", *registered)
+	}
+
+	if _, ok := <-notifier; !ok {
+		t.Fatal("Did NOT receive commit event")
+	}
+}
+
+func TestStrategyCommitHandlerAllInNetwork(t *testing.T) {
+	handler, requestContext, clientContext, registered := setupStrategyCommitHandler(t, NetworkScopeAllForTx, nil)
+
+	handler.Handle(requestContext, clientContext)
+	if requestContext.Error != nil {
+		t.Fatalf("Failed to commit transaction: %s", requestContext.Error)
+	}
+
+	if len(*registered) != 3 {
+		t.Fatalf("Unexpected peers listened to: %v", *registered)
+	}
+}
+
+func TestStrategyCommitHandlerNoWait(t *testing.T) {
+	handler, requestContext, clientContext, registered := setupStrategyCommitHandler(t, NoCommitWait, nil)
+	notifier := make(chan *fab.TxStatusEvent, 1)
+	handler.commitHandler.eventch = notifier
+
+	handler.Handle(requestContext, clientContext)
+	if requestContext.Error != nil {
+		t.Fatalf("Failed to submit transaction: %s", requestContext.Error)
+	}
+
+	if len(*registered) != 0 {
+		t.Fatalf("No peers should be listened to: %v", *registered)
+	}
+
+	if _, ok := <-notifier; ok {
+		t.Fatal("No commit event expected")
+	}
+}
+
+func TestStrategyCommitHandlerInvalidTransaction(t *testing.T) {
+	handler, requestContext, clientContext, _ := setupStrategyCommitHandler(t, MSPIDScopeAnyForTx, nil)
+	handler.eventService = func(ctx context.Channel, p fab.Peer) (fab.EventService, error) {
+		eventService := fcmocks.NewMockEventService()
+		eventService.TxValidationCode = peer.TxValidationCode_MVCC_READ_CONFLICT
+		return eventService, nil
+	}
+
+	handler.Handle(requestContext, clientContext)
+	if requestContext.Error == nil || !strings.Contains(requestContext.Error.Error(), txError) {
+		t.Fatalf("Expected error: %s, Received error: %v", txError, requestContext.Error)
+	}
+}
+
+func TestStrategyCommitHandlerNoPeersReachable(t *testing.T) {
+	handler, requestContext, clientContext, _ := setupStrategyCommitHandler(t, MSPIDScopeAllForTx, errors.New(mockError))
+
+	handler.Handle(requestContext, clientContext)
+	if requestContext.Error == nil || !strings.Contains(requestContext.Error.Error(), "commit strategy failed") {
+		t.Fatalf("Expected commit strategy error, Received error: %v", requestContext.Error)
+	}
+}
+
+func TestPeerEventServiceProvider(t *testing.T) {
+	ctx, err := mockChannelProvider("mychannel")()
+	if err != nil {
+		t.Fatalf("Failed to create channel context: %s", err)
+	}
+
+	peer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "peer1.org1.com"}
+	peer2 := &fcmocks.MockPeer{MockName: "Peer2", MockURL: "peer2.org1.com"}
+
+	services := newPeerEventServices()
+	provider := services.get
+	eventService1, err := provider(ctx, peer1)
+	if err != nil {
+		t.Fatalf("Failed to get event service: %s", err)
+	}
+	eventService2, err := provider(ctx, peer2)
+	if err != nil {
+		t.Fatalf("Failed to get event service: %s", err)
+	}
+	cached, err := provider(ctx, peer1)
+	if err != nil {
+		t.Fatalf("Failed to get event service: %s", err)
+	}
+
+	if cached != eventService1 {
+		t.Fatal("Expected the event service of a peer to be reused")
+	}
+	if eventService2 == eventService1 {
+		t.Fatal("Expected a separate event service for each peer")
+	}
+	services.close()
+
+	if !eventService1.(*chpvdr.EventClientRef).Closed() || !eventService2.(*chpvdr.EventClientRef).Closed() {
+		t.Fatal("Expected the event services to be closed")
+	}
+	if _, err := provider(ctx, peer1); err == nil {
+		t.Fatal("Expected error getting event service after close")
+	}
+}
+
+func TestGatewayClose(t *testing.T) {
+	gw := &Gateway{options: &gatewayOptions{Timeout: defaultTimeout}}
+
+	nw, err := newNetwork(gw, mockChannelProvider("mychannel"))
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+	gw.networks = append(gw.networks, nw)
+
+	ctx, err := nw.channelProvider()
+	if err != nil {
+		t.Fatalf("Failed to create channel context: %s", err)
+	}
+	eventService, err := nw.peerEventService.get(ctx, &fcmocks.MockPeer{MockName: "Peer1", MockURL: "peer1.org1.com"})
+	if err != nil {
+		t.Fatalf("Failed to get event service: %s", err)
+	}
+
+	gw.Close()
+
+	if !eventService.(*chpvdr.EventClientRef).Closed() {
+		t.Fatal("Expected the event services of the network to be closed with the gateway")
+	}
+}
+
+func setupStrategyCommitHandler(t *testing.T, strategy CommitStrategy, connectErr error) (*strategyCommitHandler, *invoke.RequestContext, *invoke.ClientContext, *[]string) {
+	ctx, err := mockChannelProvider("mychannel")()
+	if err != nil {
+		t.Fatalf("Failed to create channel context: %s", err)
+	}
+	mspID := ctx.Identifier().MSPID
+
+	peers := []fab.Peer{
+		&fcmocks.MockPeer{MockName: "Peer1", MockURL: "peer1.org1.com", MockMSP: mspID},
+		&fcmocks.MockPeer{MockName: "Peer2", MockURL: "peer2.org1.com", MockMSP: mspID},
+		&fcmocks.MockPeer{MockName: "Peer3", MockURL: "peer1.org2.com", MockMSP: "Org2MSP"},
+	}
+
+	request := invoke.Request{ChaincodeID: "test", Fcn: "invoke"}
+	requestContext := prepareRequestContext(request, invoke.Opts{}, t)
+	addProposalResponse(requestContext)
+
+	clientContext := setupChannelClientContext(nil, nil, peers, t)
+	clientContext.Discovery = txnmocks.NewMockDiscoveryService(nil, peers...)
+
+	var registered []string
+	handler := &strategyCommitHandler{
+		commitHandler: &commitTxHandler{},
+		strategy:      strategy,
+		ctx:           ctx,
+		eventService: func(ctx context.Channel, p fab.Peer) (fab.EventService, error) {
+			if connectErr != nil {
+				return nil, connectErr
+			}
+			registered = append(registered, p.URL())
+			return fcmocks.NewMockEventService(), nil
+		},
+	}
+
+	return handler, requestContext, clientContext, &registered
+}
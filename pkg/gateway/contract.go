@@ -62,7 +62,8 @@ func (c *Contract) EvaluateTransaction(name string, args ...string) ([]byte, err
 //  args are the arguments to be sent to the transaction function.
 //
 //  Returns:
-//  The return value of the transaction function in the smart contract.
+//  The return value of the transaction function in the smart contract. Use NewRequest to obtain the
+//  transaction ID, block number and validation code of the committed transaction as a SubmitResult.
 func (c *Contract) SubmitTransaction(name string, args ...string) ([]byte, error) {
 	txn, err := c.CreateTransaction(name)
 
@@ -73,6 +74,56 @@ func (c *Contract) SubmitTransaction(name string, args ...string) ([]byte, error
 	return txn.Submit(args...)
 }
 
+// EvaluateTransactionBytes will evaluate a transaction function and return its results.
+// It is equivalent to EvaluateTransaction, except that each argument is passed to the
+// transaction function as is. This should be used when the arguments are binary encoded.
+//  Parameters:
+//  name is the name of the transaction function to be invoked in the smart contract.
+//  args are the arguments to be sent to the transaction function.
+//
+//  Returns:
+//  The return value of the transaction function in the smart contract.
+func (c *Contract) EvaluateTransactionBytes(name string, args ...[]byte) ([]byte, error) {
+	txn, err := c.CreateTransaction(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return txn.EvaluateBytes(args...)
+}
+
+// SubmitTransactionBytes will submit a transaction to the ledger.
+// It is equivalent to SubmitTransaction, except that each argument is passed to the
+// transaction function as is. This should be used when the arguments are binary encoded.
+//  Parameters:
+//  name is the name of the transaction function to be invoked in the smart contract.
+//  args are the arguments to be sent to the transaction function.
+//
+//  Returns:
+//  The return value of the transaction function in the smart contract.
+func (c *Contract) SubmitTransactionBytes(name string, args ...[]byte) ([]byte, error) {
+	txn, err := c.CreateTransaction(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return txn.SubmitBytes(args...)
+}
+
+// NewRequest creates a builder for an invocation of a transaction function implemented by this
+// contract. The arguments, transient data, endorsing peers and collections of the invocation are
+// set on the returned request, which is then either evaluated or submitted.
+//  Parameters:
+//  name is the name of the transaction function to be invoked in the smart contract.
+//
+//  Returns:
+//  A TransactionRequest which can be evaluated or submitted.
+func (c *Contract) NewRequest(name string) *TransactionRequest {
+	return newTransactionRequest(c, name)
+}
+
 // CreateTransaction creates an object representing a specific invocation of a transaction
 // function implemented by this contract, and provides more control over
 // the transaction invocation using the optional arguments. A new transaction object must
@@ -90,16 +141,71 @@ func (c *Contract) CreateTransaction(name string, opts ...TransactionOption) (*T
 // RegisterEvent registers for chaincode events. Unregister must be called when the registration is no longer needed.
 //  Parameters:
 //  eventFilter is the chaincode event filter (regular expression) for which events are to be received
+//  opts are the options for the registration, e.g. WithCheckpointer to resume from a checkpoint.
 //
 //  Returns:
 //  the registration and a channel that is used to receive events. The channel is closed when Unregister is called.
-func (c *Contract) RegisterEvent(eventFilter string) (fab.Registration, <-chan *fab.CCEvent, error) {
-	return c.network.event.RegisterChaincodeEvent(c.chaincodeID, eventFilter)
+func (c *Contract) RegisterEvent(eventFilter string, opts ...EventOption) (fab.Registration, <-chan *fab.CCEvent, error) {
+	options, err := newEventOptions(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cp *checkpoint
+	if options.checkpointer != nil {
+		cp = newCheckpoint(options.checkpointer)
+	}
+
+	if c.network.peerGw != nil {
+		var startBlock *uint64
+		if cp != nil {
+			startBlock = &cp.blockNumber
+		} else if c.network.gateway.options.FromBlockSet {
+			startBlock = &c.network.gateway.options.FromBlock
+		}
+
+		reg, eventch, err := c.network.peerGw.registerChaincodeEvent(c.chaincodeID, eventFilter, startBlock)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if cp == nil {
+			return reg, eventch, nil
+		}
+
+		cpReg := newCheckpointRegistration(reg, reg.close)
+		return cpReg, cpReg.forwardChaincodeEvents(cp, eventch), nil
+	}
+
+	if options.checkpointer == nil {
+		return c.network.event.RegisterChaincodeEvent(c.chaincodeID, eventFilter)
+	}
+
+	// block events are required for the payloads of chaincode events
+	eventService, err := c.network.checkpointEventService(cp, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reg, eventch, err := eventService.RegisterChaincodeEvent(c.chaincodeID, eventFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cpReg := newCheckpointRegistration(reg, func() { eventService.Unregister(reg) })
+	return cpReg, cpReg.forwardChaincodeEvents(cp, eventch), nil
 }
 
 // Unregister removes the given registration and closes the event channel.
 //  Parameters:
 //  registration is the registration handle that was returned from RegisterContractEvent method
 func (c *Contract) Unregister(registration fab.Registration) {
-	c.network.event.Unregister(registration)
+	switch reg := registration.(type) {
+	case *chaincodeEventRegistration:
+		reg.close()
+	case *checkpointRegistration:
+		reg.close()
+	default:
+		c.network.event.Unregister(registration)
+	}
 }
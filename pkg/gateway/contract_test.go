@@ -120,6 +120,62 @@ func TestEvaluateTransaction(t *testing.T) {
 	}
 }
 
+func TestSubmitTransactionBytes(t *testing.T) {
+	c := mockChannelProvider("mychannel")
+
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: defaultTimeout,
+		},
+	}
+
+	nw, err := newNetwork(gw, c)
+
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	contr := nw.GetContract("contract1")
+
+	result, err := contr.SubmitTransactionBytes("txn1", []byte{0x00, 0xff}, []byte("arg2"))
+
+	if err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	if string(result) != "abc" {
+		t.Fatalf("Incorrect transaction result: %s", result)
+	}
+}
+
+func TestEvaluateTransactionBytes(t *testing.T) {
+	c := mockChannelProvider("mychannel")
+
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: defaultTimeout,
+		},
+	}
+
+	nw, err := newNetwork(gw, c)
+
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	contr := nw.GetContract("contract1")
+
+	result, err := contr.EvaluateTransactionBytes("txn1", []byte{0x00, 0xff}, []byte("arg2"))
+
+	if err != nil {
+		t.Fatalf("Failed to evaluate transaction: %s", err)
+	}
+
+	if string(result) != "abc" {
+		t.Fatalf("Incorrect transaction result: %s", result)
+	}
+}
+
 func TestContractEvent(t *testing.T) {
 	c := mockChannelProvider("mychannel")
 
@@ -0,0 +1,329 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+const encryptedFileExtension string = ".eid"
+
+// extensions of the files used while re-keying a wallet
+const (
+	rekeyFileExtension  = ".rekey"
+	backupFileExtension = ".bak"
+)
+const encryptedEntryVersion = 1
+
+// scrypt parameters used to derive the entry key from the passphrase
+const (
+	scryptN      = 32768
+	scryptR      = 8
+	scryptP      = 1
+	keyLength    = 32
+	saltLength   = 16
+	entryAADBase = "fabric-gateway-wallet"
+)
+
+// encryptedEntry is the on-disk format of an identity in an encrypted wallet
+type encryptedEntry struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// encryptedFileSystemWalletStore stores identity information used to connect to a Hyperledger Fabric network,
+// encrypting each identity with a key derived from a passphrase.
+// Instances are created using NewEncryptedFileSystemWallet()
+type encryptedFileSystemWalletStore struct {
+	path       string
+	passphrase []byte
+}
+
+// NewEncryptedFileSystemWallet creates an instance of a wallet, backed by files on the filesystem.
+// Each identity is sealed with AES-GCM using a key derived from the passphrase (scrypt) with a random
+// salt and nonce for every entry, so private keys are never written to disk in clear text.
+// An identity that has been modified on disk, or moved to a different label, fails to decrypt.
+//  Parameters:
+//  path specifies where on the filesystem to store the wallet.
+//  passphrase is used to derive the keys that protect the identities in the wallet.
+//
+//  Returns:
+//  A Wallet object.
+func NewEncryptedFileSystemWallet(path string, passphrase []byte) (*Wallet, error) {
+	store, err := newEncryptedFileSystemWalletStore(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewWalletWithStore(store), nil
+}
+
+// RekeyEncryptedFileSystemWallet re-encrypts every identity of an encrypted wallet with a new passphrase.
+// All identities are decrypted and written to temporary files before any of them are replaced, so the wallet is
+// left unchanged if the current passphrase is incorrect, an identity has been tampered with or an identity
+// cannot be written. The temporary files then replace the identities one by one, keeping a backup of each
+// identity until all of them are replaced; if replacing an identity fails, the identities already replaced are
+// restored from their backups.
+//  Parameters:
+//  path specifies where on the filesystem the wallet is stored.
+//  passphrase is the current passphrase of the wallet.
+//  newPassphrase is the passphrase used to protect the identities from now on.
+func RekeyEncryptedFileSystemWallet(path string, passphrase []byte, newPassphrase []byte) error {
+	if len(newPassphrase) == 0 {
+		return errors.New("new passphrase must not be empty")
+	}
+
+	current, err := newEncryptedFileSystemWalletStore(path, passphrase)
+	if err != nil {
+		return err
+	}
+
+	labels, err := current.List()
+	if err != nil {
+		return err
+	}
+
+	contents := make(map[string][]byte, len(labels))
+	for _, label := range labels {
+		content, err := current.Get(label)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to re-key wallet")
+		}
+		contents[label] = content
+	}
+
+	rekeyed := &encryptedFileSystemWalletStore{path: current.path, passphrase: newPassphrase}
+
+	// staged files are removed if re-keying fails; once an identity is replaced its staged file no longer exists
+	defer func() {
+		for _, label := range labels {
+			_ = os.Remove(rekeyed.pathname(label) + rekeyFileExtension)
+		}
+	}()
+
+	for _, label := range labels {
+		if err := rekeyed.writeEntry(rekeyed.pathname(label)+rekeyFileExtension, label, contents[label]); err != nil {
+			return errors.WithMessagef(err, "failed to re-key identity [%s]", label)
+		}
+	}
+
+	for i, label := range labels {
+		if err := replaceEntry(rekeyed.pathname(label)); err != nil {
+			restoreEntries(rekeyed, labels[:i])
+			return errors.WithMessagef(err, "failed to re-key identity [%s]", label)
+		}
+	}
+
+	for _, label := range labels {
+		_ = os.Remove(rekeyed.pathname(label) + backupFileExtension) // ignore error; the wallet is re-keyed
+	}
+
+	return nil
+}
+
+// replaceEntry replaces an entry with its staged file, keeping a backup of the entry
+func replaceEntry(pathname string) error {
+	if err := os.Rename(pathname, pathname+backupFileExtension); err != nil {
+		return err
+	}
+
+	if err := os.Rename(pathname+rekeyFileExtension, pathname); err != nil {
+		_ = os.Rename(pathname+backupFileExtension, pathname) // ignore error; Rename error takes precedence
+		return err
+	}
+
+	return nil
+}
+
+// restoreEntries restores the entries of the labels from their backups
+func restoreEntries(efw *encryptedFileSystemWalletStore, labels []string) {
+	for _, label := range labels {
+		pathname := efw.pathname(label)
+		if err := os.Rename(pathname+backupFileExtension, pathname); err != nil {
+			logger.Warnf("failed to restore identity [%s] from backup [%s]: %s", label, pathname+backupFileExtension, err)
+		}
+	}
+}
+
+func newEncryptedFileSystemWalletStore(path string, passphrase []byte) (*encryptedFileSystemWalletStore, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase must not be empty")
+	}
+
+	cleanPath := filepath.Clean(path)
+	if err := os.MkdirAll(cleanPath, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &encryptedFileSystemWalletStore{path: cleanPath, passphrase: passphrase}, nil
+}
+
+// Put an identity into the wallet.
+func (efw *encryptedFileSystemWalletStore) Put(label string, content []byte) error {
+	// write to a temporary file first so that an entry is never left partially written
+	pathname := efw.pathname(label)
+	tmpPathname := pathname + ".tmp"
+	if err := efw.writeEntry(tmpPathname, label, content); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPathname, pathname); err != nil {
+		_ = os.Remove(tmpPathname) // ignore error; Rename error takes precedence
+		return err
+	}
+
+	return nil
+}
+
+// Get an identity from the wallet.
+func (efw *encryptedFileSystemWalletStore) Get(label string) ([]byte, error) {
+	data, err := ioutil.ReadFile(efw.pathname(label))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &encryptedEntry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, errors.Wrapf(err, "invalid wallet entry [%s]", label)
+	}
+
+	return efw.open(label, entry)
+}
+
+// Remove an identity from the wallet. If the identity does not exist, this method does nothing.
+func (efw *encryptedFileSystemWalletStore) Remove(label string) error {
+	_ = os.Remove(efw.pathname(label))
+	return nil
+}
+
+// Exists tests the existence of an identity in the wallet.
+func (efw *encryptedFileSystemWalletStore) Exists(label string) bool {
+	_, err := os.Stat(efw.pathname(label))
+	return err == nil
+}
+
+// List all of the labels in the wallet.
+func (efw *encryptedFileSystemWalletStore) List() ([]string, error) {
+	files, err := ioutil.ReadDir(efw.path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	for _, file := range files {
+		name := file.Name()
+		if filepath.Ext(name) == encryptedFileExtension {
+			labels = append(labels, name[:len(name)-len(encryptedFileExtension)])
+		}
+	}
+
+	return labels, nil
+}
+
+// writeEntry encrypts the identity of the label and writes it to the file
+func (efw *encryptedFileSystemWalletStore) writeEntry(pathname string, label string, content []byte) error {
+	entry, err := efw.seal(label, content)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal wallet entry")
+	}
+
+	if err := ioutil.WriteFile(pathname, data, 0600); err != nil {
+		_ = os.Remove(pathname) // ignore error; WriteFile error takes precedence
+		return err
+	}
+
+	return nil
+}
+
+func (efw *encryptedFileSystemWalletStore) pathname(label string) string {
+	return filepath.Clean(filepath.Join(efw.path, label) + encryptedFileExtension)
+}
+
+func (efw *encryptedFileSystemWalletStore) seal(label string, content []byte) (*encryptedEntry, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, errors.Wrap(err, "failed to generate salt")
+	}
+
+	aead, err := efw.newAEAD(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+
+	return &encryptedEntry{
+		Version:    encryptedEntryVersion,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, content, entryAAD(label)),
+	}, nil
+}
+
+func (efw *encryptedFileSystemWalletStore) open(label string, entry *encryptedEntry) ([]byte, error) {
+	if entry.Version != encryptedEntryVersion {
+		return nil, errors.Errorf("unsupported wallet entry version [%d] for identity [%s]", entry.Version, label)
+	}
+
+	aead, err := efw.newAEAD(entry.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entry.Nonce) != aead.NonceSize() {
+		return nil, errors.Errorf("invalid nonce for identity [%s]", label)
+	}
+
+	content, err := aead.Open(nil, entry.Nonce, entry.Ciphertext, entryAAD(label))
+	if err != nil {
+		return nil, errors.Errorf("failed to decrypt identity [%s]: incorrect passphrase or the identity has been tampered with", label)
+	}
+
+	return content, nil
+}
+
+func (efw *encryptedFileSystemWalletStore) newAEAD(salt []byte) (cipher.AEAD, error) {
+	if len(salt) != saltLength {
+		return nil, errors.New("invalid salt")
+	}
+
+	key, err := scrypt.Key(efw.passphrase, salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to derive key from passphrase")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// entryAAD binds the ciphertext to the label so that an entry cannot be moved to another label unnoticed
+func entryAAD(label string) []byte {
+	return []byte(entryAADBase + "/" + label)
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testPassphrase = []byte("passphrase")
+
+func encryptedWalletTestDir() string {
+	dir := filepath.Join("testdata", "wallet", "encrypted")
+	os.RemoveAll(dir)
+	return dir
+}
+
+func createEncryptedFileSystemWallet() (*Wallet, error) {
+	return NewEncryptedFileSystemWallet(encryptedWalletTestDir(), testPassphrase)
+}
+
+func TestEncryptedFileSystemWalletSuite(t *testing.T) {
+	testWalletSuite(t, createEncryptedFileSystemWallet)
+	os.RemoveAll(filepath.Join("testdata", "wallet", "encrypted"))
+}
+
+func TestEncryptedFileSystemWalletEmptyPassphrase(t *testing.T) {
+	if _, err := NewEncryptedFileSystemWallet(encryptedWalletTestDir(), nil); err == nil {
+		t.Fatal("Expected error for empty passphrase")
+	}
+}
+
+func TestEncryptedFileSystemWalletNoClearText(t *testing.T) {
+	dir := encryptedWalletTestDir()
+	defer os.RemoveAll(dir)
+
+	wallet, err := NewEncryptedFileSystemWallet(dir, testPassphrase)
+	if err != nil {
+		t.Fatalf("Failed to create encrypted wallet: %s", err)
+	}
+
+	if err := wallet.Put("label1", NewX509Identity("msp", "testCert", "testPrivKey")); err != nil {
+		t.Fatalf("Failed to put identity: %s", err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "label1"+encryptedFileExtension))
+	if err != nil {
+		t.Fatalf("Failed to read wallet entry: %s", err)
+	}
+
+	if bytes.Contains(content, []byte("testPrivKey")) || bytes.Contains(content, []byte("testCert")) {
+		t.Fatal("Wallet entry contains clear text identity")
+	}
+}
+
+func TestEncryptedFileSystemWalletWrongPassphrase(t *testing.T) {
+	dir := encryptedWalletTestDir()
+	defer os.RemoveAll(dir)
+
+	wallet, _ := NewEncryptedFileSystemWallet(dir, testPassphrase)
+	wallet.Put("label1", NewX509Identity("msp", "testCert", "testPrivKey"))
+
+	other, _ := NewEncryptedFileSystemWallet(dir, []byte("wrong"))
+	if _, err := other.Get("label1"); err == nil || !strings.Contains(err.Error(), "failed to decrypt") {
+		t.Fatalf("Expected decryption error, Received error: %v", err)
+	}
+}
+
+func TestEncryptedFileSystemWalletTampered(t *testing.T) {
+	dir := encryptedWalletTestDir()
+	defer os.RemoveAll(dir)
+
+	wallet, _ := NewEncryptedFileSystemWallet(dir, testPassphrase)
+	wallet.Put("label1", NewX509Identity("msp", "testCert", "testPrivKey"))
+
+	pathname := filepath.Join(dir, "label1"+encryptedFileExtension)
+	content, _ := ioutil.ReadFile(pathname)
+	entry := &encryptedEntry{}
+	if err := json.Unmarshal(content, entry); err != nil {
+		t.Fatalf("Failed to unmarshal wallet entry: %s", err)
+	}
+	entry.Ciphertext[0] ^= 0xff
+	content, _ = json.Marshal(entry)
+	ioutil.WriteFile(pathname, content, 0600)
+
+	if _, err := wallet.Get("label1"); err == nil || !strings.Contains(err.Error(), "tampered") {
+		t.Fatalf("Expected tamper error, Received error: %v", err)
+	}
+}
+
+func TestEncryptedFileSystemWalletMovedEntry(t *testing.T) {
+	dir := encryptedWalletTestDir()
+	defer os.RemoveAll(dir)
+
+	wallet, _ := NewEncryptedFileSystemWallet(dir, testPassphrase)
+	wallet.Put("label1", NewX509Identity("msp", "testCert", "testPrivKey"))
+
+	err := os.Rename(filepath.Join(dir, "label1"+encryptedFileExtension), filepath.Join(dir, "label2"+encryptedFileExtension))
+	if err != nil {
+		t.Fatalf("Failed to rename wallet entry: %s", err)
+	}
+
+	if _, err := wallet.Get("label2"); err == nil {
+		t.Fatal("Expected error for identity moved to another label")
+	}
+}
+
+func TestRekeyEncryptedFileSystemWallet(t *testing.T) {
+	dir := encryptedWalletTestDir()
+	defer os.RemoveAll(dir)
+
+	wallet, _ := NewEncryptedFileSystemWallet(dir, testPassphrase)
+	wallet.Put("label1", NewX509Identity("msp", "testCert", "testPrivKey"))
+	wallet.Put("label2", NewX509Identity("msp", "testCert", "testPrivKey"))
+
+	newPassphrase := []byte("new passphrase")
+	if err := RekeyEncryptedFileSystemWallet(dir, []byte("wrong"), newPassphrase); err == nil {
+		t.Fatal("Expected error re-keying with incorrect passphrase")
+	}
+
+	if _, err := wallet.Get("label1"); err != nil {
+		t.Fatalf("Failed re-key should leave wallet unchanged: %s", err)
+	}
+
+	if err := RekeyEncryptedFileSystemWallet(dir, testPassphrase, newPassphrase); err != nil {
+		t.Fatalf("Failed to re-key wallet: %s", err)
+	}
+
+	if _, err := wallet.Get("label1"); err == nil {
+		t.Fatal("Old passphrase should no longer decrypt the wallet")
+	}
+
+	rekeyed, _ := NewEncryptedFileSystemWallet(dir, newPassphrase)
+	for _, label := range []string{"label1", "label2"} {
+		id, err := rekeyed.Get(label)
+		if err != nil {
+			t.Fatalf("Failed to get re-keyed identity: %s", err)
+		}
+		if id.(*X509Identity).Credentials.Key != "testPrivKey" {
+			t.Fatalf("Unexpected re-keyed identity: %v", id)
+		}
+	}
+}
+
+func TestRekeyEncryptedFileSystemWalletWriteFailure(t *testing.T) {
+	dir := encryptedWalletTestDir()
+	defer os.RemoveAll(dir)
+
+	wallet, _ := NewEncryptedFileSystemWallet(dir, testPassphrase)
+	wallet.Put("label1", NewX509Identity("msp", "testCert", "testPrivKey"))
+	wallet.Put("label2", NewX509Identity("msp", "testCert", "testPrivKey"))
+
+	// a non-empty directory in place of the staged file of label2 fails the write of its re-keyed identity
+	blocked := filepath.Join(dir, "label2"+encryptedFileExtension+rekeyFileExtension)
+	if err := os.MkdirAll(filepath.Join(blocked, "blocked"), os.ModePerm); err != nil {
+		t.Fatalf("Failed to create directory: %s", err)
+	}
+
+	if err := RekeyEncryptedFileSystemWallet(dir, testPassphrase, []byte("new passphrase")); err == nil {
+		t.Fatal("Expected error re-keying wallet")
+	}
+
+	for _, label := range []string{"label1", "label2"} {
+		if _, err := wallet.Get(label); err != nil {
+			t.Fatalf("Failed re-key should leave wallet unchanged: %s", err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "label1"+encryptedFileExtension+rekeyFileExtension)); !os.IsNotExist(err) {
+		t.Fatal("Expected staged identity to be removed after failed re-key")
+	}
+}
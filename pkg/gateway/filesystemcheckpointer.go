@@ -0,0 +1,115 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// FileSystemCheckpointer records the progress of an event listener in a file, so that event
+// processing can be resumed after the application restarts.
+// Instances are created using NewFileSystemCheckpointer()
+type FileSystemCheckpointer struct {
+	path  string
+	mutex sync.RWMutex
+	state checkpointState
+}
+
+// NewFileSystemCheckpointer creates an instance of a checkpointer, backed by a file.
+// If the file already exists, the checkpointer is initialised from its content.
+//  Parameters:
+//  path specifies the file in which to store the checkpoint.
+//
+//  Returns:
+//  A FileSystemCheckpointer object.
+func NewFileSystemCheckpointer(path string) (*FileSystemCheckpointer, error) {
+	cleanPath := filepath.Clean(path)
+	if err := os.MkdirAll(filepath.Dir(cleanPath), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	c := &FileSystemCheckpointer{path: cleanPath}
+
+	content, err := ioutil.ReadFile(cleanPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, &c.state); err != nil {
+			return nil, errors.Wrapf(err, "invalid checkpoint file: %s", cleanPath)
+		}
+	}
+
+	return c, nil
+}
+
+// BlockNumber returns the number of the next block to be processed.
+func (c *FileSystemCheckpointer) BlockNumber() uint64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.state.BlockNumber
+}
+
+// TransactionIDs returns the IDs of the transactions already processed within the current block.
+func (c *FileSystemCheckpointer) TransactionIDs() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.state.transactionIDs()
+}
+
+// CheckpointBlock records that all the events in the given block have been processed.
+func (c *FileSystemCheckpointer) CheckpointBlock(blockNumber uint64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	state := c.state
+	state.checkpointBlock(blockNumber)
+	return c.save(state)
+}
+
+// CheckpointTransaction records that the events of a transaction in the given block have been processed.
+func (c *FileSystemCheckpointer) CheckpointTransaction(blockNumber uint64, txID string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	state := c.state
+	state.TransactionIDs = state.transactionIDs()
+	state.checkpointTransaction(blockNumber, txID)
+	return c.save(state)
+}
+
+// save writes the checkpoint to a temporary file which then replaces the checkpoint file, so that
+// the checkpoint file is never left partially written. The in-memory state is only updated on success.
+func (c *FileSystemCheckpointer) save(state checkpointState) error {
+	content, err := json.Marshal(&state)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal checkpoint")
+	}
+
+	tmpPath := c.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, content, 0600); err != nil {
+		return errors.Wrap(err, "failed to write checkpoint")
+	}
+
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		_ = os.Remove(tmpPath) // ignore error; Rename error takes precedence
+		return errors.Wrap(err, "failed to write checkpoint")
+	}
+
+	c.state = state
+	return nil
+}
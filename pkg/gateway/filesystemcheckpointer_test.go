@@ -0,0 +1,81 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func checkpointerTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "checkpointer")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	return dir
+}
+
+func TestFileSystemCheckpointerSuite(t *testing.T) {
+	dir := checkpointerTestDir(t)
+	defer os.RemoveAll(dir)
+
+	count := 0
+	testCheckpointerSuite(t, func(t *testing.T) Checkpointer {
+		count++
+		checkpointer, err := NewFileSystemCheckpointer(filepath.Join(dir, fmt.Sprintf("checkpoint%d.json", count)))
+		if err != nil {
+			t.Fatalf("Failed to create FileSystemCheckpointer: %s", err)
+		}
+		return checkpointer
+	})
+}
+
+func TestFileSystemCheckpointerPersistence(t *testing.T) {
+	dir := checkpointerTestDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoint.json")
+	checkpointer, err := NewFileSystemCheckpointer(path)
+	if err != nil {
+		t.Fatalf("Failed to create FileSystemCheckpointer: %s", err)
+	}
+
+	if err := checkpointer.CheckpointTransaction(7, "tx1"); err != nil {
+		t.Fatalf("Failed to checkpoint transaction: %s", err)
+	}
+
+	restored, err := NewFileSystemCheckpointer(path)
+	if err != nil {
+		t.Fatalf("Failed to create FileSystemCheckpointer: %s", err)
+	}
+
+	txIDs := restored.TransactionIDs()
+	if restored.BlockNumber() != 7 || len(txIDs) != 1 || txIDs[0] != "tx1" {
+		t.Fatalf("Checkpoint not restored: %d %v", restored.BlockNumber(), txIDs)
+	}
+
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatal("Temporary checkpoint file should not remain")
+	}
+}
+
+func TestFileSystemCheckpointerInvalidFile(t *testing.T) {
+	dir := checkpointerTestDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoint.json")
+	if err := ioutil.WriteFile(path, []byte("invalid"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %s", err)
+	}
+
+	if _, err := NewFileSystemCheckpointer(path); err == nil {
+		t.Fatal("Expected error for invalid checkpoint file")
+	}
+}
@@ -12,6 +12,11 @@ SPDX-License-Identifier: Apache-2.0
 // as specified in a network configuration file, using an identity stored in a wallet.
 // Interactions with smart contracts are then invoked within the context of this gateway connection.
 //
+// Contract.SubmitTransaction and Transaction.Submit return only the value returned by the transaction
+// function. Applications that also need the ID of the transaction, the number of the block in which it
+// was committed or its validation code should submit it with Contract.NewRequest, whose Submit method
+// returns a SubmitResult.
+//
 // See https://github.com/hyperledger/fabric-samples/blob/master/fabcar/go/fabcar.go
 // for a working sample.
 package gateway
@@ -19,9 +24,11 @@ package gateway
 import (
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	fabricCaUtil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkinternal/pkg/util"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
@@ -37,6 +44,8 @@ const (
 	localhostEnvVarName = "DISCOVERY_AS_LOCALHOST"
 )
 
+var logger = logging.NewLogger("fabsdk/gateway")
+
 // Gateway is the entry point to a Fabric network
 type Gateway struct {
 	sdk        *fabsdk.FabricSDK
@@ -46,6 +55,9 @@ type Gateway struct {
 	mspid      string
 	peers      []fab.PeerConfig
 	mspfactory api.MSPProviderFactory
+	// networks are the networks obtained from the gateway, whose resources are released when the gateway is closed
+	networks []*Network
+	mutex    sync.Mutex
 }
 
 type gatewayOptions struct {
@@ -55,6 +67,13 @@ type gatewayOptions struct {
 	// FromBlock specify the initial block to be considerer by event client
 	FromBlock    uint64
 	FromBlockSet bool
+	// PeerGateway specifies that transactions are to be invoked through the Gateway service of a peer
+	PeerGateway         bool
+	PeerGatewayEndpoint string
+	// CommitStrategy determines the peers from which commit events are awaited on submit
+	CommitStrategy CommitStrategy
+	// QueryStrategy determines the peers on which transactions are evaluated
+	QueryStrategy QueryStrategy
 }
 
 // Option functional arguments can be supplied when connecting to the gateway.
@@ -100,6 +119,16 @@ func Connect(config ConfigOption, identity IdentityOption, options ...Option) (*
 		}
 	}
 
+	if wid, ok := g.options.Identity.(*walletIdentity); ok && wid.hsm {
+		ctx, err := g.sdk.Context()()
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create client context")
+		}
+		if err := wid.resolvePrivateKey(ctx.CryptoSuite()); err != nil {
+			return nil, err
+		}
+	}
+
 	return g, nil
 }
 
@@ -202,12 +231,21 @@ func WithIdentity(wallet wallet, label string) IdentityOption {
 			return err
 		}
 
-		privateKey, _ := fabricCaUtil.ImportBCCSPKeyFromPEMBytes([]byte(creds.(*X509Identity).Key()), cryptosuite.GetDefault(), true)
 		wid := &walletIdentity{
-			id:                    label,
-			mspID:                 creds.mspID(),
-			enrollmentCertificate: []byte(creds.(*X509Identity).Certificate()),
-			privateKey:            privateKey,
+			id:    label,
+			mspID: creds.mspID(),
+		}
+
+		switch id := creds.(type) {
+		case *X509Identity:
+			wid.enrollmentCertificate = []byte(id.Certificate())
+			wid.privateKey, _ = fabricCaUtil.ImportBCCSPKeyFromPEMBytes([]byte(id.Key()), cryptosuite.GetDefault(), true)
+		case *HSMX509Identity:
+			// the private key is resolved once the SDK, and hence the PKCS#11 cryptosuite, has been configured
+			wid.enrollmentCertificate = []byte(id.Certificate())
+			wid.hsm = true
+		default:
+			return errors.Errorf("unsupported identity type: %s", creds.idType())
 		}
 
 		gw.options.Identity = wid
@@ -252,6 +290,22 @@ func WithBlockNum(from uint64) Option {
 	}
 }
 
+// WithPeerGateway is an optional argument to the Connect method which specifies that
+// transactions are to be evaluated, endorsed, submitted and committed through the Gateway service
+// of a Fabric peer (v2.4 and later), rather than by the client using discovery, endorser selection and
+// broadcast to the orderer. Chaincode events are also received from the Gateway service.
+//
+//   Parameters:
+//   endpoint is the name or URL of the peer hosting the Gateway service. If empty, the first peer
+//   of the client organization defined in the config is used.
+func WithPeerGateway(endpoint string) Option {
+	return func(gw *Gateway) error {
+		gw.options.PeerGateway = true
+		gw.options.PeerGatewayEndpoint = endpoint
+		return nil
+	}
+}
+
 // GetNetwork returns an object representing a network channel.
 //  Parameters:
 //  name is the name of the network channel
@@ -265,13 +319,29 @@ func (gw *Gateway) GetNetwork(name string) (*Network, error) {
 	} else {
 		channelProvider = gw.sdk.ChannelContext(name, fabsdk.WithUser(gw.options.User), fabsdk.WithOrg(gw.org))
 	}
-	return newNetwork(gw, channelProvider)
+	network, err := newNetwork(gw, channelProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	gw.mutex.Lock()
+	gw.networks = append(gw.networks, network)
+	gw.mutex.Unlock()
+
+	return network, nil
 }
 
 // Close the gateway connection and all associated resources, including removing listeners attached to networks and
 // contracts created by the gateway.
 func (gw *Gateway) Close() {
-	// future use
+	gw.mutex.Lock()
+	networks := gw.networks
+	gw.networks = nil
+	gw.mutex.Unlock()
+
+	for _, network := range networks {
+		network.close()
+	}
 }
 
 func (gw *Gateway) getOrg() string {
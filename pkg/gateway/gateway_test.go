@@ -90,6 +90,21 @@ func TestConnectWithBlockNum(t *testing.T) {
 	}
 }
 
+func TestConnectWithPeerGateway(t *testing.T) {
+	gw, err := Connect(
+		WithConfig(config.FromFile("testdata/connection-tls.json")),
+		WithUser("user1"),
+		WithPeerGateway("peer0.org1.example.com"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %s", err)
+	}
+
+	if !gw.options.PeerGateway || gw.options.PeerGatewayEndpoint != "peer0.org1.example.com" {
+		t.Fatal("PeerGateway not correctly initialized")
+	}
+}
+
 func TestConnectWithSDK(t *testing.T) {
 	sdk, err := fabsdk.New(config.FromFile("testdata/connection-tls.json"))
 
@@ -0,0 +1,58 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import "encoding/json"
+
+const hsmX509Type = "HSM-X.509"
+
+// HSMX509Identity represents an X509 identity whose private key is held in a hardware security module (HSM).
+// Only the certificate is stored in the wallet. The private key is located in the HSM by the subject key
+// identifier (SKI) of the certificate, using the PKCS#11 cryptosuite configured for the SDK.
+type HSMX509Identity struct {
+	Version     int            `json:"version"`
+	MspID       string         `json:"mspId"`
+	IDType      string         `json:"type"`
+	Credentials hsmCredentials `json:"credentials"`
+}
+
+type hsmCredentials struct {
+	Certificate string `json:"certificate"`
+}
+
+// Type returns HSM-X.509 for this identity type
+func (x *HSMX509Identity) idType() string {
+	return hsmX509Type
+}
+
+func (x *HSMX509Identity) mspID() string {
+	return x.MspID
+}
+
+// Certificate returns the X509 certificate PEM
+func (x *HSMX509Identity) Certificate() string {
+	return x.Credentials.Certificate
+}
+
+// NewHSMX509Identity creates an HSM backed X509 identity for storage in a wallet
+func NewHSMX509Identity(mspid string, cert string) *HSMX509Identity {
+	return &HSMX509Identity{1, mspid, hsmX509Type, hsmCredentials{cert}}
+}
+
+func (x *HSMX509Identity) toJSON() ([]byte, error) {
+	return json.Marshal(x)
+}
+
+func (x *HSMX509Identity) fromJSON(data []byte) (Identity, error) {
+	err := json.Unmarshal(data, x)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	fabricCaUtil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkinternal/pkg/util"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/pkg/errors"
+)
+
+// mockHSMCryptoSuite emulates a PKCS#11 cryptosuite, holding private keys that can only be retrieved by SKI
+type mockHSMCryptoSuite struct {
+	core.CryptoSuite
+	keys []core.Key
+}
+
+func (cs *mockHSMCryptoSuite) GetKey(ski []byte) (core.Key, error) {
+	for _, key := range cs.keys {
+		if bytes.Equal(key.SKI(), ski) {
+			return key, nil
+		}
+	}
+	return nil, errors.New("key not found")
+}
+
+// newHSMTestCredentials generates a private key in the mock HSM and returns the PEM of a matching certificate
+func newHSMTestCredentials(t *testing.T, cs *mockHSMCryptoSuite) string {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "hsmUser"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %s", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %s", err)
+	}
+	key, err := fabricCaUtil.ImportBCCSPKeyFromPEMBytes(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), cs.CryptoSuite, true)
+	if err != nil {
+		t.Fatalf("Failed to import key: %s", err)
+	}
+	cs.keys = append(cs.keys, key)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}))
+}
+
+func TestHSMX509IdentityInWallet(t *testing.T) {
+	wallet := NewInMemoryWallet()
+	if err := wallet.Put("hsmUser", NewHSMX509Identity("msp", "testCert")); err != nil {
+		t.Fatalf("Failed to put identity: %s", err)
+	}
+
+	content, _ := wallet.store.Get("hsmUser")
+	if strings.Contains(string(content), "privateKey") {
+		t.Fatalf("HSM identity should not contain a private key: %s", content)
+	}
+
+	id, err := wallet.Get("hsmUser")
+	if err != nil {
+		t.Fatalf("Failed to get identity: %s", err)
+	}
+
+	hsmID, ok := id.(*HSMX509Identity)
+	if !ok {
+		t.Fatalf("Unexpected identity type: %s", id.idType())
+	}
+	if hsmID.mspID() != "msp" || hsmID.Certificate() != "testCert" || hsmID.idType() != hsmX509Type {
+		t.Fatalf("Unexpected identity: %v", hsmID)
+	}
+}
+
+func TestWithIdentityHSM(t *testing.T) {
+	cs := &mockHSMCryptoSuite{CryptoSuite: cryptosuite.GetDefault()}
+	cert := newHSMTestCredentials(t, cs)
+
+	wallet := NewInMemoryWallet()
+	wallet.Put("hsmUser", NewHSMX509Identity("msp", cert))
+
+	gw := &Gateway{options: &gatewayOptions{}}
+	if err := WithIdentity(wallet, "hsmUser")(gw); err != nil {
+		t.Fatalf("Failed to apply identity option: %s", err)
+	}
+
+	wid := gw.options.Identity.(*walletIdentity)
+	if !wid.hsm || wid.PrivateKey() != nil {
+		t.Fatal("Private key of HSM identity should be resolved from the HSM")
+	}
+
+	if err := wid.resolvePrivateKey(cs); err != nil {
+		t.Fatalf("Failed to resolve private key: %s", err)
+	}
+
+	if wid.PrivateKey() == nil || !wid.PrivateKey().Private() {
+		t.Fatal("Private key not resolved")
+	}
+
+	digest, _ := cs.Hash([]byte("message"), cryptosuite.GetSHA256Opts())
+	if _, err := cs.Sign(wid.PrivateKey(), digest, nil); err != nil {
+		t.Fatalf("Failed to sign with HSM identity: %s", err)
+	}
+}
+
+func TestWithIdentityHSMKeyNotFound(t *testing.T) {
+	cs := &mockHSMCryptoSuite{CryptoSuite: cryptosuite.GetDefault()}
+	cert := newHSMTestCredentials(t, cs)
+	cs.keys = nil
+
+	wallet := NewInMemoryWallet()
+	wallet.Put("hsmUser", NewHSMX509Identity("msp", cert))
+
+	gw := &Gateway{options: &gatewayOptions{}}
+	if err := WithIdentity(wallet, "hsmUser")(gw); err != nil {
+		t.Fatalf("Failed to apply identity option: %s", err)
+	}
+
+	err := gw.options.Identity.(*walletIdentity).resolvePrivateKey(cs)
+	if err == nil || !strings.Contains(err.Error(), "hsmUser") {
+		t.Fatalf("Expected error for missing HSM key, Received error: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import "sync"
+
+// InMemoryCheckpointer records the progress of an event listener in memory.
+// Instances are created using NewInMemoryCheckpointer()
+type InMemoryCheckpointer struct {
+	mutex sync.RWMutex
+	state checkpointState
+}
+
+// NewInMemoryCheckpointer creates an instance of a checkpointer, held in memory.
+// This implementation is not backed by a persistent store, so it only allows event listening to be
+// resumed within the lifetime of the application.
+//
+//  Returns:
+//  An InMemoryCheckpointer object.
+func NewInMemoryCheckpointer() *InMemoryCheckpointer {
+	return &InMemoryCheckpointer{}
+}
+
+// BlockNumber returns the number of the next block to be processed.
+func (c *InMemoryCheckpointer) BlockNumber() uint64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.state.BlockNumber
+}
+
+// TransactionIDs returns the IDs of the transactions already processed within the current block.
+func (c *InMemoryCheckpointer) TransactionIDs() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.state.transactionIDs()
+}
+
+// CheckpointBlock records that all the events in the given block have been processed.
+func (c *InMemoryCheckpointer) CheckpointBlock(blockNumber uint64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.state.checkpointBlock(blockNumber)
+	return nil
+}
+
+// CheckpointTransaction records that the events of a transaction in the given block have been processed.
+func (c *InMemoryCheckpointer) CheckpointTransaction(blockNumber uint64, txID string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.state.checkpointTransaction(blockNumber, txID)
+	return nil
+}
+
+// checkpointState is the checkpoint data shared by the checkpointer implementations
+type checkpointState struct {
+	BlockNumber    uint64   `json:"blockNumber"`
+	TransactionIDs []string `json:"transactionIds"`
+}
+
+func (s *checkpointState) transactionIDs() []string {
+	txIDs := make([]string, len(s.TransactionIDs))
+	copy(txIDs, s.TransactionIDs)
+	return txIDs
+}
+
+func (s *checkpointState) checkpointBlock(blockNumber uint64) {
+	s.BlockNumber = blockNumber + 1
+	s.TransactionIDs = nil
+}
+
+func (s *checkpointState) checkpointTransaction(blockNumber uint64, txID string) {
+	if blockNumber != s.BlockNumber {
+		s.BlockNumber = blockNumber
+		s.TransactionIDs = nil
+	}
+	if !containsString(s.TransactionIDs, txID) {
+		s.TransactionIDs = append(s.TransactionIDs, txID)
+	}
+}
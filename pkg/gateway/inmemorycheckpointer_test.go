@@ -0,0 +1,19 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"testing"
+)
+
+func createInMemoryCheckpointer(t *testing.T) Checkpointer {
+	return NewInMemoryCheckpointer()
+}
+
+func TestInMemoryCheckpointerSuite(t *testing.T) {
+	testCheckpointerSuite(t, createInMemoryCheckpointer)
+}
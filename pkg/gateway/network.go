@@ -9,8 +9,11 @@ package gateway
 import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/client"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
 	"github.com/pkg/errors"
 )
@@ -18,15 +21,20 @@ import (
 // A Network object represents the set of peers in a Fabric network (channel).
 // Applications should get a Network instance from a Gateway using the GetNetwork method.
 type Network struct {
-	name    string
-	gateway *Gateway
-	client  *channel.Client
-	event   *event.Client
+	name             string
+	gateway          *Gateway
+	client           *channel.Client
+	event            *event.Client
+	peerGw           *peerGateway
+	channelProvider  context.ChannelProvider
+	peerEventService *peerEventServices
 }
 
 func newNetwork(gateway *Gateway, channelProvider context.ChannelProvider) (*Network, error) {
 	n := Network{
-		gateway: gateway,
+		gateway:          gateway,
+		channelProvider:  channelProvider,
+		peerEventService: newPeerEventServices(),
 	}
 
 	// Channel client is used to query and execute transactions
@@ -54,6 +62,15 @@ func newNetwork(gateway *Gateway, channelProvider context.ChannelProvider) (*Net
 		return nil, errors.Wrap(err, "Failed to create new event client")
 	}
 
+	if gateway.options.PeerGateway {
+		// the gateway peer takes care of endorsement and ordering, so there is no need to discover the orderers
+		n.peerGw, err = newPeerGateway(ctx, gateway.options.PeerGatewayEndpoint, gateway.org)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create peer gateway client")
+		}
+		return &n, nil
+	}
+
 	// the following is really to kick the discovery service into getting the TLScert
 	// so that subsequent SubmitTransaction can connect to the orderer
 	members, err := ctx.ChannelService().Membership()
@@ -95,22 +112,109 @@ func (n *Network) GetContractWithName(chaincodeID string, name string) *Contract
 }
 
 // RegisterBlockEvent registers for block events. Unregister must be called when the registration is no longer needed.
+//  Parameters:
+//  opts are the options for the registration, e.g. WithCheckpointer to resume from a checkpoint.
+//
 //  Returns:
 //  the registration and a channel that is used to receive events. The channel is closed when Unregister is called.
-func (n *Network) RegisterBlockEvent() (fab.Registration, <-chan *fab.BlockEvent, error) {
-	return n.event.RegisterBlockEvent()
+func (n *Network) RegisterBlockEvent(opts ...EventOption) (fab.Registration, <-chan *fab.BlockEvent, error) {
+	options, err := newEventOptions(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if options.checkpointer == nil {
+		return n.event.RegisterBlockEvent()
+	}
+
+	cp := newCheckpoint(options.checkpointer)
+	eventService, err := n.checkpointEventService(cp, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reg, eventch, err := eventService.RegisterBlockEvent()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cpReg := newCheckpointRegistration(reg, func() { eventService.Unregister(reg) })
+	return cpReg, cpReg.forwardBlockEvents(cp, eventch), nil
 }
 
 // RegisterFilteredBlockEvent registers for filtered block events. Unregister must be called when the registration is no longer needed.
+//  Parameters:
+//  opts are the options for the registration, e.g. WithCheckpointer to resume from a checkpoint.
+//
 //  Returns:
 //  the registration and a channel that is used to receive events. The channel is closed when Unregister is called.
-func (n *Network) RegisterFilteredBlockEvent() (fab.Registration, <-chan *fab.FilteredBlockEvent, error) {
-	return n.event.RegisterFilteredBlockEvent()
+func (n *Network) RegisterFilteredBlockEvent(opts ...EventOption) (fab.Registration, <-chan *fab.FilteredBlockEvent, error) {
+	options, err := newEventOptions(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if options.checkpointer == nil {
+		return n.event.RegisterFilteredBlockEvent()
+	}
+
+	cp := newCheckpoint(options.checkpointer)
+	eventService, err := n.checkpointEventService(cp, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reg, eventch, err := eventService.RegisterFilteredBlockEvent()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cpReg := newCheckpointRegistration(reg, func() { eventService.Unregister(reg) })
+	return cpReg, cpReg.forwardFilteredBlockEvents(cp, eventch), nil
 }
 
 // Unregister removes the given registration and closes the event channel.
 //  Parameters:
 //  registration is the registration handle that was returned from RegisterBlockEvent method
 func (n *Network) Unregister(registration fab.Registration) {
+	if reg, ok := registration.(*checkpointRegistration); ok {
+		reg.close()
+		return
+	}
 	n.event.Unregister(registration)
 }
+
+// checkpointEventService returns an event service which receives events starting from the checkpointed block.
+// Full blocks are requested from the peers only if blockEvents is set, otherwise filtered blocks are received.
+// The event services are cached by the channel service, so registrations that resume from the same block
+// with the same type of events share an event service, which is closed along with the channel service.
+// If there is no checkpoint, the default event client of the network is returned.
+func (n *Network) checkpointEventService(cp *checkpoint, blockEvents bool) (fab.EventService, error) {
+	if cp == nil {
+		return n.event, nil
+	}
+
+	ctx, err := n.channelProvider()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create new channel context")
+	}
+
+	opts := []options.Opt{deliverclient.WithSeekType(seek.FromBlock), deliverclient.WithBlockNum(cp.blockNumber)}
+	if blockEvents {
+		opts = append(opts, client.WithBlockEvents())
+	}
+
+	eventService, err := ctx.ChannelService().EventService(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create new event service")
+	}
+	return eventService, nil
+}
+
+// close releases the resources of the network
+func (n *Network) close() {
+	n.peerEventService.close()
+	if n.peerGw != nil {
+		n.peerGw.close()
+	}
+}
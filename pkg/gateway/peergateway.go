@@ -0,0 +1,490 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	reqContext "context"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	gp "github.com/hyperledger/fabric-protos-go/gateway"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/connectivity"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// peerGateway invokes transactions through the Gateway service of a Fabric peer (v2.4 and later).
+// The peer takes care of endorsement, ordering and commit status so that the client only
+// needs a connection to a single endpoint.
+type peerGateway struct {
+	ctx    context.Channel
+	target *fab.PeerConfig
+	// signer is the identity that signs proposals, transactions and commit status requests
+	signer context.Client
+	// conn is the connection to the gateway peer, which is shared with the peer gateways created by withSigner
+	conn *gatewayConnection
+}
+
+// gatewayConnection holds the connection to a gateway peer, which is opened on first use
+type gatewayConnection struct {
+	mutex  sync.Mutex
+	conn   *comm.GRPCConnection
+	closed bool
+}
+
+func newPeerGateway(ctx context.Channel, endpoint string, org string) (*peerGateway, error) {
+	var target *fab.PeerConfig
+	if endpoint != "" {
+		peerCfg, ok := ctx.EndpointConfig().PeerConfig(endpoint)
+		if !ok {
+			return nil, errors.Errorf("gateway peer [%s] not found in config", endpoint)
+		}
+		target = peerCfg
+	} else {
+		peersCfg, ok := ctx.EndpointConfig().PeersConfig(org)
+		if !ok || len(peersCfg) == 0 {
+			return nil, errors.Errorf("no peers found in config for organization [%s]", org)
+		}
+		target = &peersCfg[0]
+	}
+
+	return &peerGateway{ctx: ctx, target: target, signer: ctx, conn: &gatewayConnection{}}, nil
+}
+
+// withSigner returns a peer gateway that uses the same gateway peer, but signs as the given identity
+func (pg *peerGateway) withSigner(identity msp.SigningIdentity) *peerGateway {
+	return &peerGateway{
+		ctx:    pg.ctx,
+		target: pg.target,
+		signer: &contextImpl.Client{Providers: pg.ctx, SigningIdentity: identity},
+		conn:   pg.conn,
+	}
+}
+
+// connect returns a client of the Gateway service on the gateway peer. The connection to the peer is
+// opened by the first request and reused by the following ones until the peer gateway is closed.
+func (pg *peerGateway) connect(reqCtx reqContext.Context) (gp.GatewayClient, error) {
+	pg.conn.mutex.Lock()
+	defer pg.conn.mutex.Unlock()
+
+	if pg.conn.closed {
+		return nil, errors.New("peer gateway is closed")
+	}
+
+	if pg.conn.conn != nil && pg.conn.conn.ClientConn().GetState() == connectivity.Shutdown {
+		// the connection was shut down underneath us, so a new one is opened
+		pg.conn.conn.Close()
+		pg.conn.conn = nil
+	}
+
+	if pg.conn.conn == nil {
+		opts := comm.OptsFromPeerConfig(pg.target)
+		opts = append(opts, comm.WithConnectTimeout(pg.ctx.EndpointConfig().Timeout(fab.PeerConnection)))
+		opts = append(opts, comm.WithParentContext(reqCtx))
+
+		conn, err := comm.NewConnection(pg.ctx, pg.target.URL, opts...)
+		if err != nil {
+			return nil, err
+		}
+		pg.conn.conn = conn
+	}
+
+	return gp.NewGatewayClient(pg.conn.conn.ClientConn()), nil
+}
+
+// close releases the connection to the gateway peer. Requests that are made after the peer gateway
+// is closed fail.
+func (pg *peerGateway) close() {
+	pg.conn.mutex.Lock()
+	defer pg.conn.mutex.Unlock()
+
+	pg.conn.closed = true
+	if pg.conn.conn != nil {
+		pg.conn.conn.Close()
+		pg.conn.conn = nil
+	}
+}
+
+// evaluate sends the transaction proposal to the gateway peer for evaluation and returns the chaincode result
+func (pg *peerGateway) evaluate(request *channel.Request, targetOrgs []string, timeout time.Duration) ([]byte, error) {
+	proposal, signedProposal, err := pg.newProposal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	reqCtx, cancel := reqContext.WithTimeout(reqContext.Background(), timeout)
+	defer cancel()
+
+	client, err := pg.connect(reqCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.Evaluate(reqCtx, &gp.EvaluateRequest{
+		TransactionId:       string(proposal.TxnID),
+		ChannelId:           pg.ctx.ChannelID(),
+		ProposedTransaction: signedProposal,
+		TargetOrganizations: targetOrgs,
+	})
+	if err != nil {
+		return nil, fromGRPCError(err)
+	}
+
+	return response.GetResult().GetPayload(), nil
+}
+
+// submit endorses the transaction proposal through the gateway peer, signs and submits the prepared
+// transaction for ordering and then waits for the commit status of the transaction. The commit event,
+// if any, is queued on eventch, which is closed when submit returns.
+func (pg *peerGateway) submit(request *channel.Request, endorsingOrgs []string, timeout time.Duration, eventch chan *fab.TxStatusEvent) (*SubmitResult, error) {
+	reqCtx, cancel := reqContext.WithTimeout(reqContext.Background(), timeout)
+	defer cancel()
+
+	var txStatus *fab.TxStatusEvent
+	defer func() {
+		(&commitTxHandler{eventch: eventch}).notify(txStatus)
+	}()
+
+	payload, txID, err := pg.submitAsync(reqCtx, request, endorsingOrgs)
+	if err != nil {
+		return nil, err
+	}
+
+	// the commit event of an invalid transaction is returned along with the error
+	txStatus, err = pg.waitForCommit(reqCtx, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SubmitResult{
+		Payload:          payload,
+		TransactionID:    txID,
+		BlockNumber:      txStatus.BlockNumber,
+		TxValidationCode: txStatus.TxValidationCode,
+	}, nil
+}
+
+// submitAsync endorses the transaction proposal through the gateway peer and signs and submits the prepared
+// transaction for ordering, without waiting for it to be committed. The chaincode result and the ID of the
+// transaction are returned.
+func (pg *peerGateway) submitAsync(reqCtx reqContext.Context, request *channel.Request, endorsingOrgs []string) ([]byte, string, error) {
+	proposal, signedProposal, err := pg.newProposal(request)
+	if err != nil {
+		return nil, "", err
+	}
+	txID := string(proposal.TxnID)
+
+	client, err := pg.connect(reqCtx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	endorseResponse, err := client.Endorse(reqCtx, &gp.EndorseRequest{
+		TransactionId:          txID,
+		ChannelId:              pg.ctx.ChannelID(),
+		ProposedTransaction:    signedProposal,
+		EndorsingOrganizations: endorsingOrgs,
+	})
+	if err != nil {
+		return nil, "", errors.WithMessage(fromGRPCError(err), "endorsement failed")
+	}
+
+	envelope := endorseResponse.GetPreparedTransaction()
+	if envelope == nil {
+		return nil, "", errors.New("gateway peer returned no prepared transaction")
+	}
+
+	payload, err := chaincodeResult(envelope)
+	if err != nil {
+		return nil, "", err
+	}
+
+	envelope.Signature, err = pg.sign(envelope.Payload)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "signing of prepared transaction failed")
+	}
+
+	_, err = client.Submit(reqCtx, &gp.SubmitRequest{
+		TransactionId:       txID,
+		ChannelId:           pg.ctx.ChannelID(),
+		PreparedTransaction: envelope,
+	})
+	if err != nil {
+		return nil, "", errors.WithMessage(fromGRPCError(err), "submit failed")
+	}
+
+	return payload, txID, nil
+}
+
+// waitForCommit obtains the commit status of a submitted transaction from the gateway peer. An error is
+// returned, along with the commit event, if the transaction was not committed as valid.
+func (pg *peerGateway) waitForCommit(reqCtx reqContext.Context, txID string) (*fab.TxStatusEvent, error) {
+	client, err := pg.connect(reqCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	commitStatus, err := pg.commitStatus(reqCtx, client, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	txStatus := &fab.TxStatusEvent{
+		TxID:             txID,
+		TxValidationCode: commitStatus.Result,
+		BlockNumber:      commitStatus.BlockNumber,
+		SourceURL:        pg.target.URL,
+	}
+
+	if commitStatus.Result != peer.TxValidationCode_VALID {
+		return txStatus, status.New(status.EventServerStatus, int32(commitStatus.Result), "received invalid transaction", nil)
+	}
+
+	return txStatus, nil
+}
+
+func (pg *peerGateway) commitStatus(reqCtx reqContext.Context, client gp.GatewayClient, txID string) (*gp.CommitStatusResponse, error) {
+	creator, err := pg.signer.Serialize()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to serialize identity")
+	}
+
+	requestBytes, err := proto.Marshal(&gp.CommitStatusRequest{
+		TransactionId: txID,
+		ChannelId:     pg.ctx.ChannelID(),
+		Identity:      creator,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal of commit status request failed")
+	}
+
+	signature, err := pg.sign(requestBytes)
+	if err != nil {
+		return nil, errors.WithMessage(err, "signing of commit status request failed")
+	}
+
+	response, err := client.CommitStatus(reqCtx, &gp.SignedCommitStatusRequest{Request: requestBytes, Signature: signature})
+	if err != nil {
+		if reqCtx.Err() == reqContext.DeadlineExceeded {
+			return nil, status.New(status.ClientStatus, status.Timeout.ToInt32(), "Submit didn't receive commit status", nil)
+		}
+		return nil, errors.WithMessage(fromGRPCError(err), "commit status request failed")
+	}
+
+	return response, nil
+}
+
+// registerChaincodeEvent opens a chaincode events stream on the gateway peer. Events whose name matches
+// the given filter are delivered on the returned channel until the registration is closed.
+func (pg *peerGateway) registerChaincodeEvent(chaincodeID string, eventFilter string, startBlock *uint64) (*chaincodeEventRegistration, <-chan *fab.CCEvent, error) {
+	regExp, err := regexp.Compile(eventFilter)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid event filter [%s] for chaincode [%s]", eventFilter, chaincodeID)
+	}
+
+	creator, err := pg.signer.Serialize()
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to serialize identity")
+	}
+
+	startPosition := &orderer.SeekPosition{Type: &orderer.SeekPosition_NextCommit{NextCommit: &orderer.SeekNextCommit{}}}
+	if startBlock != nil {
+		startPosition = &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: *startBlock}}}
+	}
+
+	requestBytes, err := proto.Marshal(&gp.ChaincodeEventsRequest{
+		ChannelId:     pg.ctx.ChannelID(),
+		ChaincodeId:   chaincodeID,
+		Identity:      creator,
+		StartPosition: startPosition,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "marshal of chaincode events request failed")
+	}
+
+	signature, err := pg.sign(requestBytes)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "signing of chaincode events request failed")
+	}
+
+	streamCtx, cancel := reqContext.WithCancel(reqContext.Background())
+
+	client, err := pg.connect(streamCtx)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	stream, err := client.ChaincodeEvents(streamCtx, &gp.SignedChaincodeEventsRequest{Request: requestBytes, Signature: signature})
+	if err != nil {
+		cancel()
+		return nil, nil, errors.WithMessage(fromGRPCError(err), "chaincode events request failed")
+	}
+
+	reg := &chaincodeEventRegistration{
+		cancel:  cancel,
+		eventch: make(chan *fab.CCEvent, 100),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(reg.done)
+		defer close(reg.eventch)
+
+		for {
+			response, err := stream.Recv()
+			if err != nil {
+				if streamCtx.Err() == nil {
+					logger.Warnf("chaincode events stream for [%s] closed: %s", chaincodeID, err)
+				}
+				return
+			}
+
+			for _, event := range response.Events {
+				if !regExp.MatchString(event.EventName) {
+					continue
+				}
+
+				ccEvent := &fab.CCEvent{
+					TxID:        event.TxId,
+					ChaincodeID: event.ChaincodeId,
+					EventName:   event.EventName,
+					Payload:     event.Payload,
+					BlockNumber: response.BlockNumber,
+					SourceURL:   pg.target.URL,
+				}
+
+				select {
+				case reg.eventch <- ccEvent:
+				case <-streamCtx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return reg, reg.eventch, nil
+}
+
+func (pg *peerGateway) newProposal(request *channel.Request) (*fab.TransactionProposal, *peer.SignedProposal, error) {
+	txh, err := txn.NewHeader(pg.signer, pg.ctx.ChannelID())
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "create transaction ID failed")
+	}
+
+	proposal, err := txn.CreateChaincodeInvokeProposal(txh, fab.ChaincodeInvokeRequest{
+		ChaincodeID:  request.ChaincodeID,
+		Fcn:          request.Fcn,
+		Args:         request.Args,
+		TransientMap: request.TransientMap,
+		IsInit:       request.IsInit,
+	})
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "creating transaction proposal failed")
+	}
+
+	proposalBytes, err := proto.Marshal(proposal.Proposal)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "marshal of proposal failed")
+	}
+
+	signature, err := pg.sign(proposalBytes)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "signing of proposal failed")
+	}
+
+	return proposal, &peer.SignedProposal{ProposalBytes: proposalBytes, Signature: signature}, nil
+}
+
+func (pg *peerGateway) sign(msg []byte) ([]byte, error) {
+	signingMgr := pg.signer.SigningManager()
+	if signingMgr == nil {
+		return nil, errors.New("signing manager is nil")
+	}
+	return signingMgr.Sign(msg, pg.signer.PrivateKey())
+}
+
+// endorsingOrgs returns the MSP IDs of the given peers, which are specified by name or URL
+func (pg *peerGateway) endorsingOrgs(peers []string) ([]string, error) {
+	var orgs []string
+	for _, p := range peers {
+		peerCfg, ok := pg.ctx.EndpointConfig().PeerConfig(p)
+		if !ok {
+			return nil, errors.Errorf("peer [%s] not found in config", p)
+		}
+
+		mspID := ""
+		for _, networkPeer := range pg.ctx.EndpointConfig().NetworkPeers() {
+			if networkPeer.URL == peerCfg.URL {
+				mspID = networkPeer.MSPID
+				break
+			}
+		}
+		if mspID == "" {
+			return nil, errors.Errorf("unable to determine organization of peer [%s]", p)
+		}
+
+		if !containsString(orgs, mspID) {
+			orgs = append(orgs, mspID)
+		}
+	}
+	return orgs, nil
+}
+
+// chaincodeEventRegistration is the registration handle for chaincode events received from a gateway peer
+type chaincodeEventRegistration struct {
+	cancel  reqContext.CancelFunc
+	eventch chan *fab.CCEvent
+	done    chan struct{}
+	once    sync.Once
+}
+
+func (r *chaincodeEventRegistration) close() {
+	r.once.Do(func() {
+		r.cancel()
+		<-r.done
+	})
+}
+
+// chaincodeResult extracts the chaincode response payload from a prepared transaction envelope
+func chaincodeResult(envelope *common.Envelope) ([]byte, error) {
+	action, err := protoutil.GetActionFromEnvelopeMsg(envelope)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to extract chaincode action from prepared transaction")
+	}
+	return action.GetResponse().GetPayload(), nil
+}
+
+func fromGRPCError(err error) error {
+	if rpcStatus, ok := grpcstatus.FromError(err); ok {
+		return status.NewFromGRPCStatus(rpcStatus)
+	}
+	return err
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
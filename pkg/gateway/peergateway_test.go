@@ -0,0 +1,382 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	gp "github.com/hyperledger/fabric-protos-go/gateway"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/pkg/errors"
+)
+
+const gatewayTestAddress = "127.0.0.1:0"
+
+func TestPeerGatewayEvaluate(t *testing.T) {
+	server := &mocks.MockGatewayServer{Payload: []byte("abc")}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	result, err := contr.EvaluateTransaction("txn1", "arg1", "arg2")
+	if err != nil {
+		t.Fatalf("Failed to evaluate transaction: %s", err)
+	}
+
+	if string(result) != "abc" {
+		t.Fatalf("Incorrect transaction result: %s", result)
+	}
+
+	req := server.LastEvaluateRequest()
+	if req == nil || req.ChannelId != "mychannel" || req.TransactionId == "" {
+		t.Fatalf("Unexpected evaluate request: %v", req)
+	}
+}
+
+func TestPeerGatewayEvaluateError(t *testing.T) {
+	server := &mocks.MockGatewayServer{EvaluateError: errors.New(mockError)}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	_, err := contr.EvaluateTransaction("txn1", "arg1", "arg2")
+	if err == nil || !strings.Contains(err.Error(), mockError) {
+		t.Fatalf("Expected error: %s, Received error: %v", mockError, err)
+	}
+}
+
+func TestPeerGatewaySubmit(t *testing.T) {
+	server := &mocks.MockGatewayServer{Payload: []byte("abc"), BlockNumber: 7}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	txn, err := contr.CreateTransaction("txn1")
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+	notifier := txn.RegisterCommitEvent()
+
+	result, err := txn.Submit("arg1", "arg2")
+	if err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	if string(result) != "abc" {
+		t.Fatalf("Incorrect transaction result: %s", result)
+	}
+
+	endorseReq := server.LastEndorseRequest()
+	submitReq := server.LastSubmitRequest()
+	if endorseReq == nil || submitReq == nil || endorseReq.TransactionId != submitReq.TransactionId {
+		t.Fatal("Endorse and submit requests do not match")
+	}
+
+	select {
+	case cEvent := <-notifier:
+		if cEvent.TxID != submitReq.TransactionId || cEvent.BlockNumber != 7 {
+			t.Fatalf("Unexpected commit event: %#v", cEvent)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Did NOT receive commit event")
+	}
+}
+
+func TestPeerGatewaySubmitCommitError(t *testing.T) {
+	server := &mocks.MockGatewayServer{TxValidationCode: peer.TxValidationCode_MVCC_READ_CONFLICT}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	_, err := contr.SubmitTransaction("txn1", "arg1", "arg2")
+	if err == nil || !strings.Contains(err.Error(), txError) {
+		t.Fatalf("Expected error: %s, Received error: %v", txError, err)
+	}
+}
+
+func TestPeerGatewaySubmitCommitErrorEvent(t *testing.T) {
+	server := &mocks.MockGatewayServer{TxValidationCode: peer.TxValidationCode_MVCC_READ_CONFLICT}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	txn, err := contr.CreateTransaction("txn1")
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+	notifier := txn.RegisterCommitEvent()
+
+	if _, err := txn.Submit("arg1", "arg2"); err == nil {
+		t.Fatal("Expected submit of invalid transaction to fail")
+	}
+
+	select {
+	case cEvent, ok := <-notifier:
+		if !ok || cEvent.TxValidationCode != peer.TxValidationCode_MVCC_READ_CONFLICT {
+			t.Fatalf("Unexpected commit event: %#v", cEvent)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Did NOT receive commit event")
+	}
+
+	if _, ok := <-notifier; ok {
+		t.Fatal("Commit event channel should be closed")
+	}
+}
+
+func TestPeerGatewaySubmitEndorseErrorEvent(t *testing.T) {
+	server := &mocks.MockGatewayServer{EndorseError: errors.New(mockError)}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	txn, err := contr.CreateTransaction("txn1")
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+	notifier := txn.RegisterCommitEvent()
+
+	if _, err := txn.Submit("arg1", "arg2"); err == nil {
+		t.Fatal("Expected submit to fail")
+	}
+
+	select {
+	case cEvent, ok := <-notifier:
+		if ok {
+			t.Fatalf("Unexpected commit event: %#v", cEvent)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Commit event channel was not closed")
+	}
+}
+
+func TestPeerGatewayConnection(t *testing.T) {
+	server := &mocks.MockGatewayServer{Payload: []byte("abc")}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+	peerGw := contr.network.peerGw
+
+	if _, err := contr.EvaluateTransaction("txn1"); err != nil {
+		t.Fatalf("Failed to evaluate transaction: %s", err)
+	}
+	conn := peerGw.conn.conn
+	if conn == nil {
+		t.Fatal("Expected connection to gateway peer to be kept open")
+	}
+
+	if _, err := contr.SubmitTransaction("txn1"); err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+	if peerGw.conn.conn != conn {
+		t.Fatal("Expected connection to gateway peer to be reused")
+	}
+
+	contr.network.close()
+
+	if !conn.Closed() {
+		t.Fatal("Expected connection to gateway peer to be closed")
+	}
+	if _, err := contr.EvaluateTransaction("txn1"); err == nil || !strings.Contains(err.Error(), "peer gateway is closed") {
+		t.Fatalf("Expected error for closed peer gateway, Received error: %v", err)
+	}
+}
+
+func TestPeerGatewaySubmitEndorseError(t *testing.T) {
+	server := &mocks.MockGatewayServer{EndorseError: errors.New(mockError)}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	_, err := contr.SubmitTransaction("txn1", "arg1", "arg2")
+	if err == nil || !strings.Contains(err.Error(), mockError) {
+		t.Fatalf("Expected error: %s, Received error: %v", mockError, err)
+	}
+
+	if server.LastSubmitRequest() != nil {
+		t.Fatal("Transaction should not have been submitted")
+	}
+}
+
+func TestPeerGatewayContractEvent(t *testing.T) {
+	server := &mocks.MockGatewayServer{
+		Events: []*gp.ChaincodeEventsResponse{
+			{
+				BlockNumber: 3,
+				Events: []*peer.ChaincodeEvent{
+					{ChaincodeId: "contract1", TxId: "tx1", EventName: "other", Payload: []byte("skip")},
+					{ChaincodeId: "contract1", TxId: "tx2", EventName: "testEvent", Payload: []byte("payload")},
+				},
+			},
+		},
+	}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	reg, notifier, err := contr.RegisterEvent("test([a-zA-Z]+)")
+	if err != nil {
+		t.Fatalf("Failed to register contract event: %s", err)
+	}
+
+	select {
+	case ccEvent := <-notifier:
+		if ccEvent.TxID != "tx2" || ccEvent.BlockNumber != 3 || string(ccEvent.Payload) != "payload" {
+			t.Fatalf("Unexpected chaincode event: %#v", ccEvent)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Did NOT receive chaincode event")
+	}
+
+	contr.Unregister(reg)
+
+	if _, ok := <-notifier; ok {
+		t.Fatal("Event channel should be closed after Unregister")
+	}
+
+	req := server.LastChaincodeEventsRequest()
+	if req == nil || req.ChaincodeId != "contract1" || req.ChannelId != "mychannel" {
+		t.Fatalf("Unexpected chaincode events request: %v", req)
+	}
+}
+
+func TestPeerGatewayContractEventBadFilter(t *testing.T) {
+	server := &mocks.MockGatewayServer{}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	_, _, err := contr.RegisterEvent("test([a-zA-Z]+")
+	if err == nil {
+		t.Fatal("Expected error for invalid event filter")
+	}
+}
+
+func newPeerGatewayContract(t *testing.T, addr string) *Contract {
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout:             testTimeOut,
+			PeerGateway:         true,
+			PeerGatewayEndpoint: addr,
+		},
+	}
+
+	nw, err := newNetwork(gw, mockPeerGatewayChannelProvider("mychannel", addr))
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	return nw.GetContract("contract1")
+}
+
+func mockPeerGatewayChannelProvider(channelID string, addr string) context.ChannelProvider {
+	channelProvider := func() (context.Channel, error) {
+		ch, err := mocks.NewMockChannel(channelID)
+		if err != nil {
+			return nil, err
+		}
+		ch.EndpointConfig().(*mocks.MockConfig).SetCustomPeerCfg(&fab.PeerConfig{
+			URL:         addr,
+			GRPCOptions: map[string]interface{}{"allow-insecure": true},
+		})
+		return &peerGatewayChannel{Channel: ch}, nil
+	}
+
+	return channelProvider
+}
+
+// peerGatewayChannel overrides the infra provider of the mock channel so that real gRPC connections are established
+type peerGatewayChannel struct {
+	*mocks.Channel
+}
+
+func (c *peerGatewayChannel) InfraProvider() fab.InfraProvider {
+	return comm.NewMockInfraProvider()
+}
+
+func TestPeerGatewayContractEventWithCheckpointer(t *testing.T) {
+	server := &mocks.MockGatewayServer{
+		Events: []*gp.ChaincodeEventsResponse{
+			{
+				BlockNumber: 3,
+				Events: []*peer.ChaincodeEvent{
+					{ChaincodeId: "contract1", TxId: "tx1", EventName: "testEvent"},
+					{ChaincodeId: "contract1", TxId: "tx2", EventName: "testEvent"},
+				},
+			},
+		},
+	}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	checkpointer := NewInMemoryCheckpointer()
+	checkpointer.CheckpointTransaction(3, "tx1")
+
+	reg, notifier, err := contr.RegisterEvent("test([a-zA-Z]+)", WithCheckpointer(checkpointer))
+	if err != nil {
+		t.Fatalf("Failed to register contract event: %s", err)
+	}
+	defer contr.Unregister(reg)
+
+	select {
+	case ccEvent := <-notifier:
+		if ccEvent.TxID != "tx2" {
+			t.Fatalf("Received already processed event: %s", ccEvent.TxID)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Did NOT receive chaincode event")
+	}
+
+	req := server.LastChaincodeEventsRequest()
+	if req.GetStartPosition().GetSpecified().GetNumber() != 3 {
+		t.Fatalf("Events not requested from checkpoint: %v", req.GetStartPosition())
+	}
+}
+
+func TestPeerGatewayOrganizations(t *testing.T) {
+	server := &mocks.MockGatewayServer{Payload: []byte("abc")}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	if _, err := contr.NewRequest("txn1").WithEndorsingOrganizations("Org1MSP", "Org2MSP").Submit(); err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	orgs := server.LastEndorseRequest().EndorsingOrganizations
+	if len(orgs) != 2 || orgs[0] != "Org1MSP" || orgs[1] != "Org2MSP" {
+		t.Fatalf("Unexpected endorsing organizations: %v", orgs)
+	}
+
+	if _, err := contr.NewRequest("txn1").WithEvaluatingOrganizations("Org2MSP").Evaluate(); err != nil {
+		t.Fatalf("Failed to evaluate transaction: %s", err)
+	}
+
+	orgs = server.LastEvaluateRequest().TargetOrganizations
+	if len(orgs) != 1 || orgs[0] != "Org2MSP" {
+		t.Fatalf("Unexpected target organizations: %v", orgs)
+	}
+}
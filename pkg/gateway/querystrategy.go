@@ -0,0 +1,226 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+)
+
+// QueryFunc evaluates a transaction on a single peer and returns the result of the transaction function.
+type QueryFunc = func(peer fab.Peer) ([]byte, error)
+
+// QueryStrategy determines the peers on which a transaction is evaluated, and how failures of those peers are handled.
+// To create a custom strategy, implement this interface and pass an instance to the WithQueryStrategy or
+// WithTransactionQueryStrategy options.
+type QueryStrategy interface {
+	// Evaluate evaluates a transaction and returns its result.
+	//  peers are the peers of the organization of the client identity
+	//  query sends the transaction proposal to a single peer and returns the result
+	Evaluate(peers []fab.Peer, query QueryFunc) ([]byte, error)
+}
+
+// WithQueryStrategy is an optional argument to the Connect method which selects the strategy used by
+// Evaluate to choose the peers on which transactions are evaluated. If this option is not specified, the
+// peers are chosen by the selection service of the SDK.
+// Query strategies are not applicable when transactions are evaluated using WithPeerGateway, in which
+// case the gateway peer chooses the peers.
+//
+//   Parameters:
+//   strategy is one of the predefined strategies, e.g. NewSinglePeerQueryStrategy(), or a custom strategy
+//
+//   Returns:
+//   An Option which can be passed as the third parameter to the Connect() function
+func WithQueryStrategy(strategy QueryStrategy) Option {
+	return func(gw *Gateway) error {
+		if strategy == nil {
+			return errors.New("query strategy must not be nil")
+		}
+		gw.options.QueryStrategy = strategy
+		return nil
+	}
+}
+
+// WithTransactionQueryStrategy is an optional argument to the CreateTransaction method which
+// overrides the query strategy of the gateway for the evaluation of this transaction.
+func WithTransactionQueryStrategy(strategy QueryStrategy) TransactionOption {
+	return func(txn *Transaction) error {
+		if strategy == nil {
+			return errors.New("query strategy must not be nil")
+		}
+		txn.queryStrategy = strategy
+		return nil
+	}
+}
+
+// NewSinglePeerQueryStrategy creates a strategy which evaluates all transactions on the same peer of the
+// client organization. If that peer fails, the transaction is evaluated on the next peer of the organization,
+// which is then used for subsequent transactions.
+func NewSinglePeerQueryStrategy() QueryStrategy {
+	return &singlePeerQueryStrategy{}
+}
+
+// NewRoundRobinQueryStrategy creates a strategy which distributes transactions across the peers of the
+// client organization in turn. If a peer fails, the transaction is evaluated on the next peer.
+func NewRoundRobinQueryStrategy() QueryStrategy {
+	return &roundRobinQueryStrategy{}
+}
+
+// NewFirstNMatchingQueryStrategy creates a strategy which evaluates transactions on all the peers of the
+// client organization concurrently, and returns as soon as n of the peers have returned the same result.
+//  Parameters:
+//  n is the number of matching results required
+func NewFirstNMatchingQueryStrategy(n int) QueryStrategy {
+	return &firstNMatchingQueryStrategy{n: n}
+}
+
+type singlePeerQueryStrategy struct {
+	mutex   sync.Mutex
+	current string
+}
+
+func (s *singlePeerQueryStrategy) Evaluate(peers []fab.Peer, query QueryFunc) ([]byte, error) {
+	s.mutex.Lock()
+	start := 0
+	for i, p := range peers {
+		if p.URL() == s.current {
+			start = i
+			break
+		}
+	}
+	s.mutex.Unlock()
+
+	p, result, err := evaluateWithFailover(peers, start, query)
+	if p != nil {
+		s.mutex.Lock()
+		s.current = p.URL()
+		s.mutex.Unlock()
+	}
+	return result, err
+}
+
+type roundRobinQueryStrategy struct {
+	mutex sync.Mutex
+	next  int
+}
+
+func (s *roundRobinQueryStrategy) Evaluate(peers []fab.Peer, query QueryFunc) ([]byte, error) {
+	s.mutex.Lock()
+	start := s.next
+	s.next++
+	s.mutex.Unlock()
+
+	if len(peers) > 0 {
+		start %= len(peers)
+	}
+
+	_, result, err := evaluateWithFailover(peers, start, query)
+	return result, err
+}
+
+type firstNMatchingQueryStrategy struct {
+	n int
+}
+
+func (s *firstNMatchingQueryStrategy) Evaluate(peers []fab.Peer, query QueryFunc) ([]byte, error) {
+	if s.n < 1 {
+		return nil, errors.Errorf("invalid number of matching results: %d", s.n)
+	}
+	if len(peers) < s.n {
+		return nil, errors.Errorf("%d matching results required but only %d peers are available", s.n, len(peers))
+	}
+
+	type queryResult struct {
+		result []byte
+		err    error
+	}
+
+	results := make(chan queryResult, len(peers))
+	for _, p := range peers {
+		go func(p fab.Peer) {
+			result, err := query(p)
+			results <- queryResult{result: result, err: err}
+		}(p)
+	}
+
+	var errs multi.Errors
+	var received []queryResult
+	for range peers {
+		r := <-results
+		if r.err != nil {
+			errs = append(errs, r.err)
+			continue
+		}
+
+		matching := 1
+		for _, other := range received {
+			if bytes.Equal(other.result, r.result) {
+				matching++
+			}
+		}
+		if matching >= s.n {
+			return r.result, nil
+		}
+		received = append(received, r)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.WithMessagef(errs, "%d matching results not received", s.n)
+	}
+	return nil, errors.Errorf("%d matching results not received", s.n)
+}
+
+// evaluateWithFailover evaluates the transaction on each peer in turn, starting from the given index, until a
+// peer returns a result. Chaincode errors are returned immediately since other peers would return the same error.
+// The peer that returned the result is also returned.
+func evaluateWithFailover(peers []fab.Peer, start int, query QueryFunc) (fab.Peer, []byte, error) {
+	if len(peers) == 0 {
+		return nil, nil, errors.New("no peers available to evaluate the transaction")
+	}
+
+	var errs multi.Errors
+	for i := 0; i < len(peers); i++ {
+		p := peers[(start+i)%len(peers)]
+
+		result, err := query(p)
+		if err == nil {
+			return p, result, nil
+		}
+
+		if isChaincodeError(err) {
+			return p, nil, err
+		}
+
+		logger.Warnf("failed to evaluate transaction on peer [%s], trying next peer: %s", p.URL(), err)
+		errs = append(errs, err)
+	}
+
+	return nil, nil, errs
+}
+
+// isChaincodeError returns true if the error was returned by the chaincode rather than caused by a peer failure
+func isChaincodeError(err error) bool {
+	if s, ok := errors.Cause(err).(*status.Status); ok {
+		return s.Group == status.ChaincodeStatus
+	}
+
+	if errs, ok := errors.Cause(err).(multi.Errors); ok {
+		for _, e := range errs {
+			if !isChaincodeError(e) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	}
+
+	return false
+}
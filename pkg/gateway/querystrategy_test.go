@@ -0,0 +1,268 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/pkg/errors"
+)
+
+func newQueryTestPeers(names ...string) []fab.Peer {
+	var peers []fab.Peer
+	for _, name := range names {
+		peers = append(peers, mocks.NewMockPeer(name, name+".org1.com"))
+	}
+	return peers
+}
+
+// recordingQuery returns a QueryFunc which records the peers queried and fails for the given peers
+func recordingQuery(failing ...string) (QueryFunc, *[]string) {
+	var mutex sync.Mutex
+	var queried []string
+	return func(p fab.Peer) ([]byte, error) {
+		mutex.Lock()
+		queried = append(queried, p.URL())
+		mutex.Unlock()
+		if containsString(failing, p.URL()) {
+			return nil, errors.New(mockError)
+		}
+		return []byte(p.URL()), nil
+	}, &queried
+}
+
+func TestSinglePeerQueryStrategy(t *testing.T) {
+	peers := newQueryTestPeers("peer1", "peer2", "peer3")
+	strategy := NewSinglePeerQueryStrategy()
+
+	query, queried := recordingQuery()
+	for i := 0; i < 2; i++ {
+		result, err := strategy.Evaluate(peers, query)
+		if err != nil || string(result) != "peer1.org1.com" {
+			t.Fatalf("Unexpected result: %s %v", result, err)
+		}
+	}
+	if len(*queried) != 2 {
+		t.Fatalf("Unexpected peers queried: %v", *queried)
+	}
+
+	query, queried = recordingQuery("peer1.org1.com")
+	result, err := strategy.Evaluate(peers, query)
+	if err != nil || string(result) != "peer2.org1.com" {
+		t.Fatalf("Expected failover to peer2, got: %s %v", result, err)
+	}
+
+	// the peer that succeeded is used for subsequent transactions
+	query, queried = recordingQuery()
+	strategy.Evaluate(peers, query)
+	if len(*queried) != 1 || (*queried)[0] != "peer2.org1.com" {
+		t.Fatalf("Unexpected peers queried: %v", *queried)
+	}
+}
+
+func TestSinglePeerQueryStrategyAllFail(t *testing.T) {
+	peers := newQueryTestPeers("peer1", "peer2")
+	query, queried := recordingQuery("peer1.org1.com", "peer2.org1.com")
+
+	_, err := NewSinglePeerQueryStrategy().Evaluate(peers, query)
+	if err == nil || !strings.Contains(err.Error(), mockError) {
+		t.Fatalf("Expected error: %s, Received error: %v", mockError, err)
+	}
+	if len(*queried) != 2 {
+		t.Fatalf("Unexpected peers queried: %v", *queried)
+	}
+}
+
+func TestQueryStrategyNoPeers(t *testing.T) {
+	query, _ := recordingQuery()
+	if _, err := NewSinglePeerQueryStrategy().Evaluate(nil, query); err == nil {
+		t.Fatal("Expected error with no peers")
+	}
+}
+
+func TestQueryStrategyChaincodeError(t *testing.T) {
+	peers := newQueryTestPeers("peer1", "peer2")
+	calls := 0
+	query := func(p fab.Peer) ([]byte, error) {
+		calls++
+		return nil, errors.WithMessage(status.New(status.ChaincodeStatus, 500, "chaincode failure", nil), "query failed")
+	}
+
+	_, err := NewRoundRobinQueryStrategy().Evaluate(peers, query)
+	if err == nil || !strings.Contains(err.Error(), "chaincode failure") {
+		t.Fatalf("Expected chaincode error, Received error: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("Chaincode errors should not fail over, called %d times", calls)
+	}
+}
+
+func TestRoundRobinQueryStrategy(t *testing.T) {
+	peers := newQueryTestPeers("peer1", "peer2", "peer3")
+	strategy := NewRoundRobinQueryStrategy()
+
+	var results []string
+	for i := 0; i < 4; i++ {
+		query, _ := recordingQuery()
+		result, err := strategy.Evaluate(peers, query)
+		if err != nil {
+			t.Fatalf("Failed to evaluate: %s", err)
+		}
+		results = append(results, string(result))
+	}
+
+	expected := []string{"peer1.org1.com", "peer2.org1.com", "peer3.org1.com", "peer1.org1.com"}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Fatalf("Unexpected round robin order: %v", results)
+		}
+	}
+
+	query, _ := recordingQuery("peer2.org1.com")
+	result, err := strategy.Evaluate(peers, query)
+	if err != nil || string(result) != "peer3.org1.com" {
+		t.Fatalf("Expected failover to peer3, got: %s %v", result, err)
+	}
+}
+
+func TestFirstNMatchingQueryStrategy(t *testing.T) {
+	peers := newQueryTestPeers("peer1", "peer2", "peer3")
+	query := func(p fab.Peer) ([]byte, error) {
+		if p.URL() == "peer3.org1.com" {
+			return []byte("stale"), nil
+		}
+		return []byte("value"), nil
+	}
+
+	result, err := NewFirstNMatchingQueryStrategy(2).Evaluate(peers, query)
+	if err != nil || string(result) != "value" {
+		t.Fatalf("Unexpected result: %s %v", result, err)
+	}
+
+	if _, err := NewFirstNMatchingQueryStrategy(3).Evaluate(peers, query); err == nil {
+		t.Fatal("Expected error when results do not match")
+	}
+
+	if _, err := NewFirstNMatchingQueryStrategy(4).Evaluate(peers, query); err == nil {
+		t.Fatal("Expected error with insufficient peers")
+	}
+
+	if _, err := NewFirstNMatchingQueryStrategy(0).Evaluate(peers, query); err == nil {
+		t.Fatal("Expected error for invalid number of matching results")
+	}
+}
+
+func TestEvaluateWithQueryStrategy(t *testing.T) {
+	peer1 := mocks.NewMockPeer("peer1", "peer1.org1.com")
+	peer2 := mocks.NewMockPeer("peer2", "peer2.org1.com")
+	peer3 := mocks.NewMockPeer("peer3", "peer1.org2.com")
+	peer3.MockMSP = "Org2MSP"
+
+	strategy := &recordingQueryStrategy{}
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout:       testTimeOut,
+			QueryStrategy: strategy,
+		},
+	}
+
+	nw, err := newNetwork(gw, mockDiscoveryChannelProvider("mychannel", peer1, peer2, peer3))
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	result, err := nw.GetContract("contract1").EvaluateTransaction("txn1", "arg1")
+	if err != nil {
+		t.Fatalf("Failed to evaluate transaction: %s", err)
+	}
+
+	if string(result) != "abc" {
+		t.Fatalf("Incorrect transaction result: %s", result)
+	}
+
+	if len(strategy.peers) != 2 || strategy.peers[0] != "peer1.org1.com" || strategy.peers[1] != "peer2.org1.com" {
+		t.Fatalf("Query strategy should be given the peers of the client organization: %v", strategy.peers)
+	}
+
+	// explicitly specified endorsing peers take precedence over the query strategy
+	strategy.peers = nil
+	txn, _ := nw.GetContract("contract1").CreateTransaction("txn1", WithEndorsingPeers("peer1.org1.com"))
+	if _, err := txn.Evaluate("arg1"); err != nil {
+		t.Fatalf("Failed to evaluate transaction: %s", err)
+	}
+	if strategy.peers != nil {
+		t.Fatal("Query strategy should not be used when endorsing peers are specified")
+	}
+}
+
+func TestEvaluateWithTransactionQueryStrategy(t *testing.T) {
+	gatewayStrategy := &recordingQueryStrategy{}
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout:       testTimeOut,
+			QueryStrategy: gatewayStrategy,
+		},
+	}
+
+	nw, err := newNetwork(gw, mockDiscoveryChannelProvider("mychannel", mocks.NewMockPeer("peer1", "peer1.org1.com")))
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	txnStrategy := &recordingQueryStrategy{err: errors.New(mockError)}
+	txn, err := nw.GetContract("contract1").CreateTransaction("txn1", WithTransactionQueryStrategy(txnStrategy))
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+
+	if _, err := txn.Evaluate("arg1"); err == nil || !strings.Contains(err.Error(), mockError) {
+		t.Fatalf("Expected error: %s, Received error: %v", mockError, err)
+	}
+
+	if txnStrategy.peers == nil || gatewayStrategy.peers != nil {
+		t.Fatal("Transaction query strategy should override the gateway query strategy")
+	}
+
+	if _, err := nw.GetContract("contract1").CreateTransaction("txn1", WithTransactionQueryStrategy(nil)); err == nil {
+		t.Fatal("Expected error for nil query strategy")
+	}
+}
+
+// recordingQueryStrategy records the peers it is given and evaluates on the first one
+type recordingQueryStrategy struct {
+	peers []string
+	err   error
+}
+
+func (s *recordingQueryStrategy) Evaluate(peers []fab.Peer, query QueryFunc) ([]byte, error) {
+	for _, p := range peers {
+		s.peers = append(s.peers, p.URL())
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return query(peers[0])
+}
+
+func mockDiscoveryChannelProvider(channelID string, peers ...fab.Peer) context.ChannelProvider {
+	channelProvider := func() (context.Channel, error) {
+		ch, err := mocks.NewMockChannel(channelID)
+		if err != nil {
+			return nil, err
+		}
+		ch.ChannelService().(*mocks.MockChannelService).SetDiscovery(mocks.NewMockDiscoveryService(nil, peers...))
+		return ch, nil
+	}
+
+	return channelProvider
+}
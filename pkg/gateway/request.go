@@ -0,0 +1,115 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+// A TransactionRequest carries the arguments, transient data, endorsing peers and collections
+// of an invocation of a transaction function. Applications should obtain instances using the
+// Contract.NewRequest method, e.g.
+//   result, err := contract.NewRequest("createAsset").
+//       WithBytesArgs(assetBytes).
+//       WithTransient(transient).
+//       Submit()
+type TransactionRequest struct {
+	contract *Contract
+	name     string
+	args     [][]byte
+	options  []TransactionOption
+}
+
+func newTransactionRequest(contract *Contract, name string) *TransactionRequest {
+	return &TransactionRequest{contract: contract, name: name}
+}
+
+// WithArgs appends string arguments to be sent to the transaction function
+func (r *TransactionRequest) WithArgs(args ...string) *TransactionRequest {
+	r.args = append(r.args, stringsToBytes(args)...)
+	return r
+}
+
+// WithBytesArgs appends arguments to be sent to the transaction function as is
+func (r *TransactionRequest) WithBytesArgs(args ...[]byte) *TransactionRequest {
+	r.args = append(r.args, args...)
+	return r
+}
+
+// WithTransient sets the transient data that will be passed to the transaction function
+// but will not be stored on the ledger
+func (r *TransactionRequest) WithTransient(data map[string][]byte) *TransactionRequest {
+	r.options = append(r.options, WithTransient(data))
+	return r
+}
+
+// WithEndorsingPeers sets the peers that should be used for endorsement
+func (r *TransactionRequest) WithEndorsingPeers(peers ...string) *TransactionRequest {
+	r.options = append(r.options, WithEndorsingPeers(peers...))
+	return r
+}
+
+// WithEndorsingOrganizations restricts endorsement to the peers of the given organizations
+func (r *TransactionRequest) WithEndorsingOrganizations(mspIDs ...string) *TransactionRequest {
+	r.options = append(r.options, WithEndorsingOrganizations(mspIDs...))
+	return r
+}
+
+// WithEvaluatingOrganizations restricts evaluation to the peers of the given organizations
+func (r *TransactionRequest) WithEvaluatingOrganizations(mspIDs ...string) *TransactionRequest {
+	r.options = append(r.options, WithEvaluatingOrganizations(mspIDs...))
+	return r
+}
+
+// WithCollections sets the private data collections that are accessed by the transaction function
+func (r *TransactionRequest) WithCollections(collections ...string) *TransactionRequest {
+	r.options = append(r.options, WithCollections(collections...))
+	return r
+}
+
+// WithInit makes the transaction fulfill the --init-required condition of the chaincode
+func (r *TransactionRequest) WithInit() *TransactionRequest {
+	r.options = append(r.options, WithInit())
+	return r
+}
+
+// WithOptions adds transaction options to the request
+func (r *TransactionRequest) WithOptions(opts ...TransactionOption) *TransactionRequest {
+	r.options = append(r.options, opts...)
+	return r
+}
+
+// Evaluate the transaction function and return its results. The transaction will not be
+// committed to the ledger.
+func (r *TransactionRequest) Evaluate() ([]byte, error) {
+	txn, err := r.contract.CreateTransaction(r.name, r.options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return txn.evaluate(r.args)
+}
+
+// Submit the transaction to the ledger and return the outcome of the transaction,
+// including the return value of the transaction function and the block in which the
+// transaction was committed.
+func (r *TransactionRequest) Submit() (*SubmitResult, error) {
+	txn, err := r.contract.CreateTransaction(r.name, r.options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return txn.submit(r.args)
+}
+
+// SubmitAsync sends the transaction to the orderer and returns as soon as it has been accepted,
+// without waiting for it to be committed. The returned Commit is used to obtain the outcome
+// of the transaction.
+func (r *TransactionRequest) SubmitAsync() (*Commit, error) {
+	txn, err := r.contract.CreateTransaction(r.name, r.options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return txn.submitAsync(r.args)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"bytes"
+	reqContext "context"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestRequestArgs(t *testing.T) {
+	c := mockChannelProvider("mychannel")
+
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: defaultTimeout,
+		},
+	}
+
+	nw, err := newNetwork(gw, c)
+
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	contr := nw.GetContract("contract1")
+
+	req := contr.NewRequest("txn1").
+		WithArgs("arg1").
+		WithBytesArgs([]byte{0x00, 0xff}).
+		WithTransient(map[string][]byte{"price": []byte("8500")}).
+		WithEndorsingPeers("peer1").
+		WithCollections("_implicit_org_org1").
+		WithInit()
+
+	if len(req.args) != 2 || string(req.args[0]) != "arg1" || !bytes.Equal(req.args[1], []byte{0x00, 0xff}) {
+		t.Fatalf("Incorrect request args: %v", req.args)
+	}
+
+	txn, err := contr.CreateTransaction(req.name, req.options...)
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+
+	if string(txn.request.TransientMap["price"]) != "8500" {
+		t.Fatalf("Incorrect transient data: %s", string(txn.request.TransientMap["price"]))
+	}
+
+	if txn.endorsingPeers[0] != "peer1" {
+		t.Fatalf("Incorrect endorsing peer: %s", txn.endorsingPeers[0])
+	}
+
+	if txn.collections[0] != "_implicit_org_org1" {
+		t.Fatalf("Incorrect collection: %s", txn.collections[0])
+	}
+
+	if !txn.isInit {
+		t.Fatal("Init not set")
+	}
+}
+
+func TestRequestEvaluate(t *testing.T) {
+	c := mockChannelProvider("mychannel")
+
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: defaultTimeout,
+		},
+	}
+
+	nw, err := newNetwork(gw, c)
+
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	contr := nw.GetContract("contract1")
+
+	result, err := contr.NewRequest("txn1").WithBytesArgs([]byte("arg1")).Evaluate()
+
+	if err != nil {
+		t.Fatalf("Failed to evaluate transaction: %s", err)
+	}
+
+	if string(result) != "abc" {
+		t.Fatalf("Incorrect transaction result: %s", result)
+	}
+}
+
+func TestRequestSubmit(t *testing.T) {
+	c := mockChannelProvider("mychannel")
+
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: defaultTimeout,
+		},
+	}
+
+	nw, err := newNetwork(gw, c)
+
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	contr := nw.GetContract("contract1")
+
+	result, err := contr.NewRequest("txn1").WithBytesArgs([]byte("arg1")).Submit()
+
+	if err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	if string(result.Payload) != "abc" {
+		t.Fatalf("Incorrect transaction result: %s", result.Payload)
+	}
+
+	if result.TxValidationCode != peer.TxValidationCode_VALID {
+		t.Fatalf("Incorrect validation code: %s", result.TxValidationCode)
+	}
+}
+
+func TestRequestSubmitWithPeerGateway(t *testing.T) {
+	server := &mocks.MockGatewayServer{Payload: []byte("abc"), BlockNumber: 7}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	result, err := contr.NewRequest("txn1").WithBytesArgs([]byte{0x00, 0xff}).Submit()
+
+	if err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	if string(result.Payload) != "abc" || result.BlockNumber != 7 || result.TransactionID != server.LastSubmitRequest().TransactionId {
+		t.Fatalf("Unexpected submit result: %#v", result)
+	}
+}
+
+func TestRequestSubmitAsyncWithPeerGateway(t *testing.T) {
+	server := &mocks.MockGatewayServer{Payload: []byte("abc"), BlockNumber: 7}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	commit, err := contr.NewRequest("txn1").WithArgs("arg1").SubmitAsync()
+	if err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	result, err := commit.Status(reqContext.Background())
+	if err != nil {
+		t.Fatalf("Failed to get commit status: %s", err)
+	}
+
+	if string(result.Payload) != "abc" || result.BlockNumber != 7 {
+		t.Fatalf("Unexpected commit status: %#v", result)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+)
+
+func TestSigningIdentityOption(t *testing.T) {
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: testTimeOut,
+		},
+	}
+
+	nw, err := newNetwork(gw, mockChannelProvider("mychannel"))
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	if _, err := nw.GetContract("contract1").CreateTransaction("txn1", WithSigningIdentity(nil)); err == nil {
+		t.Fatal("Expected error for nil signing identity")
+	}
+
+	user := mspmocks.NewMockSigningIdentity("user1", "Org2MSP")
+	txn, err := nw.GetContract("contract1").CreateTransaction("txn1", WithSigningIdentity(user))
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+
+	if txn.identity != user {
+		t.Fatal("Signing identity not set")
+	}
+
+	result, err := txn.Submit("arg1")
+	if err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	if string(result) != "abc" {
+		t.Fatalf("Incorrect transaction result: %s", result)
+	}
+}
+
+func TestEvaluateWithSigningIdentityQueryStrategy(t *testing.T) {
+	peer1 := mocks.NewMockPeer("peer1", "peer1.org1.com")
+	peer2 := mocks.NewMockPeer("peer2", "peer1.org2.com")
+	peer2.MockMSP = "Org2MSP"
+
+	strategy := &recordingQueryStrategy{}
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout:       testTimeOut,
+			QueryStrategy: strategy,
+		},
+	}
+
+	nw, err := newNetwork(gw, mockDiscoveryChannelProvider("mychannel", peer1, peer2))
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	user := mspmocks.NewMockSigningIdentity("user1", "Org2MSP")
+	txn, err := nw.GetContract("contract1").CreateTransaction("txn1", WithSigningIdentity(user))
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+
+	if _, err := txn.Evaluate("arg1"); err != nil {
+		t.Fatalf("Failed to evaluate transaction: %s", err)
+	}
+
+	if len(strategy.peers) != 1 || strategy.peers[0] != "peer1.org2.com" {
+		t.Fatalf("Query strategy should be given the peers of the organization of the signing identity: %v", strategy.peers)
+	}
+}
+
+func TestPeerGatewaySubmitWithSigningIdentity(t *testing.T) {
+	server := &mocks.MockGatewayServer{Payload: []byte("abc"), BlockNumber: 7}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	contr := newPeerGatewayContract(t, addr)
+
+	user := mspmocks.NewMockSigningIdentity("user1", "Org2MSP")
+	txn, err := contr.CreateTransaction("txn1", WithSigningIdentity(user))
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+
+	if _, err := txn.Submit("arg1"); err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	if creator := proposalCreator(t, server); creator != "user1Org2MSP" {
+		t.Fatalf("Proposal should be created by the signing identity, but was created by: %s", creator)
+	}
+
+	// the gateway identity is used by subsequent transactions without the option
+	if _, err := contr.SubmitTransaction("txn1", "arg1"); err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	if creator := proposalCreator(t, server); creator == "user1Org2MSP" {
+		t.Fatal("Proposal should be created by the gateway identity")
+	}
+}
+
+func proposalCreator(t *testing.T, server *mocks.MockGatewayServer) string {
+	proposal, err := protoutil.UnmarshalProposal(server.LastEndorseRequest().ProposedTransaction.ProposalBytes)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal proposal: %s", err)
+	}
+
+	header, err := protoutil.UnmarshalHeader(proposal.Header)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal header: %s", err)
+	}
+
+	signatureHeader, err := protoutil.UnmarshalSignatureHeader(header.SignatureHeader)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal signature header: %s", err)
+	}
+
+	return string(signatureHeader.Creator)
+}
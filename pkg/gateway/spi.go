@@ -24,3 +24,20 @@ type WalletStore interface {
 	Exists(label string) bool
 	Remove(label string) error
 }
+
+// Checkpointer is the interface for implementations that record the progress of an event listener, so
+// that event processing can be resumed after the application restarts without missing or duplicating events.
+// The checkpointer holds the number of the next block to be processed and the IDs of the transactions
+// within that block whose events have already been processed.
+// Instances are passed to the event registration methods using the WithCheckpointer option.
+type Checkpointer interface {
+	// BlockNumber returns the number of the next block to be processed.
+	BlockNumber() uint64
+	// TransactionIDs returns the IDs of the transactions that have already been processed within the
+	// block returned by BlockNumber.
+	TransactionIDs() []string
+	// CheckpointBlock records that all the events in the given block have been processed.
+	CheckpointBlock(blockNumber uint64) error
+	// CheckpointTransaction records that the events of a transaction in the given block have been processed.
+	CheckpointTransaction(blockNumber uint64, txID string) error
+}
@@ -7,13 +7,18 @@ SPDX-License-Identifier: Apache-2.0
 package gateway
 
 import (
+	reqContext "context"
+	"time"
+
 	"github.com/hyperledger/fabric-protos-go/peer"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/filter"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/pkg/errors"
 )
 
@@ -31,11 +36,28 @@ type Transaction struct {
 	collections    []string
 	isInit         bool
 	eventch        chan *fab.TxStatusEvent
+	queryStrategy  QueryStrategy
+	identity       msp.SigningIdentity
+	endorsingOrgs  []string
+	evaluatingOrgs []string
 }
 
 // TransactionOption functional arguments can be supplied when creating a transaction object
 type TransactionOption = func(*Transaction) error
 
+// SubmitResult contains the outcome of a transaction that was submitted to the ledger. It is returned by
+// TransactionRequest.Submit.
+type SubmitResult struct {
+	// Payload is the return value of the transaction function
+	Payload []byte
+	// TransactionID is the ID of the submitted transaction
+	TransactionID string
+	// BlockNumber is the number of the block in which the transaction was committed
+	BlockNumber uint64
+	// TxValidationCode is the validation code of the committed transaction
+	TxValidationCode peer.TxValidationCode
+}
+
 func newTransaction(name string, contract *Contract, options ...TransactionOption) (*Transaction, error) {
 	qname := name
 	if len(contract.name) > 0 {
@@ -76,6 +98,34 @@ func WithEndorsingPeers(peers ...string) TransactionOption {
 	}
 }
 
+// WithEndorsingOrganizations is an optional argument to the CreateTransaction method which restricts
+// the endorsement of a transaction submitted to the ledger using Submit() to the peers of the given
+// organizations. This can be used when writing to a private data collection whose membership is
+// narrower than the endorsement policy of the chaincode.
+// Peers specified using WithEndorsingPeers take precedence over the endorsing organizations.
+func WithEndorsingOrganizations(mspIDs ...string) TransactionOption {
+	return func(txn *Transaction) error {
+		if len(mspIDs) == 0 {
+			return errors.New("at least one endorsing organization must be specified")
+		}
+		txn.endorsingOrgs = mspIDs
+		return nil
+	}
+}
+
+// WithEvaluatingOrganizations is an optional argument to the CreateTransaction method which restricts
+// the evaluation of a transaction using Evaluate() to the peers of the given organizations.
+// Peers specified using WithEndorsingPeers take precedence over the evaluating organizations.
+func WithEvaluatingOrganizations(mspIDs ...string) TransactionOption {
+	return func(txn *Transaction) error {
+		if len(mspIDs) == 0 {
+			return errors.New("at least one evaluating organization must be specified")
+		}
+		txn.evaluatingOrgs = mspIDs
+		return nil
+	}
+}
+
 // WithCollections is an optional argument to the CreateTransaction method which sets the collections
 func WithCollections(collections ...string) TransactionOption {
 	return func(txn *Transaction) error {
@@ -93,27 +143,100 @@ func WithInit() TransactionOption {
 	}
 }
 
+// WithSigningIdentity is an optional argument to the CreateTransaction method which signs the
+// transaction as the given identity instead of the identity of the gateway. The connections, discovery
+// and event services of the gateway are reused, so a single gateway can serve many users.
+func WithSigningIdentity(identity msp.SigningIdentity) TransactionOption {
+	return func(txn *Transaction) error {
+		if identity == nil {
+			return errors.New("signing identity must not be nil")
+		}
+		txn.identity = identity
+		return nil
+	}
+}
+
 // Evaluate a transaction function and return its results.
 // The transaction function will be evaluated on the endorsing peers but
 // the responses will not be sent to the ordering service and hence will
 // not be committed to the ledger. This can be used for querying the world state.
 func (txn *Transaction) Evaluate(args ...string) ([]byte, error) {
-	bytes := make([][]byte, len(args))
-	for i, v := range args {
-		bytes[i] = []byte(v)
+	return txn.evaluate(stringsToBytes(args))
+}
+
+// EvaluateBytes evaluates a transaction function, passing each argument to the
+// transaction function as is, and returns its results.
+// This should be used instead of Evaluate when the arguments are binary (e.g. protobuf) encoded.
+func (txn *Transaction) EvaluateBytes(args ...[]byte) ([]byte, error) {
+	return txn.evaluate(args)
+}
+
+// Submit a transaction to the ledger. The transaction function represented by this object
+// will be evaluated on the endorsing peers and then submitted to the ordering service
+// for committing to the ledger. Only the return value of the transaction function is returned;
+// use Contract.NewRequest to obtain the SubmitResult of the transaction.
+func (txn *Transaction) Submit(args ...string) ([]byte, error) {
+	result, err := txn.submit(stringsToBytes(args))
+	if err != nil {
+		return nil, err
 	}
-	txn.request.Args = bytes
+	return result.Payload, nil
+}
 
-	var options []channel.RequestOption
-	if txn.endorsingPeers != nil {
-		options = append(options, channel.WithTargetEndpoints(txn.endorsingPeers...))
+// SubmitBytes submits a transaction to the ledger, passing each argument to the
+// transaction function as is.
+// This should be used instead of Submit when the arguments are binary (e.g. protobuf) encoded.
+func (txn *Transaction) SubmitBytes(args ...[]byte) ([]byte, error) {
+	result, err := txn.submit(args)
+	if err != nil {
+		return nil, err
+	}
+	return result.Payload, nil
+}
+
+// SubmitAsync submits a transaction to the ledger and returns as soon as the transaction has been
+// accepted by the orderer, without waiting for it to be committed. The returned Commit provides the
+// result of the transaction function and the commit status of the transaction.
+func (txn *Transaction) SubmitAsync(args ...string) (*Commit, error) {
+	return txn.submitAsync(stringsToBytes(args))
+}
+
+// SubmitAsyncBytes submits a transaction to the ledger without waiting for it to be committed, passing
+// each argument to the transaction function as is.
+// This should be used instead of SubmitAsync when the arguments are binary (e.g. protobuf) encoded.
+func (txn *Transaction) SubmitAsyncBytes(args ...[]byte) (*Commit, error) {
+	return txn.submitAsync(args)
+}
+
+func (txn *Transaction) evaluate(args [][]byte) ([]byte, error) {
+	txn.request.Args = args
+
+	if txn.contract.network.peerGw != nil {
+		return txn.evaluateWithPeerGateway()
+	}
+
+	options, err := txn.targetOptions(txn.evaluatingOrgs, filter.ChaincodeQuery)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to evaluate")
 	}
 	options = append(options, channel.WithTimeout(fab.Query, txn.contract.network.gateway.options.Timeout))
+	if txn.identity != nil {
+		options = append(options, channel.WithSigningIdentity(txn.identity))
+	}
 
 	if txn.collections != nil {
 		txn.request.InvocationChain = append(txn.request.InvocationChain, &fab.ChaincodeCall{ID: txn.contract.chaincodeID, Collections: txn.collections})
 	}
 
+	// explicitly specified endorsing peers take precedence over the query strategy
+	strategy := txn.queryStrategy
+	if strategy == nil {
+		strategy = txn.contract.network.gateway.options.QueryStrategy
+	}
+	if strategy != nil && txn.endorsingPeers == nil {
+		return txn.evaluateWithStrategy(strategy, options)
+	}
+
 	response, err := txn.contract.client.Query(
 		*txn.request,
 		options...,
@@ -125,38 +248,225 @@ func (txn *Transaction) Evaluate(args ...string) ([]byte, error) {
 	return response.Payload, nil
 }
 
-// Submit a transaction to the ledger. The transaction function represented by this object
-// will be evaluated on the endorsing peers and then submitted to the ordering service
-// for committing to the ledger.
-func (txn *Transaction) Submit(args ...string) ([]byte, error) {
-	bytes := make([][]byte, len(args))
-	for i, v := range args {
-		bytes[i] = []byte(v)
+func (txn *Transaction) evaluateWithStrategy(strategy QueryStrategy, options []channel.RequestOption) ([]byte, error) {
+	ctx, err := txn.contract.network.channelProvider()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create new channel context")
+	}
+
+	discovery, err := ctx.ChannelService().Discovery()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get discovery service")
+	}
+
+	channelPeers, err := discovery.GetPeers()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get channel peers")
+	}
+
+	mspIDs := txn.evaluatingOrgs
+	if mspIDs == nil {
+		mspID := ctx.Identifier().MSPID
+		if txn.identity != nil {
+			mspID = txn.identity.Identifier().MSPID
+		}
+		mspIDs = []string{mspID}
+	}
+
+	var orgPeers []fab.Peer
+	for _, p := range channelPeers {
+		if containsString(mspIDs, p.MSPID()) {
+			orgPeers = append(orgPeers, p)
+		}
 	}
-	txn.request.Args = bytes
+
+	result, err := strategy.Evaluate(orgPeers, func(p fab.Peer) ([]byte, error) {
+		targetOptions := append([]channel.RequestOption{channel.WithTargets(p)}, options...)
+		response, err := txn.contract.client.Query(*txn.request, targetOptions...)
+		if err != nil {
+			return nil, err
+		}
+		return response.Payload, nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to evaluate")
+	}
+
+	return result, nil
+}
+
+func (txn *Transaction) submit(args [][]byte) (*SubmitResult, error) {
+	txn.request.Args = args
 	txn.request.IsInit = txn.isInit
 
-	var options []channel.RequestOption
-	if txn.endorsingPeers != nil {
-		options = append(options, channel.WithTargetEndpoints(txn.endorsingPeers...))
+	if txn.contract.network.peerGw != nil {
+		return txn.submitWithPeerGateway()
+	}
+
+	commitHandler := &commitTxHandler{eventch: txn.eventch}
+	response, err := txn.invokeSubmit(commitHandler)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SubmitResult{
+		Payload:          response.Payload,
+		TransactionID:    string(response.TransactionID),
+		TxValidationCode: response.TxValidationCode,
+	}
+	if commitHandler.txStatus != nil {
+		result.BlockNumber = commitHandler.txStatus.BlockNumber
+	}
+
+	return result, nil
+}
+
+func (txn *Transaction) submitAsync(args [][]byte) (*Commit, error) {
+	txn.request.Args = args
+	txn.request.IsInit = txn.isInit
+
+	if txn.contract.network.peerGw != nil {
+		return txn.submitAsyncWithPeerGateway()
+	}
+
+	commitHandler := &commitTxHandler{
+		eventch: txn.eventch,
+		async:   true,
+		timeout: txn.contract.network.gateway.options.Timeout,
+	}
+	if _, err := txn.invokeSubmit(commitHandler); err != nil {
+		return nil, err
+	}
+
+	return commitHandler.commit, nil
+}
+
+func (txn *Transaction) invokeSubmit(commitHandler *commitTxHandler) (channel.Response, error) {
+	options, err := txn.targetOptions(txn.endorsingOrgs, filter.EndorsingPeer)
+	if err != nil {
+		return channel.Response{}, errors.Wrap(err, "Failed to submit")
 	}
 	options = append(options, channel.WithTimeout(fab.Execute, txn.contract.network.gateway.options.Timeout))
 	options = append(options, channel.WithRetry(retry.DefaultChannelOpts))
+	if txn.identity != nil {
+		options = append(options, channel.WithSigningIdentity(txn.identity))
+	}
 
 	if txn.collections != nil {
 		txn.request.InvocationChain = append(txn.request.InvocationChain, &fab.ChaincodeCall{ID: txn.contract.chaincodeID, Collections: txn.collections})
 	}
 
+	handler, err := txn.newCommitHandler(commitHandler)
+	if err != nil {
+		return channel.Response{}, errors.Wrap(err, "Failed to submit")
+	}
+
 	response, err := txn.contract.client.InvokeHandler(
-		newSubmitHandler(txn.eventch),
+		newSubmitHandler(handler),
 		*txn.request,
 		options...,
 	)
+	if err != nil {
+		return channel.Response{}, errors.Wrap(err, "Failed to submit")
+	}
+
+	return response, nil
+}
+
+// targetOptions returns the request options that select the peers for the transaction, which are either the
+// endorsing peers of the transaction or the peers of the given organizations
+func (txn *Transaction) targetOptions(mspIDs []string, endpointType filter.EndpointType) ([]channel.RequestOption, error) {
+	if txn.endorsingPeers != nil {
+		return []channel.RequestOption{channel.WithTargetEndpoints(txn.endorsingPeers...)}, nil
+	}
+
+	if mspIDs == nil {
+		return nil, nil
+	}
+
+	ctx, err := txn.contract.network.channelProvider()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create new channel context")
+	}
+
+	// the endpoint filter is replaced by the target filter so it is applied here as well
+	targetFilter := filter.NewMSPFilter(filter.NewEndpointFilter(ctx, endpointType), mspIDs...)
+	return []channel.RequestOption{channel.WithTargetFilter(targetFilter)}, nil
+}
+
+// peerGatewayOrgs returns the organizations that the gateway peer is to use for the transaction, which are either
+// the organizations of the endorsing peers of the transaction or the given organizations
+func (txn *Transaction) peerGatewayOrgs(peerGw *peerGateway, mspIDs []string) ([]string, error) {
+	if txn.endorsingPeers != nil {
+		return peerGw.endorsingOrgs(txn.endorsingPeers)
+	}
+	return mspIDs, nil
+}
+
+func (txn *Transaction) evaluateWithPeerGateway() ([]byte, error) {
+	peerGw := txn.peerGateway()
+
+	orgs, err := txn.peerGatewayOrgs(peerGw, txn.evaluatingOrgs)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to evaluate")
+	}
+
+	result, err := peerGw.evaluate(txn.request, orgs, txn.contract.network.gateway.options.Timeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to evaluate")
+	}
+
+	return result, nil
+}
+
+func (txn *Transaction) submitAsyncWithPeerGateway() (*Commit, error) {
+	peerGw := txn.peerGateway()
+
+	orgs, err := txn.peerGatewayOrgs(peerGw, txn.endorsingOrgs)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to submit")
 	}
 
-	return response.Payload, nil
+	timeout := txn.contract.network.gateway.options.Timeout
+	reqCtx, cancel := reqContext.WithTimeout(reqContext.Background(), timeout)
+	defer cancel()
+
+	payload, txID, err := peerGw.submitAsync(reqCtx, txn.request, orgs)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to submit")
+	}
+
+	commit := newCommit(txID, payload)
+	commit.complete(func(ctx reqContext.Context) (*fab.TxStatusEvent, error) {
+		return peerGw.waitForCommit(ctx, txID)
+	}, timeout, (&commitTxHandler{eventch: txn.eventch}).notify)
+
+	return commit, nil
+}
+
+func (txn *Transaction) submitWithPeerGateway() (*SubmitResult, error) {
+	peerGw := txn.peerGateway()
+
+	orgs, err := txn.peerGatewayOrgs(peerGw, txn.endorsingOrgs)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to submit")
+	}
+
+	result, err := peerGw.submit(txn.request, orgs, txn.contract.network.gateway.options.Timeout, txn.eventch)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to submit")
+	}
+
+	return result, nil
+}
+
+// peerGateway returns the peer gateway of the network, signing as the identity of the transaction if one is set
+func (txn *Transaction) peerGateway() *peerGateway {
+	peerGw := txn.contract.network.peerGw
+	if txn.identity != nil {
+		return peerGw.withSigner(txn.identity)
+	}
+	return peerGw
 }
 
 // RegisterCommitEvent registers for a commit event for this transaction.
@@ -167,16 +477,45 @@ func (txn *Transaction) RegisterCommitEvent() <-chan *fab.TxStatusEvent {
 	return txn.eventch
 }
 
-func newSubmitHandler(eventch chan *fab.TxStatusEvent) invoke.Handler {
+// newCommitHandler returns the handler that waits for the transaction to be committed, using the
+// commit strategy of the gateway if one is set
+func (txn *Transaction) newCommitHandler(commitHandler *commitTxHandler) (invoke.Handler, error) {
+	network := txn.contract.network
+
+	strategy := network.gateway.options.CommitStrategy
+	if strategy == nil {
+		return commitHandler, nil
+	}
+
+	ctx, err := network.channelProvider()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create new channel context")
+	}
+
+	return &strategyCommitHandler{
+		commitHandler: commitHandler,
+		strategy:      strategy,
+		ctx:           ctx,
+		eventService:  network.peerEventService.get,
+	}, nil
+}
+
+func newSubmitHandler(commitHandler invoke.Handler) invoke.Handler {
 	return invoke.NewSelectAndEndorseHandler(
 		invoke.NewEndorsementValidationHandler(
-			invoke.NewSignatureValidationHandler(&commitTxHandler{eventch}),
+			invoke.NewSignatureValidationHandler(commitHandler),
 		),
 	)
 }
 
 type commitTxHandler struct {
-	eventch chan *fab.TxStatusEvent
+	eventch  chan *fab.TxStatusEvent
+	txStatus *fab.TxStatusEvent
+	// async is set for asynchronous submit, in which case the commit is awaited in the background
+	// for up to timeout, and its outcome is recorded in commit
+	async   bool
+	timeout time.Duration
+	commit  *Commit
 }
 
 //Handle handles commit tx
@@ -189,32 +528,69 @@ func (c *commitTxHandler) Handle(requestContext *invoke.RequestContext, clientCo
 		requestContext.Error = errors.Wrap(err, "error registering for TxStatus event")
 		return
 	}
-	defer clientContext.EventService.Unregister(reg)
 	_, err = createAndSendTransaction(clientContext.Transactor, requestContext.Response.Proposal, requestContext.Response.Responses)
 	if err != nil {
+		clientContext.EventService.Unregister(reg)
 		requestContext.Error = errors.Wrap(err, "CreateAndSendTransaction failed")
 		return
 	}
 
-	select {
-	case txStatus := <-statusNotifier:
+	c.complete(requestContext, func(ctx reqContext.Context) (*fab.TxStatusEvent, error) {
+		defer clientContext.EventService.Unregister(reg)
+
+		select {
+		case txStatus := <-statusNotifier:
+			if txStatus.TxValidationCode != peer.TxValidationCode_VALID {
+				return txStatus, status.New(status.EventServerStatus, int32(txStatus.TxValidationCode),
+					"received invalid transaction", nil)
+			}
+			return txStatus, nil
+		case <-ctx.Done():
+			return nil, status.New(status.ClientStatus, status.Timeout.ToInt32(),
+				"Execute didn't receive block event", nil)
+		}
+	})
+}
+
+// complete waits for the transaction, which has been sent to the orderer, to be committed.
+// For asynchronous submit, the commit is instead awaited in the background.
+func (c *commitTxHandler) complete(requestContext *invoke.RequestContext, wait commitWaitFunc) {
+	if c.async {
+		c.commit = newCommit(string(requestContext.Response.TransactionID), requestContext.Response.Payload)
+		c.commit.complete(wait, c.timeout, c.notify)
+		return
+	}
+
+	txStatus, err := wait(requestContext.Ctx)
+	if txStatus != nil {
 		requestContext.Response.TxValidationCode = txStatus.TxValidationCode
+		c.txStatus = txStatus
+	}
+	if err != nil {
+		requestContext.Error = err
+		return
+	}
 
-		if txStatus.TxValidationCode != peer.TxValidationCode_VALID {
-			requestContext.Error = status.New(status.EventServerStatus, int32(txStatus.TxValidationCode),
-				"received invalid transaction", nil)
-			return
-		}
+	c.notify(txStatus)
+}
 
-		if c.eventch != nil {
-			c.eventch <- txStatus
-			close(c.eventch)
-		}
-	case <-requestContext.Ctx.Done():
-		requestContext.Error = status.New(status.ClientStatus, status.Timeout.ToInt32(),
-			"Execute didn't receive block event", nil)
+// notify queues the commit event, if any, for the registered listener and closes the event channel
+func (c *commitTxHandler) notify(txStatus *fab.TxStatusEvent) {
+	if c.eventch == nil {
 		return
 	}
+	if txStatus != nil {
+		c.eventch <- txStatus
+	}
+	close(c.eventch)
+}
+
+func stringsToBytes(args []string) [][]byte {
+	bytes := make([][]byte, len(args))
+	for i, v := range args {
+		bytes[i] = []byte(v)
+	}
+	return bytes
 }
 
 func createAndSendTransaction(sender fab.Sender, proposal *fab.TransactionProposal, resps []*fab.TransactionProposalResponse) (*fab.TransactionResponse, error) {
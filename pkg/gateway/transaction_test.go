@@ -17,6 +17,7 @@ import (
 	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
 	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	cpc "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
@@ -338,3 +339,115 @@ func (t *mockTransactor) CreateTransactionHeader(opts ...fab.TxnHeaderOpt) (fab.
 func (t *mockTransactor) SendTransactionProposal(proposal *fab.TransactionProposal, targets []fab.ProposalProcessor) ([]*fab.TransactionProposalResponse, error) {
 	return nil, nil
 }
+
+func TestOrganizationOptions(t *testing.T) {
+	txn := &Transaction{}
+
+	if err := WithEndorsingOrganizations()(txn); err == nil {
+		t.Fatal("Expected error for no endorsing organizations")
+	}
+
+	if err := WithEvaluatingOrganizations()(txn); err == nil {
+		t.Fatal("Expected error for no evaluating organizations")
+	}
+}
+
+func TestSubmitWithEndorsingOrganizations(t *testing.T) {
+	peer1 := fcmocks.NewMockPeer("peer1", "peer1.org1.com")
+	peer2 := fcmocks.NewMockPeer("peer2", "peer1.org2.com")
+	peer2.MockMSP = "Org2MSP"
+
+	selection := &recordingSelectionService{MockSelectionService: txnmocks.NewMockSelectionService(nil, peer1, peer2)}
+	channelProvider := func() (cpc.Channel, error) {
+		ch, err := fcmocks.NewMockChannel("mychannel")
+		if err != nil {
+			return nil, err
+		}
+		ch.ChannelService().(*fcmocks.MockChannelService).SetSelection(selection)
+		return ch, nil
+	}
+
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: testTimeOut,
+		},
+	}
+
+	nw, err := newNetwork(gw, channelProvider)
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	txn, err := nw.GetContract("contract1").CreateTransaction("txn1", WithEndorsingOrganizations("Org2MSP"))
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+
+	if _, err := txn.Submit("arg1"); err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	if len(selection.selected) != 1 || selection.selected[0] != "peer1.org2.com" {
+		t.Fatalf("Only peers of the endorsing organizations should be selected: %v", selection.selected)
+	}
+
+	// endorsing peers take precedence over endorsing organizations
+	selection.selected = nil
+	txn, err = nw.GetContract("contract1").CreateTransaction("txn1", WithEndorsingOrganizations("Org2MSP"), WithEndorsingPeers("peer1.org1.com"))
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+
+	txn.Submit("arg1")
+	if selection.selected != nil {
+		t.Fatalf("Selection service should not be used when endorsing peers are specified: %v", selection.selected)
+	}
+}
+
+func TestEvaluateWithEvaluatingOrganizations(t *testing.T) {
+	peer1 := fcmocks.NewMockPeer("peer1", "peer1.org1.com")
+	peer2 := fcmocks.NewMockPeer("peer2", "peer1.org2.com")
+	peer2.MockMSP = "Org2MSP"
+	peer3 := fcmocks.NewMockPeer("peer3", "peer1.org3.com")
+	peer3.MockMSP = "Org3MSP"
+
+	strategy := &recordingQueryStrategy{}
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout:       testTimeOut,
+			QueryStrategy: strategy,
+		},
+	}
+
+	nw, err := newNetwork(gw, mockDiscoveryChannelProvider("mychannel", peer1, peer2, peer3))
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	result, err := nw.GetContract("contract1").NewRequest("txn1").WithEvaluatingOrganizations("Org2MSP", "Org3MSP").Evaluate()
+	if err != nil {
+		t.Fatalf("Failed to evaluate transaction: %s", err)
+	}
+
+	if string(result) != "abc" {
+		t.Fatalf("Incorrect transaction result: %s", result)
+	}
+
+	if len(strategy.peers) != 2 || strategy.peers[0] != "peer1.org2.com" || strategy.peers[1] != "peer1.org3.com" {
+		t.Fatalf("Query strategy should be given the peers of the evaluating organizations: %v", strategy.peers)
+	}
+}
+
+// recordingSelectionService records the URLs of the selected endorsers
+type recordingSelectionService struct {
+	*txnmocks.MockSelectionService
+	selected []string
+}
+
+func (s *recordingSelectionService) GetEndorsersForChaincode(chaincodes []*fab.ChaincodeCall, opts ...options.Opt) ([]fab.Peer, error) {
+	peers, err := s.MockSelectionService.GetEndorsersForChaincode(chaincodes, opts...)
+	for _, p := range peers {
+		s.selected = append(s.selected, p.URL())
+	}
+	return peers, err
+}
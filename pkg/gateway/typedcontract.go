@@ -0,0 +1,269 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	gp "github.com/hyperledger/fabric-protos-go/gateway"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/pkg/errors"
+)
+
+// chaincodeResponsePattern matches the chaincode errors reported by a gateway peer
+var chaincodeResponsePattern = regexp.MustCompile(`chaincode response (\d+), (.*)`)
+
+// A TypedContract invokes the transaction functions of a smart contract using Go values as arguments and
+// results, which are encoded by a Codec.
+// Instances are created using NewTypedContract()
+type TypedContract struct {
+	contract    *Contract
+	codec       Codec
+	errorMapper ErrorMapper
+	options     []TransactionOption
+}
+
+// TypedContractOption functional arguments can be supplied when creating a TypedContract
+type TypedContractOption = func(*TypedContract) error
+
+// ErrorMapper converts a chaincode error into an application specific error, which callers can then
+// match using errors.As. If nil is returned, the ChaincodeError itself is returned to the caller.
+type ErrorMapper = func(err *ChaincodeError) error
+
+// ChaincodeError is returned by a TypedContract when a transaction function returns an error
+type ChaincodeError struct {
+	// Status is the status code returned by the chaincode, e.g. 500
+	Status int32
+	// Message is the error message returned by the chaincode
+	Message string
+	cause   error
+}
+
+// Error returns the error message
+func (e *ChaincodeError) Error() string {
+	return fmt.Sprintf("chaincode returned status %d: %s", e.Status, e.Message)
+}
+
+// Unwrap returns the error from which the chaincode error was extracted
+func (e *ChaincodeError) Unwrap() error {
+	return e.cause
+}
+
+// NewTypedContract creates a typed layer over a contract.
+//  Parameters:
+//  contract is the contract whose transaction functions are invoked
+//  opts are the options for the typed contract, e.g. WithCodec to select the encoding of values.
+//
+//  Returns:
+//  A TypedContract object. Values are encoded as JSON unless another codec is selected.
+func NewTypedContract(contract *Contract, opts ...TypedContractOption) (*TypedContract, error) {
+	tc := &TypedContract{
+		contract: contract,
+		codec:    NewJSONCodec(),
+	}
+
+	for _, opt := range opts {
+		if err := opt(tc); err != nil {
+			return nil, err
+		}
+	}
+
+	return tc, nil
+}
+
+// WithCodec is an optional argument to the NewTypedContract method which selects the codec used to
+// encode the arguments and decode the results of transaction functions.
+func WithCodec(codec Codec) TypedContractOption {
+	return func(tc *TypedContract) error {
+		if codec == nil {
+			return errors.New("codec must not be nil")
+		}
+		tc.codec = codec
+		return nil
+	}
+}
+
+// WithErrorMapper is an optional argument to the NewTypedContract method which converts the errors
+// returned by transaction functions into application specific errors.
+func WithErrorMapper(mapper ErrorMapper) TypedContractOption {
+	return func(tc *TypedContract) error {
+		tc.errorMapper = mapper
+		return nil
+	}
+}
+
+// WithTypedTransactionOptions is an optional argument to the NewTypedContract method which sets the
+// transaction options, e.g. WithTransient, used for every invocation.
+func WithTypedTransactionOptions(opts ...TransactionOption) TypedContractOption {
+	return func(tc *TypedContract) error {
+		tc.options = append(tc.options, opts...)
+		return nil
+	}
+}
+
+// Contract returns the contract underlying this typed contract
+func (tc *TypedContract) Contract() *Contract {
+	return tc.contract
+}
+
+// Evaluate evaluates a transaction function and decodes its result. The transaction will not be
+// committed to the ledger.
+//  Parameters:
+//  name is the name of the transaction function to be invoked in the smart contract.
+//  result is a pointer to the value into which the result is decoded, or nil if the result is not needed.
+//  args are the arguments to be encoded and sent to the transaction function.
+//
+//  Returns:
+//  A ChaincodeError, or the error returned by the error mapper, if the transaction function fails.
+func (tc *TypedContract) Evaluate(name string, result interface{}, args ...interface{}) error {
+	request, err := tc.newRequest(name, args)
+	if err != nil {
+		return err
+	}
+
+	payload, err := request.Evaluate()
+	if err != nil {
+		return tc.mapError(err)
+	}
+
+	return tc.decode(payload, result)
+}
+
+// Submit submits a transaction to the ledger and decodes the result of the transaction function.
+//  Parameters:
+//  name is the name of the transaction function to be invoked in the smart contract.
+//  result is a pointer to the value into which the result is decoded, or nil if the result is not needed.
+//  args are the arguments to be encoded and sent to the transaction function.
+//
+//  Returns:
+//  A ChaincodeError, or the error returned by the error mapper, if the transaction function fails.
+func (tc *TypedContract) Submit(name string, result interface{}, args ...interface{}) error {
+	request, err := tc.newRequest(name, args)
+	if err != nil {
+		return err
+	}
+
+	submitResult, err := request.Submit()
+	if err != nil {
+		return tc.mapError(err)
+	}
+
+	return tc.decode(submitResult.Payload, result)
+}
+
+func (tc *TypedContract) newRequest(name string, args []interface{}) (*TransactionRequest, error) {
+	encoded := make([][]byte, len(args))
+	for i, arg := range args {
+		data, err := tc.codec.Marshal(arg)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to encode argument %d of transaction [%s]", i, name)
+		}
+		encoded[i] = data
+	}
+
+	return tc.contract.NewRequest(name).WithBytesArgs(encoded...).WithOptions(tc.options...), nil
+}
+
+func (tc *TypedContract) decode(payload []byte, result interface{}) error {
+	if result == nil {
+		return nil
+	}
+
+	if err := tc.codec.Unmarshal(payload, result); err != nil {
+		return errors.WithMessage(err, "failed to decode transaction result")
+	}
+	return nil
+}
+
+// mapError returns a typed error if the given error was returned by the chaincode, otherwise the error itself
+func (tc *TypedContract) mapError(err error) error {
+	ccErr := chaincodeErrorFrom(err)
+	if ccErr == nil {
+		return err
+	}
+
+	if tc.errorMapper != nil {
+		if mapped := tc.errorMapper(ccErr); mapped != nil {
+			return mapped
+		}
+	}
+
+	return ccErr
+}
+
+// chaincodeErrorFrom extracts the chaincode status and message from an error returned by the SDK or a gateway peer.
+// nil is returned if the error was not returned by the chaincode.
+func chaincodeErrorFrom(err error) *ChaincodeError {
+	cause := errors.Cause(err)
+
+	if errs, ok := cause.(multi.Errors); ok {
+		for _, e := range errs {
+			if ccErr := chaincodeErrorFrom(e); ccErr != nil {
+				ccErr.cause = err
+				return ccErr
+			}
+		}
+		return nil
+	}
+
+	s, ok := cause.(*status.Status)
+	if !ok {
+		return nil
+	}
+
+	switch s.Group {
+	case status.ChaincodeStatus:
+		return &ChaincodeError{Status: s.Code, Message: s.Message, cause: err}
+	case status.GRPCTransportStatus:
+		// a gateway peer reports chaincode errors in the error details of each endorser
+		for _, detail := range s.Details {
+			if ccErr := chaincodeErrorFromDetail(detail); ccErr != nil {
+				ccErr.cause = err
+				return ccErr
+			}
+		}
+		if ccErr := chaincodeErrorFromMessage(s.Message); ccErr != nil {
+			ccErr.cause = err
+			return ccErr
+		}
+	}
+
+	return nil
+}
+
+func chaincodeErrorFromDetail(detail interface{}) *ChaincodeError {
+	anyDetail, ok := detail.(*any.Any)
+	if !ok {
+		return nil
+	}
+
+	errorDetail := &gp.ErrorDetail{}
+	if err := ptypes.UnmarshalAny(anyDetail, errorDetail); err != nil {
+		return nil
+	}
+
+	return chaincodeErrorFromMessage(errorDetail.Message)
+}
+
+func chaincodeErrorFromMessage(message string) *ChaincodeError {
+	match := chaincodeResponsePattern.FindStringSubmatch(message)
+	if match == nil {
+		return nil
+	}
+
+	code, err := strconv.ParseInt(match[1], 10, 32)
+	if err != nil {
+		return nil
+	}
+
+	return &ChaincodeError{Status: int32(code), Message: match[2]}
+}
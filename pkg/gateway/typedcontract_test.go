@@ -0,0 +1,197 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	gp "github.com/hyperledger/fabric-protos-go/gateway"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	pkgerrors "github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+type assetNotFoundError struct {
+	message string
+}
+
+func (e *assetNotFoundError) Error() string {
+	return e.message
+}
+
+func TestTypedContractSubmit(t *testing.T) {
+	c := mockChannelProvider("mychannel")
+
+	gw := &Gateway{
+		options: &gatewayOptions{
+			Timeout: defaultTimeout,
+		},
+	}
+
+	nw, err := newNetwork(gw, c)
+	if err != nil {
+		t.Fatalf("Failed to create network: %s", err)
+	}
+
+	tc, err := NewTypedContract(nw.GetContract("contract1"))
+	if err != nil {
+		t.Fatalf("Failed to create typed contract: %s", err)
+	}
+
+	var result string
+	if err := tc.Submit("txn1", &result, &testAsset{ID: "asset1"}, 5); err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+
+	if result != "abc" {
+		t.Fatalf("Incorrect transaction result: %s", result)
+	}
+
+	if err := tc.Evaluate("txn1", nil, "asset1"); err != nil {
+		t.Fatalf("Failed to evaluate transaction: %s", err)
+	}
+}
+
+func TestTypedContractEvaluate(t *testing.T) {
+	server := &mocks.MockGatewayServer{Payload: []byte(`{"id":"asset1","owner":"Tom","value":100}`)}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	tc, err := NewTypedContract(newPeerGatewayContract(t, addr))
+	if err != nil {
+		t.Fatalf("Failed to create typed contract: %s", err)
+	}
+
+	asset := &testAsset{}
+	if err := tc.Evaluate("ReadAsset", asset, "asset1"); err != nil {
+		t.Fatalf("Failed to evaluate transaction: %s", err)
+	}
+
+	if asset.ID != "asset1" || asset.Owner != "Tom" || asset.Value != 100 {
+		t.Fatalf("Unexpected asset: %#v", asset)
+	}
+
+	var value int
+	if err := tc.Evaluate("ReadAsset", &value, "asset1"); err == nil {
+		t.Fatal("Expected error decoding result")
+	}
+}
+
+func TestTypedContractEncodeArgs(t *testing.T) {
+	tc, err := NewTypedContract(&Contract{})
+	if err != nil {
+		t.Fatalf("Failed to create typed contract: %s", err)
+	}
+
+	request, err := tc.newRequest("CreateAsset", []interface{}{"asset1", 100, &testAsset{Owner: "Tom"}})
+	if err != nil {
+		t.Fatalf("Failed to encode arguments: %s", err)
+	}
+
+	expected := []string{"asset1", "100", `{"id":"","owner":"Tom","value":0}`}
+	for i, arg := range request.args {
+		if string(arg) != expected[i] {
+			t.Fatalf("Unexpected argument %d: %s", i, arg)
+		}
+	}
+
+	_, err = tc.newRequest("CreateAsset", []interface{}{make(chan int)})
+	if err == nil {
+		t.Fatal("Expected error encoding argument")
+	}
+}
+
+func TestTypedContractOptions(t *testing.T) {
+	if _, err := NewTypedContract(&Contract{}, WithCodec(nil)); err == nil {
+		t.Fatal("Expected error for nil codec")
+	}
+
+	tc, err := NewTypedContract(&Contract{}, WithCodec(NewProtobufCodec()), WithTypedTransactionOptions(WithInit()))
+	if err != nil {
+		t.Fatalf("Failed to create typed contract: %s", err)
+	}
+
+	if _, ok := tc.codec.(*protobufCodec); !ok {
+		t.Fatalf("Unexpected codec: %T", tc.codec)
+	}
+
+	if len(tc.options) != 1 {
+		t.Fatalf("Unexpected transaction options: %v", tc.options)
+	}
+}
+
+func TestTypedContractChaincodeError(t *testing.T) {
+	rpcStatus, err := grpcstatus.New(codes.Aborted, "failed to endorse transaction").
+		WithDetails(&gp.ErrorDetail{Address: "peer1:7051", MspId: "Org1MSP", Message: "chaincode response 404, asset1 does not exist"})
+	if err != nil {
+		t.Fatalf("Failed to create status: %s", err)
+	}
+
+	server := &mocks.MockGatewayServer{EndorseError: rpcStatus.Err()}
+	addr := server.Start(gatewayTestAddress)
+	defer server.Stop()
+
+	tc, err := NewTypedContract(newPeerGatewayContract(t, addr))
+	if err != nil {
+		t.Fatalf("Failed to create typed contract: %s", err)
+	}
+
+	err = tc.Submit("DeleteAsset", nil, "asset1")
+
+	var ccErr *ChaincodeError
+	if !errors.As(err, &ccErr) {
+		t.Fatalf("Expected chaincode error, Received error: %v", err)
+	}
+
+	if ccErr.Status != 404 || ccErr.Message != "asset1 does not exist" {
+		t.Fatalf("Unexpected chaincode error: %#v", ccErr)
+	}
+
+	tc, err = NewTypedContract(newPeerGatewayContract(t, addr), WithErrorMapper(func(err *ChaincodeError) error {
+		if err.Status == 404 {
+			return &assetNotFoundError{message: err.Message}
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create typed contract: %s", err)
+	}
+
+	err = tc.Submit("DeleteAsset", nil, "asset1")
+
+	var notFound *assetNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected mapped error, Received error: %v", err)
+	}
+}
+
+func TestChaincodeErrorFrom(t *testing.T) {
+	ccStatus := status.New(status.ChaincodeStatus, 500, "asset1 already exists", nil)
+	err := pkgerrors.WithMessage(multi.Errors{fmt.Errorf("peer failed"), ccStatus}, "endorsement failed")
+
+	ccErr := chaincodeErrorFrom(err)
+	if ccErr == nil || ccErr.Status != 500 || ccErr.Message != "asset1 already exists" {
+		t.Fatalf("Unexpected chaincode error: %#v", ccErr)
+	}
+
+	if ccErr.Unwrap() != err {
+		t.Fatalf("Unexpected cause: %v", ccErr.Unwrap())
+	}
+
+	if chaincodeErrorFrom(errors.New("connection refused")) != nil {
+		t.Fatal("Expected no chaincode error")
+	}
+
+	if chaincodeErrorFrom(status.New(status.EndorserClientStatus, 1, "timeout", nil)) != nil {
+		t.Fatal("Expected no chaincode error")
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
 	"github.com/pkg/errors"
 )
 
@@ -84,6 +85,8 @@ func (w *Wallet) Get(label string) (Identity, error) {
 	switch idType {
 	case x509Type:
 		id = &X509Identity{}
+	case hsmX509Type:
+		id = &HSMX509Identity{}
 	default:
 		return nil, errors.New("Invalid identity format: unsupported identity type: " + idType)
 	}
@@ -133,6 +136,23 @@ type walletIdentity struct {
 	mspID                 string
 	enrollmentCertificate []byte
 	privateKey            core.Key
+	// hsm indicates that the private key is held in an HSM and is resolved using the SDK cryptosuite
+	hsm bool
+}
+
+// resolvePrivateKey locates the private key of an HSM identity by the SKI of its certificate
+func (u *walletIdentity) resolvePrivateKey(cs core.CryptoSuite) error {
+	if !u.hsm || u.privateKey != nil {
+		return nil
+	}
+
+	privateKey, err := cryptoutil.GetPrivateKeyFromCert(u.enrollmentCertificate, cs)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to find private key for HSM identity [%s]", u.id)
+	}
+
+	u.privateKey = privateKey
+	return nil
 }
 
 // Identifier returns walletIdentity identifier